@@ -33,7 +33,7 @@ class StartMiner():
         pwFile.writelines(passwd)
         pwFile.close()
         chainExe = StartMiner.myConfig.getChainExe()
-        Cmd = chainExe + ' --mine --rpc --rpcaddr localhost --rpcport 8545 --rpcapi "web3,eth" --rpccorsdomain "http://localhost:8000" '
+        Cmd = chainExe + ' --mine --rpc --rpcaddr localhost --rpcport 8545 --rpcapi "web3,eth,veriteem" --rpccorsdomain "http://localhost:8000" '
         Cmd = Cmd + '--datadir ' + StartMiner.myConfig.GETHDATA  + ' '
     
         Cmd = Cmd + '--port 60303 --networkid ' + StartMiner.myConfig.NETWORK + ' --targetgaslimit 15000000 --gasprice 0 --maxpeers 25 --nat none '
@@ -50,7 +50,7 @@ class StartVeriteem():
         pwFile.writelines(passwd)
         pwFile.close()
         chainExe = StartVeriteem.myConfig.getChainExe()
-        Cmd = chainExe + ' --rpc --rpcaddr localhost --rpcport 8545 --rpcapi "web3,eth" --rpccorsdomain "http://localhost:8000" '
+        Cmd = chainExe + ' --rpc --rpcaddr localhost --rpcport 8545 --rpcapi "web3,eth,veriteem" --rpccorsdomain "http://localhost:8000" '
         Cmd = Cmd + '--datadir ' + StartVeriteem.myConfig.GETHDATA  + ' '
     
         Cmd = Cmd + '--port 60303 --networkid ' + StartVeriteem.myConfig.NETWORK + ' --targetgaslimit 15000000 --gasprice 0 --maxpeers 25 --nat none '
@@ -0,0 +1,756 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// accessCheckGas is the gas allowance handed to every query of the access
+// contract. It is not deducted from the caller, which pays the fixed cost of
+// the permission check instead.
+const accessCheckGas uint64 = 1000000
+
+var (
+	errAccessShortReturn     = errors.New("access contract returned too little data")
+	errAccessMalformedReturn = errors.New("access contract returned malformed data")
+)
+
+// AccessReason explains the outcome of a permission check.
+type AccessReason uint8
+
+const (
+	AccessGranted         AccessReason = iota // Both reads and writes are allowed
+	AccessDenied                              // All access denied, the policy gave no detail
+	AccessWriteDenied                         // Writes denied, the policy gave no detail
+	AccessReadDenied                          // Reads denied, the output is withheld
+	AccessUnownedContract                     // The contract belongs to no guardianship
+	AccessGroupMismatch                       // The function is restricted to other access groups
+	AccessNoGuardianship                      // The caller isn't a contributor of the contract's (sub) guardianship
+	AccessRateLimited                         // The contributor wrote less than Limit blocks ago
+	AccessWriteDisabled                       // The contract's WriteValid flag is cleared
+	AccessContractPaused                      // The contract is paused, writes are denied
+	AccessContractRetired                     // The contract is retired, all access is denied
+	AccessCreateDenied                        // The caller may not create contracts
+)
+
+// accessReasonNames are the descriptions of the access reasons.
+var accessReasonNames = map[AccessReason]string{
+	AccessGranted:         "granted",
+	AccessDenied:          "denied",
+	AccessWriteDenied:     "write denied",
+	AccessReadDenied:      "read denied",
+	AccessUnownedContract: "unowned contract",
+	AccessGroupMismatch:   "access group mismatch",
+	AccessNoGuardianship:  "no guardianship",
+	AccessRateLimited:     "rate limited",
+	AccessWriteDisabled:   "write disabled",
+	AccessContractPaused:  "contract paused",
+	AccessContractRetired: "contract retired",
+	AccessCreateDenied:    "create denied",
+}
+
+// String implements fmt.Stringer.
+func (r AccessReason) String() string {
+	if name, ok := accessReasonNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Err returns the error reported to callers denied access for this reason.
+func (r AccessReason) Err() error {
+	switch r {
+	case AccessGranted:
+		return nil
+	case AccessDenied, AccessWriteDenied:
+		// Every call denied all access had its writes denied, a check of
+		// reads alone never ends up without detail
+		return ErrWriteDenied
+	case AccessUnownedContract:
+		return ErrUnownedContract
+	case AccessGroupMismatch:
+		return ErrAccessGroupDenied
+	case AccessNoGuardianship:
+		return ErrNoGuardianship
+	case AccessRateLimited:
+		return ErrRateLimited
+	case AccessWriteDisabled:
+		return ErrContractDisabled
+	case AccessContractPaused:
+		return ErrContractPaused
+	case AccessContractRetired:
+		return ErrContractRetired
+	case AccessCreateDenied:
+		return ErrCreateDenied
+	}
+	return ErrReadDenied
+}
+
+// AccessDecision is the outcome of a permission check.
+type AccessDecision struct {
+	Read   bool         // Whether the caller may see the output of the function
+	Write  bool         // Whether state changes of the function are kept
+	Reason AccessReason // Why access was restricted, AccessGranted if it wasn't
+}
+
+// newAccessDecision returns the decision for a policy that can only tell which
+// kinds of access were denied, not why.
+func newAccessDecision(read, write bool) AccessDecision {
+	decision := AccessDecision{Read: read, Write: write}
+	switch {
+	case !read && !write:
+		decision.Reason = AccessDenied
+	case !write:
+		decision.Reason = AccessWriteDenied
+	case !read:
+		decision.Reason = AccessReadDenied
+	}
+	return decision
+}
+
+// newReadDecision returns the decision of a read check for a policy that can't
+// tell why reads were denied.
+func newReadDecision(read bool) AccessDecision {
+	if read {
+		return AccessDecision{Read: true}
+	}
+	return AccessDecision{Reason: AccessReadDenied}
+}
+
+// ContractState is the State of a contract in the AccessRights ContractTable.
+type ContractState uint64
+
+const (
+	ContractStateDisabled   ContractState = 0 // The guardian owning the contract was removed
+	ContractStateActive     ContractState = 1 // Set by CreateContract
+	ContractStateMigrated   ContractState = 2 // The contract was superseded by the one at NewAddress
+	ContractStatePaused     ContractState = 3 // Writes are suspended, reads still allowed
+	ContractStateDeprecated ContractState = 4 // Still usable, but calls are logged with a warning
+	ContractStateRetired    ContractState = 5 // All access is denied
+
+	// contractStateInvalid stands for states that don't fit in 64 bits
+	contractStateInvalid ContractState = ^ContractState(0)
+)
+
+// contractStateNames are the descriptions of the contract states.
+var contractStateNames = map[ContractState]string{
+	ContractStateDisabled:   "disabled",
+	ContractStateActive:     "active",
+	ContractStateMigrated:   "migrated",
+	ContractStatePaused:     "paused",
+	ContractStateDeprecated: "deprecated",
+	ContractStateRetired:    "retired",
+}
+
+// String implements fmt.Stringer.
+func (s ContractState) String() string {
+	if name, ok := contractStateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// restrict narrows decision down to the access a contract in this state
+// allows. Paused contracts can't be written to and retired ones can't be
+// called at all. Other states leave the decision as it is.
+func (s ContractState) restrict(decision AccessDecision) AccessDecision {
+	switch {
+	case s == ContractStateRetired && (decision.Read || decision.Write):
+		return AccessDecision{Reason: AccessContractRetired}
+	case s == ContractStatePaused && decision.Write:
+		decision.Write = false
+		decision.Reason = AccessContractPaused
+	}
+	return decision
+}
+
+// newContractState decodes a State stored in or returned by the access
+// contract.
+func newContractState(word []byte) ContractState {
+	state := new(big.Int).SetBytes(word)
+	if !state.IsUint64() {
+		return contractStateInvalid
+	}
+	return ContractState(state.Uint64())
+}
+
+// ContractStatus is the lifecycle information the access contract keeps about
+// a contract.
+type ContractStatus struct {
+	State      ContractState  // Lifecycle state of the contract
+	NewAddress common.Address // Address of the contract superseding it, if any
+}
+
+// AccessPolicy decides which accounts may deploy contracts and which contract
+// functions an account may read from or write to.
+type AccessPolicy interface {
+	// CanCreate reports whether caller may deploy a new contract.
+	CanCreate(caller common.Address) (bool, error)
+
+	// CheckAccess decides whether caller may read from and write to contract
+	// through the function identified by selector.
+	CheckAccess(caller, contract common.Address, selector [4]byte) (AccessDecision, error)
+
+	// CheckRead decides whether caller may read from contract through the
+	// function identified by selector, leaving write access unevaluated. Its
+	// Read verdict must equal the one of CheckAccess.
+	CheckRead(caller, contract common.Address, selector [4]byte) (AccessDecision, error)
+
+	// RegisterContract records a contract freshly deployed by caller.
+	RegisterContract(caller, contract common.Address) error
+
+	// RecordWrite records the current block as the last one caller wrote
+	// in, which its rate limit is enforced against.
+	RecordWrite(caller common.Address) error
+
+	// ContractStatus returns the lifecycle state of contract and the address
+	// of its replacement.
+	ContractStatus(contract common.Address) (ContractStatus, error)
+
+	// ContributorAccessGroup returns the access groups caller belongs to.
+	ContributorAccessGroup(caller common.Address) (*big.Int, error)
+
+	// IsContributor reports whether caller is a contributor of a guardianship.
+	IsContributor(caller common.Address) (bool, error)
+
+	// AccessDetails returns the access contract entries behind the check of
+	// a call by caller to the function of contract identified by selector.
+	AccessDetails(caller, contract common.Address, selector [4]byte) (*AccessDetails, error)
+}
+
+// contractAccessPolicy is the AccessPolicy backed by the AccessRights contract.
+// Every decision is a nested, zero value call into the contract's code.
+type contractAccessPolicy struct {
+	evm    *EVM
+	access params.VeriteemAccess
+}
+
+// newContractAccessPolicy returns an AccessPolicy that consults the access
+// contract configured by access through evm.
+func newContractAccessPolicy(evm *EVM, access params.VeriteemAccess) *contractAccessPolicy {
+	return &contractAccessPolicy{
+		evm:    evm,
+		access: access,
+	}
+}
+
+// CanCreate implements AccessPolicy, allowing guardians to deploy contracts.
+func (p *contractAccessPolicy) CanCreate(caller common.Address) (bool, error) {
+	ret, err := p.call(caller, packAccessCall(p.access.GuardianshipIndexSelector, caller))
+	if err != nil {
+		return false, err
+	}
+	// GuardianshipIndex returns (bool Match, uint Index), only Match is needed
+	if len(ret) < 64 {
+		return false, errAccessShortReturn
+	}
+	return unpackBool(ret[:32])
+}
+
+// CheckAccess implements AccessPolicy, evaluating VerifyContractAccess. The
+// contract only tells which kinds of access it denied. If it denies writes to a
+// contract the caller may read from, the reason is derived from the entries of
+// the contract and the caller, see writeDenialReason. If it denies all access,
+// the ownership and access group checks of the contract may have failed, which
+// the configured methods can't tell apart from the others, so the reason stays
+// AccessDenied.
+func (p *contractAccessPolicy) CheckAccess(caller, contract common.Address, selector [4]byte) (AccessDecision, error) {
+	read, write, err := p.verifyAccess(caller, contract, selector)
+	if err != nil {
+		return AccessDecision{}, err
+	}
+	decision := newAccessDecision(read, write)
+	if read && !write {
+		if decision.Reason, err = p.writeDenialReason(caller, contract); err != nil {
+			return AccessDecision{}, err
+		}
+	}
+	return decision, nil
+}
+
+// CheckRead implements AccessPolicy. VerifyContractAccess decides reads and
+// writes at once, so it is no cheaper than CheckAccess, but the reason for
+// denied writes isn't looked up.
+func (p *contractAccessPolicy) CheckRead(caller, contract common.Address, selector [4]byte) (AccessDecision, error) {
+	read, _, err := p.verifyAccess(caller, contract, selector)
+	if err != nil {
+		return AccessDecision{}, err
+	}
+	return newReadDecision(read), nil
+}
+
+// verifyAccess calls VerifyContractAccess on behalf of caller.
+func (p *contractAccessPolicy) verifyAccess(caller, contract common.Address, selector [4]byte) (read, write bool, err error) {
+	ret, err := p.call(caller, packAccessCall(p.access.VerifyAccessSelector, contract, FunctionAddress(selector)))
+	if err != nil {
+		return false, false, err
+	}
+	if len(ret) < 64 {
+		return false, false, errAccessShortReturn
+	}
+	if read, err = unpackBool(ret[:32]); err != nil {
+		return false, false, err
+	}
+	if write, err = unpackBool(ret[32:64]); err != nil {
+		return false, false, err
+	}
+	return read, write, nil
+}
+
+// writeDenialReason tells why VerifyContractAccess denied caller writes to a
+// contract it may read from. Readable contracts passed the ownership and access
+// group checks, so the caller either isn't a contributor of a (sub) guardianship
+// of the contract, or the contract's WriteValid flag is cleared, or the caller
+// is rate limited, in this order. ReadContractInfo and ReadContributor tell
+// these apart, but not whether a contributor of another guardianship belongs
+// to a sub guardianship of the contract: AccessWriteDenied is returned if that
+// decides the reason.
+func (p *contractAccessPolicy) writeDenialReason(caller, contract common.Address) (AccessReason, error) {
+	info, err := p.readContractInfo(contract)
+	if err != nil {
+		return AccessWriteDenied, err
+	}
+	writeValid, err := unpackBool(info[96:128])
+	if err != nil {
+		return AccessWriteDenied, err
+	}
+	contributor, err := p.readContributor(caller)
+	if err != nil {
+		return AccessWriteDenied, err
+	}
+	var (
+		guardianship = new(big.Int).SetBytes(contributor[:32])
+		limit        = new(big.Int).SetBytes(contributor[64:96])
+		next         = new(big.Int).SetBytes(contributor[96:128])
+	)
+	next.Add(next, limit).Mod(next, tt256)
+
+	var (
+		owner   = guardianship.Cmp(new(big.Int).SetBytes(info[:32])) == 0
+		limited = limit.Sign() > 0 && next.Cmp(p.evm.BlockNumber) > 0
+	)
+	switch {
+	case guardianship.Sign() == 0:
+		return AccessNoGuardianship, nil
+	case owner && !writeValid:
+		return AccessWriteDisabled, nil
+	case owner && limited:
+		return AccessRateLimited, nil
+	case writeValid && !limited:
+		// A contributor of a sub guardianship would have been allowed to write
+		return AccessNoGuardianship, nil
+	}
+	return AccessWriteDenied, nil
+}
+
+// RegisterContract implements AccessPolicy, adding contract to the creator's
+// guardianship through CreateContract.
+func (p *contractAccessPolicy) RegisterContract(caller, contract common.Address) error {
+	return p.transact(caller, packAccessCall(p.access.CreateContractSelector, contract))
+}
+
+// RecordWrite implements AccessPolicy through UpdateContributorBlock.
+func (p *contractAccessPolicy) RecordWrite(caller common.Address) error {
+	return p.transact(caller, packAccessCall(p.access.UpdateContributorBlockSelector))
+}
+
+// ContractStatus implements AccessPolicy through ReadContractInfo.
+func (p *contractAccessPolicy) ContractStatus(contract common.Address) (ContractStatus, error) {
+	info, err := p.readContractInfo(contract)
+	if err != nil {
+		return ContractStatus{}, err
+	}
+	newAddress, err := unpackAddress(info[64:96])
+	if err != nil {
+		return ContractStatus{}, err
+	}
+	return ContractStatus{
+		State:      newContractState(info[160:192]),
+		NewAddress: newAddress,
+	}, nil
+}
+
+// ContributorAccessGroup implements AccessPolicy through ReadContributor.
+func (p *contractAccessPolicy) ContributorAccessGroup(caller common.Address) (*big.Int, error) {
+	contributor, err := p.readContributor(caller)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(contributor[128:160]), nil
+}
+
+// IsContributor implements AccessPolicy through ReadContributor. Contributors
+// belong to a guardianship, deleted ones are moved to guardianship zero.
+func (p *contractAccessPolicy) IsContributor(caller common.Address) (bool, error) {
+	contributor, err := p.readContributor(caller)
+	if err != nil {
+		return false, err
+	}
+	return new(big.Int).SetBytes(contributor[:32]).Sign() != 0, nil
+}
+
+// AccessDetails implements AccessPolicy through ReadContractInfo and
+// ReadContributor. The access groups of functions aren't exposed by the
+// contract, so FunctionAccessGroup is never set.
+func (p *contractAccessPolicy) AccessDetails(caller, contract common.Address, selector [4]byte) (*AccessDetails, error) {
+	info, err := p.readContractInfo(contract)
+	if err != nil {
+		return nil, err
+	}
+	contributor, err := p.readContributor(caller)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessDetails{
+		Guardianship:            (*hexutil.Big)(new(big.Int).SetBytes(info[:32])),
+		ContributorGuardianship: (*hexutil.Big)(new(big.Int).SetBytes(contributor[:32])),
+		AccessGroup:             (*hexutil.Big)(new(big.Int).SetBytes(contributor[128:160])),
+		Limit:                   (*hexutil.Big)(new(big.Int).SetBytes(contributor[64:96])),
+		LastBlockNumber:         (*hexutil.Big)(new(big.Int).SetBytes(contributor[96:128])),
+	}, nil
+}
+
+// readContractInfo returns the output of ReadContractInfo(contract): (uint
+// Guardianship, string Name, address NewAddress, bool WriteValid, bool
+// GlobalReadValid, uint State), the string by offset.
+func (p *contractAccessPolicy) readContractInfo(contract common.Address) ([]byte, error) {
+	ret, err := p.call(common.Address{}, packAccessCall(p.access.ReadContractInfoSelector, contract))
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) < 6*32 {
+		return nil, errAccessShortReturn
+	}
+	return ret, nil
+}
+
+// readContributor returns the output of ReadContributor(caller): (uint
+// Guardianship, string Name, uint Limit, uint LastBlockNumber, uint AccessGroup),
+// the string by offset.
+func (p *contractAccessPolicy) readContributor(caller common.Address) ([]byte, error) {
+	ret, err := p.call(common.Address{}, packAccessCall(p.access.ReadContributorSelector, caller))
+	if err != nil {
+		return nil, err
+	}
+	if len(ret) < 5*32 {
+		return nil, errAccessShortReturn
+	}
+	return ret, nil
+}
+
+// call runs input against a constant method of the access contract on behalf
+// of caller. The call is static, so the contract's state can't change.
+func (p *contractAccessPolicy) call(caller common.Address, input []byte) ([]byte, error) {
+	ret, _, err := p.evm.StaticCall(AccountRef(caller), p.access.Contract, input, accessCheckGas)
+	return ret, err
+}
+
+// transact runs input against a method of the access contract changing its
+// state on behalf of caller. The EVM makes these calls after the code of a
+// frame ran, at the depth of the frame, so they are hidden from the tracer,
+// which would take them for the outermost frame of the transaction.
+func (p *contractAccessPolicy) transact(caller common.Address, input []byte) error {
+	if p.evm.vmConfig.Debug {
+		p.evm.vmConfig.Debug = false
+		defer func() { p.evm.vmConfig.Debug = true }()
+	}
+	_, _, err := p.evm.Call(AccountRef(caller), p.access.Contract, input, accessCheckGas, new(big.Int))
+	return err
+}
+
+// accessSelector returns the 4 byte function selector of input. Calldata
+// shorter than a selector is padded with zeros.
+func accessSelector(input []byte) (selector [4]byte) {
+	copy(selector[:], input)
+	return selector
+}
+
+// callSelector returns the selector a call to the contract at addr with the
+// given input is checked under, and whether the call reaches the fallback
+// function of the contract. From the selector fork on, calldata shorter than a
+// selector, including none at all, makes a fallback call, checked under the
+// zero selector. The zero selector can't be listed in a FunctionList, so such
+// calls are never caught by the access group of a function that happens to
+// share the leading bytes of a padded selector. Longer calldata makes a
+// fallback call too if the code at addr doesn't dispatch its selector, but
+// keeps its selector. Before the fork the calldata is padded with zeros.
+func (evm *EVM) callSelector(addr common.Address, input []byte) ([4]byte, bool) {
+	if !evm.access.SelectorRules {
+		return accessSelector(input), false
+	}
+	if len(input) < 4 {
+		return [4]byte{}, true
+	}
+	selector := accessSelector(input)
+	return selector, !evm.dispatches(addr, selector)
+}
+
+// dispatches reports whether the code at addr dispatches calls with selector
+// to a function of its own. Accounts without code have no fallback, so any
+// selector counts as dispatched. The dispatched selectors of a code are found
+// once per EVM, see dispatchedSelectors.
+func (evm *EVM) dispatches(addr common.Address, selector [4]byte) bool {
+	hash := evm.StateDB.GetCodeHash(addr)
+	if hash == (common.Hash{}) || hash == emptyCodeHash {
+		return true
+	}
+	selectors, ok := evm.dispatchTables[hash]
+	if !ok {
+		selectors = dispatchedSelectors(evm.StateDB.GetCode(addr))
+		if evm.dispatchTables == nil {
+			evm.dispatchTables = make(map[common.Hash]map[[4]byte]struct{})
+		}
+		evm.dispatchTables[hash] = selectors
+	}
+	_, ok = selectors[selector]
+	return ok
+}
+
+// dispatchedSelectors returns the selectors a contract's code dispatches on:
+// the constants of up to four bytes it pushes, as the dispatcher emitted by
+// the Solidity compiler compares the selector of the calldata against a
+// constant per function. Push data is skipped while walking the code, so bytes
+// that merely look like a push aren't mistaken for one. Other constants can
+// only make the set too large, letting a fallback call pass for a function
+// call, never the reverse.
+func dispatchedSelectors(code []byte) map[[4]byte]struct{} {
+	selectors := make(map[[4]byte]struct{})
+	for pc := 0; pc < len(code); pc++ {
+		op := OpCode(code[pc])
+		if op < PUSH1 || op > PUSH32 {
+			continue
+		}
+		size := int(op-PUSH1) + 1
+		if size <= 4 && pc+size < len(code) {
+			var selector [4]byte
+			copy(selector[4-size:], code[pc+1:pc+1+size])
+			selectors[selector] = struct{}{}
+		}
+		pc += size
+	}
+	return selectors
+}
+
+// FunctionAddress converts a function selector into the address form that the
+// AccessRights contract stores in its FunctionList and expects as the second
+// argument of VerifyContractAccess: the selector in the four low order bytes,
+// 0x00000000000000000000000000000000xxxxxxxx. Functions have to be registered
+// with WriteContractFunctionIndex in this form for their access groups to
+// apply.
+func FunctionAddress(selector [4]byte) common.Address {
+	return common.BytesToAddress(selector[:])
+}
+
+// packAccessCall ABI encodes a call to the access contract method identified by
+// selector taking only address arguments.
+func packAccessCall(selector []byte, args ...common.Address) []byte {
+	input := make([]byte, 0, len(selector)+32*len(args))
+	input = append(input, selector...)
+	for _, arg := range args {
+		input = append(input, common.LeftPadBytes(arg.Bytes(), 32)...)
+	}
+	return input
+}
+
+// unpackBool decodes an ABI encoded bool, rejecting anything but 0 or 1.
+func unpackBool(word []byte) (bool, error) {
+	if len(word) != 32 {
+		return false, errAccessShortReturn
+	}
+	for _, b := range word[:31] {
+		if b != 0 {
+			return false, errAccessMalformedReturn
+		}
+	}
+	switch word[31] {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	}
+	return false, errAccessMalformedReturn
+}
+
+// unpackAddress decodes an ABI encoded address.
+func unpackAddress(word []byte) (common.Address, error) {
+	if len(word) != 32 {
+		return common.Address{}, errAccessShortReturn
+	}
+	for _, b := range word[:32-common.AddressLength] {
+		if b != 0 {
+			return common.Address{}, errAccessMalformedReturn
+		}
+	}
+	return common.BytesToAddress(word), nil
+}
+
+// revertReasonSelector is the selector of Error(string), the ABI encoding of
+// Solidity revert reasons.
+var revertReasonSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// packRevertReason ABI encodes err as a Solidity revert reason, so that clients
+// can decode why a call was denied from its output.
+func packRevertReason(err error) []byte {
+	reason := []byte(err.Error())
+
+	output := make([]byte, 0, len(revertReasonSelector)+64+len(reason)+31)
+	output = append(output, revertReasonSelector...)
+	output = append(output, common.LeftPadBytes(big.NewInt(32).Bytes(), 32)...)
+	output = append(output, common.LeftPadBytes(big.NewInt(int64(len(reason))).Bytes(), 32)...)
+	return append(output, common.RightPadBytes(reason, (len(reason)+31)/32*32)...)
+}
+
+// CheckTransactionAccess runs the permission check the outermost frame of a
+// transaction from sender to the contract at to, or creating a contract if to
+// is nil, faces in block number given statedb. It returns the error the
+// transaction is bound to fail with if its writes would be dropped or the
+// contract was migrated, or nil if the policy lets it through. Any change the
+// check makes to statedb is undone.
+//
+// Dropped writes of a rate limited sender fail with ErrRateLimited whatever the
+// reason the policy gives, as the contract policy can't always tell, so that
+// the transaction may be retried once the rate limit passed.
+func CheckTransactionAccess(statedb StateDB, config *params.ChainConfig, number *big.Int, sender common.Address, to *common.Address, data []byte) error {
+	access, ok := config.VeriteemAccess(number)
+	if !ok || (to != nil && *to == access.Contract) {
+		return nil
+	}
+	snapshot := statedb.Snapshot()
+	defer statedb.RevertToSnapshot(snapshot)
+
+	evm := newAccessEVM(statedb, config, number, sender)
+	policy := evm.accessPolicy
+
+	if to == nil {
+		allowed, err := policy.CanCreate(sender)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrCreateDenied
+		}
+		return nil
+	}
+	contract := *to
+	if newAddress, migrated := evm.migration(contract); migrated {
+		if !access.Forward {
+			return &ContractMigratedError{Contract: contract, NewAddress: newAddress}
+		}
+		contract = newAddress
+	}
+	selector, fallback := evm.callSelector(contract, data)
+	decision, err := evm.checkAccess(sender, contract, selector, fallback, false)
+	if err != nil {
+		return err
+	}
+	if state, ok := evm.contractState(contract); ok {
+		decision = state.restrict(decision)
+	}
+	if !decision.Write {
+		limited, err := evm.rateLimited(sender, contract, selector)
+		if err != nil {
+			return err
+		}
+		if limited {
+			return ErrRateLimited
+		}
+		return decision.Reason.Err()
+	}
+	return nil
+}
+
+// rateLimited reports whether the Limit and LastBlockNumber of the contributor
+// entry of caller, as ReadContributor returns them, keep it from writing in the
+// block of the EVM.
+func (evm *EVM) rateLimited(caller, contract common.Address, selector [4]byte) (bool, error) {
+	details, err := evm.accessPolicy.AccessDetails(caller, contract, selector)
+	if err != nil {
+		return false, err
+	}
+	limit := details.Limit.ToInt()
+	if limit.Sign() == 0 {
+		return false, nil
+	}
+	next := new(big.Int).Add(details.LastBlockNumber.ToInt(), limit)
+	return next.Mod(next, tt256).Cmp(evm.BlockNumber) > 0, nil
+}
+
+// TransactionAccessGas returns the gas the permission check of the outermost
+// frame of a transaction to to, or creating a contract if to is nil, costs in
+// block number on top of the intrinsic gas of the transaction. Like the EVM, it
+// charges nothing for calls of the access contract or of precompiled contracts.
+func TransactionAccessGas(config *params.ChainConfig, number *big.Int, to *common.Address) uint64 {
+	access, ok := config.VeriteemAccess(number)
+	if !ok || !access.ChargeGas {
+		return 0
+	}
+	if to == nil {
+		return params.VeriteemCreateCheckGas
+	}
+	if *to == access.Contract || isPrecompiled(config, number, *to) {
+		return 0
+	}
+	return params.VeriteemAccessCheckGas
+}
+
+// isPrecompiled reports whether addr holds a precompiled contract in block
+// number.
+func isPrecompiled(config *params.ChainConfig, number *big.Int, addr common.Address) bool {
+	precompiles := PrecompiledContractsHomestead
+	if config.IsByzantium(number) {
+		precompiles = PrecompiledContractsByzantium
+	}
+	return precompiles[addr] != nil
+}
+
+// ReadContractStatus returns the lifecycle state the access contract in force
+// at block number records for contract, given statedb. The second return value
+// is false if access control isn't active at that block.
+func ReadContractStatus(statedb StateDB, config *params.ChainConfig, number *big.Int, contract common.Address) (ContractStatus, bool, error) {
+	if _, ok := config.VeriteemAccess(number); !ok {
+		return ContractStatus{}, false, nil
+	}
+	snapshot := statedb.Snapshot()
+	defer statedb.RevertToSnapshot(snapshot)
+
+	status, err := newAccessEVM(statedb, config, number, common.Address{}).accessPolicy.ContractStatus(contract)
+	return status, true, err
+}
+
+// newAccessEVM creates an EVM for consulting the access policy in block number
+// outside of block processing, on behalf of origin.
+func newAccessEVM(statedb StateDB, config *params.ChainConfig, number *big.Int, origin common.Address) *EVM {
+	ctx := Context{
+		CanTransfer: func(db StateDB, addr common.Address, amount *big.Int) bool {
+			return db.GetBalance(addr).Cmp(amount) >= 0
+		},
+		Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		Origin:      origin,
+		GasPrice:    new(big.Int),
+		BlockNumber: number,
+		Time:        new(big.Int),
+		Difficulty:  new(big.Int),
+	}
+	return NewEVM(ctx, statedb, config, Config{})
+}
@@ -0,0 +1,281 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// AccessAuditConfig configures the logging of the permission checks the EVM
+// takes while processing blocks. Those of pending blocks, eth_call and tracing
+// aren't audited.
+type AccessAuditConfig struct {
+	Level     log.Lvl          // Level permission checks are logged at
+	Contracts []common.Address // Contracts whose checks are logged, all if empty
+	Sample    uint64           // Log only one in Sample granted checks, 0 or 1 logs all
+	Sink      io.Writer        // Receives every denial changing an outcome as a line of JSON, if set
+}
+
+// DefaultAccessAuditConfig logs every permission check at debug level.
+var DefaultAccessAuditConfig = AccessAuditConfig{
+	Level:  log.LvlDebug,
+	Sample: 1,
+}
+
+// AccessDenial is the audit record of a call or creation denied by the access
+// policy, as written to the audit sink and kept by the DenialRecorder. Only
+// denials changing the outcome are recorded: failed calls and creations, reads
+// whose output was withheld and writes that were reverted. A call whose writes
+// were denied but which wrote nothing isn't.
+type AccessDenial struct {
+	Time     time.Time      `json:"time"`
+	Block    uint64         `json:"block"`
+	TxHash   common.Hash    `json:"txHash"` // Only known to the DenialRecorder
+	Caller   common.Address `json:"caller"`
+	Contract common.Address `json:"contract"`           // Address of the denied contract, or of the denied creation
+	Selector hexutil.Bytes  `json:"selector,omitempty"` // Only set for calls
+	Create   bool           `json:"create"`
+	Reason   string         `json:"reason"`
+}
+
+// AccessDenialRecorder persists the access denials of processed blocks.
+type AccessDenialRecorder interface {
+	// RecordDenials stores the denials taken while processing the block
+	// number with hash hash, replacing any recorded for it before.
+	RecordDenials(number uint64, hash common.Hash, denials []AccessDenial)
+}
+
+// AccessDenialBatch gathers the access denials of the transactions of a block,
+// so that they are handed to the DenialRecorder at once when the block has
+// been processed. Only the state processor sets one up, which keeps the
+// denials of pending blocks, eth_call and tracing out of the audit.
+type AccessDenialBatch struct {
+	recorder AccessDenialRecorder
+	denials  []AccessDenial
+}
+
+// NewAccessDenialBatch creates a batch of the denials of a block for recorder.
+// Without a recorder the denials are audited, but not kept.
+func NewAccessDenialBatch(recorder AccessDenialRecorder) *AccessDenialBatch {
+	return &AccessDenialBatch{recorder: recorder}
+}
+
+// Record hands the denials of the processed block number with hash hash to
+// the recorder. A nil batch records nothing.
+func (b *AccessDenialBatch) Record(number uint64, hash common.Hash) {
+	if b == nil || b.recorder == nil {
+		return
+	}
+	b.recorder.RecordDenials(number, hash, b.denials)
+	b.denials = nil
+}
+
+// auditing reports whether the EVM is processing a block, the only time its
+// permission checks are audited.
+func (evm *EVM) auditing() bool {
+	return evm.vmConfig.AccessDenials != nil
+}
+
+// recordDenial keeps a denial until the transaction that caused it is done, if
+// there is a recorder to hand it to.
+func (evm *EVM) recordDenial(denial AccessDenial) {
+	if evm.vmConfig.AccessDenials.recorder != nil {
+		evm.accessDenials = append(evm.accessDenials, denial)
+	}
+}
+
+// FlushAccessDenials adds the denials taken while running the transaction
+// txHash to the AccessDenialBatch of the EVM configuration, if any.
+func (evm *EVM) FlushAccessDenials(txHash common.Hash) {
+	if evm.vmConfig.AccessDenials == nil || len(evm.accessDenials) == 0 {
+		return
+	}
+	for i := range evm.accessDenials {
+		evm.accessDenials[i].TxHash = txHash
+	}
+	evm.vmConfig.AccessDenials.denials = append(evm.vmConfig.AccessDenials.denials, evm.accessDenials...)
+	evm.accessDenials = nil
+}
+
+// accessAudit is the auditor of the permission checks of all EVMs.
+var accessAudit atomic.Value
+
+func init() {
+	SetAccessAudit(DefaultAccessAuditConfig)
+}
+
+// SetAccessAudit replaces the configuration of the permission check logging.
+func SetAccessAudit(config AccessAuditConfig) {
+	auditor := &accessAuditor{
+		level:  config.Level,
+		sample: config.Sample,
+		sink:   config.Sink,
+	}
+	if len(config.Contracts) > 0 {
+		auditor.contracts = make(map[common.Address]bool)
+		for _, contract := range config.Contracts {
+			auditor.contracts[contract] = true
+		}
+	}
+	accessAudit.Store(auditor)
+}
+
+// accessAuditor logs permission checks according to an AccessAuditConfig.
+// Denials are never sampled out.
+type accessAuditor struct {
+	level     log.Lvl
+	contracts map[common.Address]bool
+	sample    uint64
+	checks    uint64 // Number of granted checks seen, accessed atomically
+
+	sinkLock sync.Mutex
+	sink     io.Writer
+}
+
+// auditor returns the auditor in force.
+func auditor() *accessAuditor {
+	return accessAudit.Load().(*accessAuditor)
+}
+
+// audited reports whether the checks on contract are logged.
+func (a *accessAuditor) audited(contract common.Address) bool {
+	return a.contracts == nil || a.contracts[contract]
+}
+
+// sampled reports whether a granted check is to be logged.
+func (a *accessAuditor) sampled() bool {
+	if a.sample <= 1 {
+		return true
+	}
+	return atomic.AddUint64(&a.checks, 1)%a.sample == 0
+}
+
+// check audits a permission check on a call. Calls denied reads are denials,
+// as they fail or have their output withheld. The denial of the writes alone
+// is returned instead, to be audited only if the call turns out to have had
+// writes reverted by it. Calldata isn't logged, only the selector of the
+// function.
+func (a *accessAuditor) check(evm *EVM, caller, contract common.Address, selector [4]byte, decision AccessDecision) *AccessDenial {
+	if !evm.auditing() {
+		return nil
+	}
+	var denial *AccessDenial
+	if !decision.Read || !decision.Write {
+		denial = &AccessDenial{
+			Block:    evm.BlockNumber.Uint64(),
+			Caller:   caller,
+			Contract: contract,
+			Selector: common.CopyBytes(selector[:]),
+			Reason:   decision.Reason.String(),
+		}
+	}
+	if a.audited(contract) && (!decision.Read || a.sampled()) {
+		a.log("Contract access checked", "caller", caller, "contract", contract, "selector", hexutil.Bytes(selector[:]),
+			"read", decision.Read, "write", decision.Write, "reason", decision.Reason)
+	}
+	if !decision.Read {
+		a.deny(evm, *denial)
+		return nil
+	}
+	return denial
+}
+
+// migrated audits a call to a migrated contract, which is a denial unless it
+// was forwarded to the new contract.
+func (a *accessAuditor) migrated(evm *EVM, caller, contract, newAddress common.Address, selector [4]byte, forwarded bool) {
+	if !evm.auditing() {
+		return
+	}
+	if a.audited(contract) {
+		a.log("Migrated contract called", "caller", caller, "contract", contract, "selector", hexutil.Bytes(selector[:]),
+			"newaddress", newAddress, "forwarded", forwarded)
+	}
+	if !forwarded {
+		a.deny(evm, AccessDenial{
+			Block:    evm.BlockNumber.Uint64(),
+			Caller:   caller,
+			Contract: contract,
+			Selector: common.CopyBytes(selector[:]),
+			Reason:   (&ContractMigratedError{Contract: contract, NewAddress: newAddress}).Error(),
+		})
+	}
+}
+
+// create audits a permission check on a contract creation.
+func (a *accessAuditor) create(evm *EVM, caller, address common.Address, allowed bool) {
+	if !evm.auditing() {
+		return
+	}
+	if a.audited(address) && (!allowed || a.sampled()) {
+		a.log("Contract creation checked", "caller", caller, "address", address, "allowed", allowed)
+	}
+	if !allowed {
+		a.deny(evm, AccessDenial{
+			Block:    evm.BlockNumber.Uint64(),
+			Caller:   caller,
+			Contract: address,
+			Create:   true,
+			Reason:   AccessCreateDenied.String(),
+		})
+	}
+}
+
+// deny audits a denial that changed the outcome of a call or creation of the
+// block being processed: it is kept for the recorder and, if the contract is
+// audited, written to the audit sink.
+func (a *accessAuditor) deny(evm *EVM, denial AccessDenial) {
+	evm.recordDenial(denial)
+	if a.sink == nil || !a.audited(denial.Contract) {
+		return
+	}
+	denial.Time = time.Now()
+	line, err := json.Marshal(denial)
+	if err != nil {
+		log.Warn("Failed to encode access denial", "err", err)
+		return
+	}
+	a.sinkLock.Lock()
+	defer a.sinkLock.Unlock()
+
+	if _, err := a.sink.Write(append(line, '\n')); err != nil {
+		log.Warn("Failed to write access denial", "err", err)
+	}
+}
+
+// log writes a message at the configured level.
+func (a *accessAuditor) log(msg string, ctx ...interface{}) {
+	switch a.level {
+	case log.LvlCrit, log.LvlError:
+		log.Error(msg, ctx...)
+	case log.LvlWarn:
+		log.Warn(msg, ctx...)
+	case log.LvlInfo:
+		log.Info(msg, ctx...)
+	case log.LvlDebug:
+		log.Debug(msg, ctx...)
+	default:
+		log.Trace(msg, ctx...)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Tests that the denials changing the outcome of calls and creations on the
+// audited contracts of a block being processed are written to the audit sink:
+// failed calls and creations and reverted writes, but not granted checks,
+// denied writes of calls that wrote nothing, other contracts or checks outside
+// block processing.
+func TestAccessAuditSink(t *testing.T) {
+	outsider := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	config := newAccessTestConfig(allAccessForks(true))
+	evm, guardian, member := newAccessTest(t, config, 1, 0)
+
+	counter := deployTestContract(t, evm, guardian, testCounterCode())
+	disabled := deployTestContract(t, evm, guardian, testCounterCode())
+	writeContractInfo(t, evm, guardian, disabled, false, true)
+	reader := deployTestContract(t, evm, guardian, testReaderCode())
+	writeContractInfo(t, evm, guardian, reader, false, true)
+	private := deployTestContract(t, evm, guardian, testReaderCode())
+	writeContractInfo(t, evm, guardian, private, true, false)
+	ignored := deployTestContract(t, evm, guardian, testReaderCode())
+	writeContractInfo(t, evm, guardian, ignored, true, false)
+
+	created := crypto.CreateAddress(outsider, evm.StateDB.GetNonce(outsider))
+	sink := new(bytes.Buffer)
+	SetAccessAudit(AccessAuditConfig{
+		Level:     log.LvlTrace,
+		Contracts: []common.Address{counter, disabled, reader, private, created},
+		Sink:      sink,
+	})
+	defer SetAccessAudit(DefaultAccessAuditConfig)
+
+	// Calls outside of block processing, such as eth_call, aren't audited
+	evm.Call(AccountRef(outsider), private, nil, testGas, new(big.Int))
+
+	evm = newAccessTestEVM(evm.StateDB, config, 1, Config{AccessDenials: NewAccessDenialBatch(nil)})
+	evm.Call(AccountRef(member), counter, nil, testGas, new(big.Int))
+	evm.Call(AccountRef(member), disabled, nil, testGas, new(big.Int))
+	evm.Call(AccountRef(member), reader, nil, testGas, new(big.Int))
+	evm.Call(AccountRef(outsider), private, []byte{0x12, 0x34, 0x56, 0x78}, testGas, new(big.Int))
+	evm.Call(AccountRef(outsider), ignored, nil, testGas, new(big.Int))
+	evm.Create(AccountRef(outsider), testReaderCode(), testGas, new(big.Int))
+
+	want := []AccessDenial{
+		{Block: 1, Caller: member, Contract: disabled, Selector: make([]byte, 4), Reason: AccessWriteDisabled.String()},
+		{Block: 1, Caller: outsider, Contract: private, Selector: []byte{0x12, 0x34, 0x56, 0x78}, Reason: AccessNoGuardianship.String()},
+		{Block: 1, Caller: outsider, Contract: created, Create: true, Reason: AccessCreateDenied.String()},
+	}
+	decoder := json.NewDecoder(sink)
+	i := 0
+	for ; decoder.More(); i++ {
+		var denial AccessDenial
+		if err := decoder.Decode(&denial); err != nil {
+			t.Fatalf("line %d: failed to decode denial: %v", i, err)
+		}
+		if i >= len(want) {
+			t.Fatalf("line %d: unexpected denial %+v", i, denial)
+		}
+		if denial.Time.IsZero() {
+			t.Errorf("line %d: denial not timestamped", i)
+		}
+		if denial.Block != want[i].Block || denial.Caller != want[i].Caller || denial.Contract != want[i].Contract ||
+			!bytes.Equal(denial.Selector, want[i].Selector) || denial.Create != want[i].Create || denial.Reason != want[i].Reason {
+			t.Errorf("line %d: have %+v, want %+v", i, denial, want[i])
+		}
+	}
+	if i != len(want) {
+		t.Errorf("%d denials written, want %d", i, len(want))
+	}
+}
+
+// Tests that one in sample granted checks is logged.
+func TestAccessAuditSample(t *testing.T) {
+	for _, sample := range []uint64{0, 1, 3} {
+		auditor := &accessAuditor{sample: sample}
+
+		logged := 0
+		for i := 0; i < 9; i++ {
+			if auditor.sampled() {
+				logged++
+			}
+		}
+		want := 9
+		if sample > 1 {
+			want = 3
+		}
+		if logged != want {
+			t.Errorf("sample %d: logged %d of 9 checks, want %d", sample, logged, want)
+		}
+	}
+}
+
+// testDenialRecorder keeps the denials handed to it.
+type testDenialRecorder struct {
+	records int
+	number  uint64
+	hash    common.Hash
+	denials []AccessDenial
+}
+
+// RecordDenials implements AccessDenialRecorder.
+func (r *testDenialRecorder) RecordDenials(number uint64, hash common.Hash, denials []AccessDenial) {
+	r.records++
+	r.number, r.hash, r.denials = number, hash, denials
+}
+
+// Tests that the denials of the transactions of a block are handed to the
+// denial recorder once the block is processed, whether or not they are
+// audited, and that EVMs outside block processing record nothing.
+func TestAccessDenialRecorder(t *testing.T) {
+	outsider := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	config := newAccessTestConfig(allAccessForks(true))
+	evm, guardian, member := newAccessTest(t, config, 1, 0)
+
+	private := deployTestContract(t, evm, guardian, testReaderCode())
+	writeContractInfo(t, evm, guardian, private, true, false)
+	reader := deployTestContract(t, evm, guardian, testReaderCode())
+	writeContractInfo(t, evm, guardian, reader, false, true)
+
+	SetAccessAudit(AccessAuditConfig{Contracts: []common.Address{guardian}})
+	defer SetAccessAudit(DefaultAccessAuditConfig)
+
+	// Calls outside of block processing, such as eth_call, aren't recorded
+	recorder := new(testDenialRecorder)
+	call := newAccessTestEVM(evm.StateDB, config, 1, Config{DenialRecorder: recorder})
+	call.Call(AccountRef(outsider), private, nil, testGas, new(big.Int))
+	call.FlushAccessDenials(common.HexToHash("0x01"))
+
+	batch := NewAccessDenialBatch(recorder)
+	evm = newAccessTestEVM(evm.StateDB, config, 1, Config{DenialRecorder: recorder, AccessDenials: batch})
+
+	evm.Call(AccountRef(member), private, nil, testGas, new(big.Int))
+	evm.Call(AccountRef(outsider), private, nil, testGas, new(big.Int))
+	evm.FlushAccessDenials(common.HexToHash("0x01"))
+	// Transactions without denials changing an outcome add nothing
+	evm.Call(AccountRef(member), reader, nil, testGas, new(big.Int))
+	evm.FlushAccessDenials(common.HexToHash("0x02"))
+	evm.Create(AccountRef(outsider), testReaderCode(), testGas, new(big.Int))
+	evm.FlushAccessDenials(common.HexToHash("0x03"))
+
+	if recorder.records != 0 {
+		t.Fatalf("denials recorded before the block was processed")
+	}
+	hash := common.HexToHash("0xb1")
+	batch.Record(1, hash)
+	if recorder.records != 1 || recorder.number != 1 || recorder.hash != hash {
+		t.Fatalf("recorded %d times, block %d hash %x, want once, block 1 hash %x", recorder.records, recorder.number, recorder.hash, hash)
+	}
+	if len(recorder.denials) != 2 || recorder.denials[0].Contract != private || !recorder.denials[1].Create {
+		t.Fatalf("recorded denials mismatch: %+v", recorder.denials)
+	}
+	for i, tx := range []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x03")} {
+		if denial := recorder.denials[i]; denial.TxHash != tx || denial.Caller != outsider {
+			t.Errorf("denial %d: have %+v, want tx %x caller %x", i, denial, tx, outsider)
+		}
+	}
+	// A batch without a recorder keeps nothing, and a nil one records nothing
+	unrecorded := NewAccessDenialBatch(nil)
+	evm = newAccessTestEVM(evm.StateDB, config, 1, Config{AccessDenials: unrecorded})
+	evm.Call(AccountRef(outsider), private, nil, testGas, new(big.Int))
+	evm.FlushAccessDenials(common.HexToHash("0x04"))
+	if len(unrecorded.denials) != 0 {
+		t.Errorf("denials kept without recorder: %+v", unrecorded.denials)
+	}
+	unrecorded.Record(2, hash)
+
+	var none *AccessDenialBatch
+	none.Record(2, hash)
+}
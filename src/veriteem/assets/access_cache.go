@@ -0,0 +1,230 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	accessCacheHitMeter        = metrics.NewRegisteredMeter("vm/access/cache/hit", nil)
+	accessCacheMissMeter       = metrics.NewRegisteredMeter("vm/access/cache/miss", nil)
+	accessCacheInvalidateMeter = metrics.NewRegisteredMeter("vm/access/cache/invalidate", nil)
+)
+
+// accessKey identifies a permission check.
+type accessKey struct {
+	caller   common.Address
+	contract common.Address
+	selector [4]byte
+}
+
+// AccessCache memoizes the permission decisions taken while processing a
+// block, so that the same caller, contract and selector triple is evaluated
+// against the access contract only once. Any transaction frame that may have
+// modified the storage of the access contract invalidates the cache, keeping
+// the cached results identical to the uncached ones.
+//
+// An AccessCache must only be shared by the transactions of a single block and
+// is not thread safe. A nil AccessCache is valid and caches nothing.
+type AccessCache struct {
+	decisions map[accessKey]AccessDecision
+	reads     map[accessKey]AccessDecision
+	creators  map[common.Address]bool
+	statuses  map[common.Address]ContractStatus
+	groups    map[common.Address]*big.Int
+	members   map[common.Address]bool
+	epoch     uint64 // Number of invalidations so far
+}
+
+// NewAccessCache creates an empty access decision cache.
+func NewAccessCache() *AccessCache {
+	return &AccessCache{
+		decisions: make(map[accessKey]AccessDecision),
+		reads:     make(map[accessKey]AccessDecision),
+		creators:  make(map[common.Address]bool),
+		statuses:  make(map[common.Address]ContractStatus),
+		groups:    make(map[common.Address]*big.Int),
+		members:   make(map[common.Address]bool),
+	}
+}
+
+// Invalidate drops all cached decisions.
+func (c *AccessCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.decisions = make(map[accessKey]AccessDecision)
+	c.reads = make(map[accessKey]AccessDecision)
+	c.creators = make(map[common.Address]bool)
+	c.statuses = make(map[common.Address]ContractStatus)
+	c.groups = make(map[common.Address]*big.Int)
+	c.members = make(map[common.Address]bool)
+	c.epoch++
+	accessCacheInvalidateMeter.Mark(1)
+}
+
+// invalidateCaller drops the cached decisions on calls made by caller.
+func (c *AccessCache) invalidateCaller(caller common.Address) {
+	for key := range c.decisions {
+		if key.caller == caller {
+			delete(c.decisions, key)
+		}
+	}
+	c.epoch++
+	accessCacheInvalidateMeter.Mark(1)
+}
+
+// Epoch returns a counter that changes every time the cache is invalidated.
+func (c *AccessCache) Epoch() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.epoch
+}
+
+// Reverted is called when the state is reverted to a snapshot taken at the
+// given cache epoch. If the access contract was modified since, decisions
+// cached afterwards reflect discarded state and are dropped.
+func (c *AccessCache) Reverted(epoch uint64) {
+	if c != nil && c.epoch != epoch {
+		c.Invalidate()
+	}
+}
+
+// cachedAccessPolicy is an AccessPolicy answering permission checks from an
+// AccessCache, falling back to the wrapped policy on a miss.
+type cachedAccessPolicy struct {
+	policy AccessPolicy
+	cache  *AccessCache
+}
+
+// CanCreate implements AccessPolicy.
+func (p *cachedAccessPolicy) CanCreate(caller common.Address) (bool, error) {
+	if allowed, ok := p.cache.creators[caller]; ok {
+		accessCacheHitMeter.Mark(1)
+		return allowed, nil
+	}
+	accessCacheMissMeter.Mark(1)
+
+	allowed, err := p.policy.CanCreate(caller)
+	if err == nil {
+		p.cache.creators[caller] = allowed
+	}
+	return allowed, err
+}
+
+// CheckAccess implements AccessPolicy.
+func (p *cachedAccessPolicy) CheckAccess(caller, contract common.Address, selector [4]byte) (AccessDecision, error) {
+	key := accessKey{caller: caller, contract: contract, selector: selector}
+	if decision, ok := p.cache.decisions[key]; ok {
+		accessCacheHitMeter.Mark(1)
+		return decision, nil
+	}
+	accessCacheMissMeter.Mark(1)
+
+	decision, err := p.policy.CheckAccess(caller, contract, selector)
+	if err == nil {
+		p.cache.decisions[key] = decision
+	}
+	return decision, err
+}
+
+// CheckRead implements AccessPolicy.
+func (p *cachedAccessPolicy) CheckRead(caller, contract common.Address, selector [4]byte) (AccessDecision, error) {
+	key := accessKey{caller: caller, contract: contract, selector: selector}
+	if decision, ok := p.cache.reads[key]; ok {
+		accessCacheHitMeter.Mark(1)
+		return decision, nil
+	}
+	accessCacheMissMeter.Mark(1)
+
+	decision, err := p.policy.CheckRead(caller, contract, selector)
+	if err == nil {
+		p.cache.reads[key] = decision
+	}
+	return decision, err
+}
+
+// RecordWrite implements AccessPolicy. The rate limit of caller may deny its
+// next writes, so the decisions on its calls are dropped. Reads don't depend
+// on the rate limit and stay cached.
+func (p *cachedAccessPolicy) RecordWrite(caller common.Address) error {
+	defer p.cache.invalidateCaller(caller)
+	return p.policy.RecordWrite(caller)
+}
+
+// RegisterContract implements AccessPolicy. Registration writes to the access
+// contract, so the cache is invalidated.
+func (p *cachedAccessPolicy) RegisterContract(caller, contract common.Address) error {
+	defer p.cache.Invalidate()
+	return p.policy.RegisterContract(caller, contract)
+}
+
+// ContractStatus implements AccessPolicy.
+func (p *cachedAccessPolicy) ContractStatus(contract common.Address) (ContractStatus, error) {
+	if status, ok := p.cache.statuses[contract]; ok {
+		accessCacheHitMeter.Mark(1)
+		return status, nil
+	}
+	accessCacheMissMeter.Mark(1)
+
+	status, err := p.policy.ContractStatus(contract)
+	if err == nil {
+		p.cache.statuses[contract] = status
+	}
+	return status, err
+}
+
+// ContributorAccessGroup implements AccessPolicy.
+func (p *cachedAccessPolicy) ContributorAccessGroup(caller common.Address) (*big.Int, error) {
+	if group, ok := p.cache.groups[caller]; ok {
+		accessCacheHitMeter.Mark(1)
+		return group, nil
+	}
+	accessCacheMissMeter.Mark(1)
+
+	group, err := p.policy.ContributorAccessGroup(caller)
+	if err == nil {
+		p.cache.groups[caller] = group
+	}
+	return group, err
+}
+
+// IsContributor implements AccessPolicy.
+func (p *cachedAccessPolicy) IsContributor(caller common.Address) (bool, error) {
+	if member, ok := p.cache.members[caller]; ok {
+		accessCacheHitMeter.Mark(1)
+		return member, nil
+	}
+	accessCacheMissMeter.Mark(1)
+
+	member, err := p.policy.IsContributor(caller)
+	if err == nil {
+		p.cache.members[caller] = member
+	}
+	return member, err
+}
+
+// AccessDetails implements AccessPolicy. Details are only looked up by
+// tracers, so they aren't cached.
+func (p *cachedAccessPolicy) AccessDetails(caller, contract common.Address, selector [4]byte) (*AccessDetails, error) {
+	return p.policy.AccessDetails(caller, contract, selector)
+}
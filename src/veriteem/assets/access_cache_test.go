@@ -0,0 +1,242 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that a cached policy answers like the policy it wraps, whether the
+// answers come from the cache or not.
+func TestCachedAccessPolicy(t *testing.T) {
+	for _, native := range []bool{false, true} {
+		config := newAccessTestConfig(allAccessForks(native))
+		for seed := int64(0); seed < 10; seed++ {
+			var (
+				rnd     = rand.New(rand.NewSource(seed))
+				statedb = newAccessTestState(t)
+			)
+			callers, contracts, selectors := randomAccessTables(rnd, accessTables{statedb, testAccessContract})
+
+			evm := newAccessTestEVM(statedb, config, uint64(rnd.Intn(25)), Config{AccessCache: NewAccessCache()})
+			access, _ := config.VeriteemAccess(evm.BlockNumber)
+			want := NewAccessPolicy(evm, access)
+
+			// Once to fill the cache, once to answer from it
+			for i := 0; i < 2; i++ {
+				if err := compareAccessPolicies(want, evm.accessPolicy, callers, contracts, selectors); err != nil {
+					t.Errorf("native %v seed %d pass %d: %v", native, seed, i, err)
+				}
+			}
+		}
+	}
+}
+
+// newCacheTestEVM returns an EVM with a cached native policy over tables where
+// caller, a rate limited contributor of the only guardianship, may write to
+// contract, and other may only read from it.
+func newCacheTestEVM(t *testing.T) (evm *EVM, guardian, caller, other, contract common.Address) {
+	var (
+		statedb = newAccessTestState(t)
+		tables  = accessTables{statedb, testAccessContract}
+	)
+	guardian = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	caller = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	other = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	contract = common.HexToAddress("0x00000000000000000000000000000000000000dd")
+
+	tables.setGuardians(1, guardian, common.Address{})
+	tables.setContract(contract, testContractEntry{Guardianship: 1, WriteValid: true, GlobalReadValid: true, State: ContractStateActive})
+	tables.setContributor(caller, testContributorEntry{Guardianship: 1, Limit: 5})
+	tables.setContributor(other, testContributorEntry{Guardianship: 2, Limit: 5})
+
+	evm = newAccessTestEVM(statedb, newAccessTestConfig(allAccessForks(true)), 10, Config{AccessCache: NewAccessCache()})
+	return evm, guardian, caller, other, contract
+}
+
+// Tests that recording a write only drops the cached decisions on the calls of
+// the writer, which may now be rate limited.
+func TestAccessCacheRecordWrite(t *testing.T) {
+	evm, _, caller, other, contract := newCacheTestEVM(t)
+	policy := evm.accessPolicy
+
+	if decision, err := policy.CheckAccess(caller, contract, [4]byte{}); err != nil || !decision.Write {
+		t.Fatalf("write before recording: have %+v, %v", decision, err)
+	}
+	if _, err := policy.CheckAccess(other, contract, [4]byte{}); err != nil {
+		t.Fatalf("check of other caller failed: %v", err)
+	}
+	if _, err := policy.CheckRead(caller, contract, [4]byte{}); err != nil {
+		t.Fatalf("read check failed: %v", err)
+	}
+	epoch := evm.accessCache.Epoch()
+	if err := policy.RecordWrite(caller); err != nil {
+		t.Fatalf("failed to record write: %v", err)
+	}
+	if evm.accessCache.Epoch() == epoch {
+		t.Errorf("epoch unchanged by recorded write")
+	}
+	if _, ok := evm.accessCache.decisions[accessKey{caller: caller, contract: contract}]; ok {
+		t.Errorf("decision of writer still cached")
+	}
+	if _, ok := evm.accessCache.decisions[accessKey{caller: other, contract: contract}]; !ok {
+		t.Errorf("decision of other caller dropped")
+	}
+	if _, ok := evm.accessCache.reads[accessKey{caller: caller, contract: contract}]; !ok {
+		t.Errorf("read decision of writer dropped")
+	}
+	want, _ := newNativeAccessPolicy(evm, evm.access).CheckAccess(caller, contract, [4]byte{})
+	have, err := policy.CheckAccess(caller, contract, [4]byte{})
+	if err != nil || have != want || want.Reason != AccessRateLimited {
+		t.Errorf("write after recording: have %+v (%v), want %+v rate limited", have, err, want)
+	}
+}
+
+// Tests that registering a contract, which writes to the access contract, drops
+// every cached decision.
+func TestAccessCacheRegisterContract(t *testing.T) {
+	evm, guardian, caller, _, _ := newCacheTestEVM(t)
+	policy := evm.accessPolicy
+
+	created := common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	if decision, err := policy.CheckAccess(caller, created, [4]byte{}); err != nil || decision.Reason != AccessUnownedContract {
+		t.Fatalf("access before registration: have %+v, %v", decision, err)
+	}
+	if err := policy.RegisterContract(guardian, created); err != nil {
+		t.Fatalf("failed to register contract: %v", err)
+	}
+	if len(evm.accessCache.decisions) != 0 {
+		t.Errorf("%d decisions still cached", len(evm.accessCache.decisions))
+	}
+	if decision, err := policy.CheckAccess(caller, created, [4]byte{}); err != nil || decision.Reason != AccessGranted {
+		t.Errorf("access after registration: have %+v, %v", decision, err)
+	}
+}
+
+// Tests that reverting the state drops the decisions cached since, but only if
+// the access contract was written to in the meantime.
+func TestAccessCacheReverted(t *testing.T) {
+	evm, _, caller, _, contract := newCacheTestEVM(t)
+	var (
+		policy  = evm.accessPolicy
+		statedb = evm.StateDB
+		key     = accessKey{caller: caller, contract: contract}
+	)
+	// Reverting without any write to the access contract keeps the cache
+	snapshot, epoch := statedb.Snapshot(), evm.accessCache.Epoch()
+	policy.CheckAccess(caller, contract, [4]byte{})
+	statedb.RevertToSnapshot(snapshot)
+	evm.accessCache.Reverted(epoch)
+
+	if _, ok := evm.accessCache.decisions[key]; !ok {
+		t.Fatalf("decision dropped by revert leaving the access contract untouched")
+	}
+	// Disable writes within a frame, as its code would, and cache the decision
+	snapshot, epoch = statedb.Snapshot(), evm.accessCache.Epoch()
+	accessTables{statedb, testAccessContract}.setContract(contract, testContractEntry{Guardianship: 1, GlobalReadValid: true, State: ContractStateActive})
+	evm.accessStorageWritten(testAccessContract)
+
+	if decision, _ := policy.CheckAccess(caller, contract, [4]byte{}); decision.Write {
+		t.Fatalf("write allowed to disabled contract")
+	}
+	// Reverting the frame has to bring back the decision of the original state
+	statedb.RevertToSnapshot(snapshot)
+	evm.accessCache.Reverted(epoch)
+
+	if decision, err := policy.CheckAccess(caller, contract, [4]byte{}); err != nil || decision.Reason != AccessGranted {
+		t.Errorf("access after revert: have %+v, %v", decision, err)
+	}
+}
+
+// Tests that a guardian blocking a contract takes effect on the very next call
+// of the block, although the decision to allow it was cached.
+func TestAccessCacheAdministration(t *testing.T) {
+	for _, native := range []bool{false, true} {
+		config := newAccessTestConfig(allAccessForks(native))
+		evm, guardian, member := newAccessTest(t, config, 1, 0)
+		contract := deployTestContract(t, evm, guardian, testCounterCode())
+
+		evm = newAccessTestEVM(evm.StateDB, config, 1, Config{AccessCache: NewAccessCache()})
+		if _, _, err := evm.Call(AccountRef(member), contract, nil, testGas, new(big.Int)); err != nil {
+			t.Fatalf("native %v: call before blocking failed: %v", native, err)
+		}
+		writeContractInfo(t, evm, guardian, contract, false, false)
+		if _, _, err := evm.Call(AccountRef(member), contract, nil, testGas, new(big.Int)); err == nil {
+			t.Errorf("native %v: call after blocking succeeded", native)
+		}
+	}
+}
+
+// BenchmarkAccessCache measures a public read workload: certification queries,
+// as run against the ZigBee certification registry, made by static calls of
+// outsiders to a GlobalReadValid contract, with and without the read fast path
+// and the decision cache.
+func BenchmarkAccessCache(b *testing.B) {
+	var (
+		registry = common.HexToAddress("0x00000000000000000000000000000000000000dd")
+		guardian = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		query    = common.Hex2Bytes("a9059cbb0000000000000000000000000000000000000000000000000000000000000001")
+		// PUSH1 1 PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN: certified
+		code = common.Hex2Bytes("600160005260206000f3")
+	)
+	callers := make([]common.Address, 16)
+	for i := range callers {
+		callers[i] = common.BigToAddress(big.NewInt(int64(0x1000 + i)))
+	}
+	benchmarks := []struct {
+		name        string
+		native      bool
+		staticReads bool
+		cached      bool
+	}{
+		{"contract", false, false, false},
+		{"native", true, false, false},
+		{"native/fastpath", true, true, false},
+		{"native/fastpath/cached", true, true, true},
+	}
+	for _, bench := range benchmarks {
+		b.Run(bench.name, func(b *testing.B) {
+			statedb := newAccessTestState(b)
+			tables := accessTables{statedb, testAccessContract}
+			tables.setGuardians(1, guardian, common.Address{})
+			tables.setContract(registry, testContractEntry{Guardianship: 1, WriteValid: true, GlobalReadValid: true, State: ContractStateActive})
+			statedb.SetCode(registry, code)
+
+			veriteem := allAccessForks(bench.native)
+			if !bench.staticReads {
+				veriteem.StaticReadBlock = nil
+			}
+			vmConfig := Config{}
+			if bench.cached {
+				vmConfig.AccessCache = NewAccessCache()
+			}
+			evm := newAccessTestEVM(statedb, newAccessTestConfig(veriteem), 100, vmConfig)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ret, _, err := evm.StaticCall(AccountRef(callers[i%len(callers)]), registry, query, 100000)
+				if err != nil || len(ret) != 32 {
+					b.Fatalf("query failed: %x, %v", ret, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testChildCode is the creation code of the contracts created by factories,
+// deploying the runtime code STOP.
+var testChildCode = []byte{0x60, 0x00, 0x60, 0x00, 0x53, 0x60, 0x01, 0x60, 0x00, 0xf3}
+
+// testFactoryCode returns the creation code of a contract creating a contract
+// with testChildCode on every call, with CREATE or, if create2 is set, CREATE2,
+// and storing the address of the latest contract, or zero if creation failed,
+// in storage slot zero.
+func testFactoryCode(create2 bool) []byte {
+	size := byte(len(testChildCode))
+	offset := byte(18)
+	if create2 {
+		offset = 20
+	}
+	// PUSH1 size PUSH1 offset PUSH1 0 CODECOPY: the child's code
+	code := []byte{0x60, size, 0x60, offset, 0x60, 0x00, 0x39}
+	if create2 {
+		// PUSH1 0 PUSH1 size PUSH1 0 PUSH1 0 CREATE2: salt 0, no value
+		code = append(code, 0x60, 0x00, 0x60, size, 0x60, 0x00, 0x60, 0x00, byte(CREATE2))
+	} else {
+		// PUSH1 size PUSH1 0 PUSH1 0 CREATE: no value
+		code = append(code, 0x60, size, 0x60, 0x00, 0x60, 0x00, byte(CREATE))
+	}
+	// PUSH1 0 SSTORE STOP
+	code = append(code, 0x60, 0x00, 0x55, 0x00)
+	return testDeployCode(append(code, testChildCode...))
+}
+
+// Tests that only guardians may deploy contracts. Before the create denial fork
+// others deploy an empty contract; from the fork on their creation fails before
+// any account is created, returning all gas handed to it but the cost of the
+// permission check.
+func TestCreateDenial(t *testing.T) {
+	outsider := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	for _, forked := range []bool{false, true} {
+		veriteem := allAccessForks(true)
+		if !forked {
+			veriteem.CreateDenialBlock = nil
+		}
+		evm, guardian, member := newAccessTest(t, newAccessTestConfig(veriteem), 1, 0)
+
+		if contract := deployTestContract(t, evm, guardian, testReaderCode()); len(evm.StateDB.GetCode(contract)) == 0 {
+			t.Fatalf("forked %v: deployment by guardian failed", forked)
+		}
+		for _, creator := range []common.Address{member, outsider} {
+			var (
+				nonce   = evm.StateDB.GetNonce(creator)
+				address = crypto.CreateAddress(creator, nonce)
+			)
+			_, created, gas, err := evm.Create(AccountRef(creator), testReaderCode(), testGas, new(big.Int))
+			if len(evm.StateDB.GetCode(address)) != 0 {
+				t.Errorf("forked %v: contract deployed by %x", forked, creator)
+			}
+			if next := evm.StateDB.GetNonce(creator); next != nonce+1 {
+				t.Errorf("forked %v: nonce after denied creation %d, want %d", forked, next, nonce+1)
+			}
+			if !forked {
+				if err != nil || created != address {
+					t.Errorf("forked %v: creation by %x: have %x (%v), want %x", forked, creator, created, err, address)
+				}
+				continue
+			}
+			want := testGas - params.VeriteemCreateCheckGas
+			if err != ErrCreateDenied || created != (common.Address{}) || gas != want {
+				t.Errorf("forked %v: creation by %x: have %x, gas %d (%v), want none, gas %d (%v)", forked, creator, created, gas, err, want, ErrCreateDenied)
+			}
+			if evm.StateDB.Exist(address) {
+				t.Errorf("forked %v: account created for denied creation by %x", forked, creator)
+			}
+		}
+	}
+}
+
+// Tests that contracts can't deploy contracts with CREATE or CREATE2, as only
+// guardians may create contracts. Before the create denial fork they deploy an
+// empty contract instead.
+func TestContractCreateDenial(t *testing.T) {
+	for _, forked := range []bool{false, true} {
+		for _, create2 := range []bool{false, true} {
+			veriteem := allAccessForks(true)
+			if !forked {
+				veriteem.CreateDenialBlock = nil
+			}
+			config := newAccessTestConfig(veriteem)
+			config.ConstantinopleBlock = new(big.Int)
+			evm, guardian, member := newAccessTest(t, config, 1, 0)
+
+			factory := deployTestContract(t, evm, guardian, testFactoryCode(create2))
+			// The factory itself doesn't fail, as the failure of CREATE is
+			// returned to its code
+			if _, _, err := evm.Call(AccountRef(member), factory, nil, testGas, new(big.Int)); err != nil {
+				t.Fatalf("forked %v: call of factory (create2 %v) failed: %v", forked, create2, err)
+			}
+			created := common.BytesToAddress(evm.StateDB.GetState(factory, common.Hash{}).Bytes())
+			if forked && created != (common.Address{}) {
+				t.Errorf("forked %v: factory (create2 %v) created %x", forked, create2, created)
+			}
+			if !forked && created == (common.Address{}) {
+				t.Errorf("forked %v: factory (create2 %v) failed to create", forked, create2)
+			}
+			if created != (common.Address{}) && len(evm.StateDB.GetCode(created)) != 0 {
+				t.Errorf("forked %v: factory (create2 %v) deployed code at %x", forked, create2, created)
+			}
+		}
+	}
+}
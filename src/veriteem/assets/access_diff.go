@@ -0,0 +1,303 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Kinds of permission changes.
+const (
+	ChangeAdded    = "added"    // Guardian added, contributor created or contract registered
+	ChangeRemoved  = "removed"  // Guardian removed, contributor deleted or contract unregistered
+	ChangeModified = "modified" // Permissions of an existing entry changed
+)
+
+// AccessDiff lists the permission changes between two snapshots of the access
+// contract. Entries that only differ in data not affecting permissions, such
+// as names or the block of a contributor's last write, are left out.
+type AccessDiff struct {
+	Guardians    []GuardianChange `json:"guardians"`
+	Contributors []EntryChange    `json:"contributors"`
+	Contracts    []EntryChange    `json:"contracts"`
+}
+
+// Empty reports whether the snapshots hold the same permissions.
+func (d *AccessDiff) Empty() bool {
+	return len(d.Guardians) == 0 && len(d.Contributors) == 0 && len(d.Contracts) == 0
+}
+
+// GuardianChange is a guardian joining or leaving a guardianship.
+type GuardianChange struct {
+	Guardian     common.Address `json:"guardian"`
+	Guardianship hexutil.Uint64 `json:"guardianship"`
+	Change       string         `json:"change"`
+}
+
+// EntryChange is a change to the entry of a contributor or a contract.
+type EntryChange struct {
+	Address      common.Address `json:"address"`
+	Guardianship hexutil.Uint64 `json:"guardianship"` // Owning guardianship, before removals
+	Change       string         `json:"change"`
+	Fields       []FieldChange  `json:"fields,omitempty"` // Changed fields of modified entries
+}
+
+// FieldChange is a changed field of an entry. Function groups are reported as
+// one field per function, named after its selector, whose value is nil if the
+// function isn't restricted.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// DiffAccessSnapshots compares the permission tables of two snapshots. Changes
+// are ordered by address, guardians by guardianship too.
+func DiffAccessSnapshots(from, to *AccessSnapshot) *AccessDiff {
+	return &AccessDiff{
+		Guardians:    diffGuardians(from, to),
+		Contributors: diffContributors(from, to),
+		Contracts:    diffContracts(from, to),
+	}
+}
+
+// diffGuardians lists the guardians whose membership of a guardianship changed.
+func diffGuardians(from, to *AccessSnapshot) []GuardianChange {
+	type membership struct {
+		guardian     common.Address
+		guardianship hexutil.Uint64
+	}
+	members := func(snapshot *AccessSnapshot) map[membership]bool {
+		set := make(map[membership]bool)
+		for _, g := range snapshot.Guardianships {
+			for _, guardian := range g.Guardians {
+				if guardian != (common.Address{}) {
+					set[membership{guardian, g.Index}] = true
+				}
+			}
+		}
+		return set
+	}
+	before, after := members(from), members(to)
+
+	changes := []GuardianChange{}
+	for m := range before {
+		if !after[m] {
+			changes = append(changes, GuardianChange{Guardian: m.guardian, Guardianship: m.guardianship, Change: ChangeRemoved})
+		}
+	}
+	for m := range after {
+		if !before[m] {
+			changes = append(changes, GuardianChange{Guardian: m.guardian, Guardianship: m.guardianship, Change: ChangeAdded})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Guardianship != changes[j].Guardianship {
+			return changes[i].Guardianship < changes[j].Guardianship
+		}
+		return bytes.Compare(changes[i].Guardian[:], changes[j].Guardian[:]) < 0
+	})
+	return changes
+}
+
+// diffContributors lists the contributors created, deleted or whose permissions
+// changed. Contributors exist while they belong to a guardianship.
+func diffContributors(from, to *AccessSnapshot) []EntryChange {
+	before := make(map[common.Address]ContributorSnapshot)
+	for _, c := range from.Contributors {
+		if c.Guardianship != 0 {
+			before[c.Address] = c
+		}
+	}
+	after := make(map[common.Address]ContributorSnapshot)
+	for _, c := range to.Contributors {
+		if c.Guardianship != 0 {
+			after[c.Address] = c
+		}
+	}
+	addresses := make(map[common.Address]bool)
+	for address := range before {
+		addresses[address] = true
+	}
+	for address := range after {
+		addresses[address] = true
+	}
+	changes := []EntryChange{}
+	for _, address := range sortedAddresses(addresses) {
+		old, existed := before[address]
+		cur, exists := after[address]
+		switch {
+		case !existed:
+			changes = append(changes, EntryChange{Address: address, Guardianship: cur.Guardianship, Change: ChangeAdded})
+		case !exists:
+			changes = append(changes, EntryChange{Address: address, Guardianship: old.Guardianship, Change: ChangeRemoved})
+		default:
+			var fields []FieldChange
+			if old.Guardianship != cur.Guardianship {
+				fields = append(fields, FieldChange{"guardianship", old.Guardianship, cur.Guardianship})
+			}
+			if old.Limit.ToInt().Cmp(cur.Limit.ToInt()) != 0 {
+				fields = append(fields, FieldChange{"limit", old.Limit, cur.Limit})
+			}
+			if old.AccessGroup.ToInt().Cmp(cur.AccessGroup.ToInt()) != 0 {
+				fields = append(fields, FieldChange{"accessGroup", old.AccessGroup, cur.AccessGroup})
+			}
+			if len(fields) > 0 {
+				changes = append(changes, EntryChange{Address: address, Guardianship: old.Guardianship, Change: ChangeModified, Fields: fields})
+			}
+		}
+	}
+	return changes
+}
+
+// diffContracts lists the contracts registered, unregistered or whose
+// permissions changed. Contracts are registered while a guardianship owns them.
+func diffContracts(from, to *AccessSnapshot) []EntryChange {
+	before := make(map[common.Address]ContractSnapshot)
+	for _, c := range from.Contracts {
+		if c.Guardianship != 0 {
+			before[c.Address] = c
+		}
+	}
+	after := make(map[common.Address]ContractSnapshot)
+	for _, c := range to.Contracts {
+		if c.Guardianship != 0 {
+			after[c.Address] = c
+		}
+	}
+	addresses := make(map[common.Address]bool)
+	for address := range before {
+		addresses[address] = true
+	}
+	for address := range after {
+		addresses[address] = true
+	}
+	changes := []EntryChange{}
+	for _, address := range sortedAddresses(addresses) {
+		old, existed := before[address]
+		cur, exists := after[address]
+		switch {
+		case !existed:
+			changes = append(changes, EntryChange{Address: address, Guardianship: cur.Guardianship, Change: ChangeAdded})
+		case !exists:
+			changes = append(changes, EntryChange{Address: address, Guardianship: old.Guardianship, Change: ChangeRemoved})
+		default:
+			if fields := diffContract(&old, &cur); len(fields) > 0 {
+				changes = append(changes, EntryChange{Address: address, Guardianship: old.Guardianship, Change: ChangeModified, Fields: fields})
+			}
+		}
+	}
+	return changes
+}
+
+// diffContract lists the changed permission fields of a contract.
+func diffContract(old, cur *ContractSnapshot) []FieldChange {
+	var fields []FieldChange
+	if old.Guardianship != cur.Guardianship {
+		fields = append(fields, FieldChange{"guardianship", old.Guardianship, cur.Guardianship})
+	}
+	if old.WriteValid != cur.WriteValid {
+		fields = append(fields, FieldChange{"writeValid", old.WriteValid, cur.WriteValid})
+	}
+	if old.GlobalReadValid != cur.GlobalReadValid {
+		fields = append(fields, FieldChange{"globalReadValid", old.GlobalReadValid, cur.GlobalReadValid})
+	}
+	if old.StateCode != cur.StateCode {
+		fields = append(fields, FieldChange{"state", old.State, cur.State})
+	}
+	if old.NewAddress != cur.NewAddress {
+		fields = append(fields, FieldChange{"newAddress", old.NewAddress, cur.NewAddress})
+	}
+	if !sameGuardianships(old.SubGuardianships, cur.SubGuardianships) {
+		fields = append(fields, FieldChange{"subGuardianships", old.SubGuardianships, cur.SubGuardianships})
+	}
+	// Only the first entry of a function in the FunctionList applies
+	groups := func(c *ContractSnapshot) map[string]*hexutil.Big {
+		set := make(map[string]*hexutil.Big)
+		for _, f := range c.Functions {
+			if _, ok := set[f.Selector.String()]; !ok {
+				set[f.Selector.String()] = f.AccessGroup
+			}
+		}
+		return set
+	}
+	before, after := groups(old), groups(cur)
+
+	var selectors []string
+	for selector := range before {
+		selectors = append(selectors, selector)
+	}
+	for selector := range after {
+		if _, ok := before[selector]; !ok {
+			selectors = append(selectors, selector)
+		}
+	}
+	sort.Strings(selectors)
+	for _, selector := range selectors {
+		oldGroup, newGroup := before[selector], after[selector]
+		switch {
+		case oldGroup == nil && newGroup == nil:
+		case oldGroup == nil || newGroup == nil || oldGroup.ToInt().Cmp(newGroup.ToInt()) != 0:
+			fields = append(fields, FieldChange{fmt.Sprintf("function %s", selector), nilIfUnset(oldGroup), nilIfUnset(newGroup)})
+		}
+	}
+	return fields
+}
+
+// sameGuardianships reports whether two SubGuardianshipLists grant the same
+// guardianships, regardless of their order.
+func sameGuardianships(a, b []hexutil.Uint64) bool {
+	set := make(map[hexutil.Uint64]int)
+	for _, index := range a {
+		set[index] |= 1
+	}
+	for _, index := range b {
+		set[index] |= 2
+	}
+	for _, in := range set {
+		if in != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// nilIfUnset turns a missing access group into an untyped nil, so it encodes
+// as null.
+func nilIfUnset(group *hexutil.Big) interface{} {
+	if group == nil {
+		return nil
+	}
+	return group
+}
+
+// sortedAddresses returns the addresses of a set in ascending order.
+func sortedAddresses(set map[common.Address]bool) []common.Address {
+	addresses := make([]common.Address, 0, len(set))
+	for address := range set {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+	return addresses
+}
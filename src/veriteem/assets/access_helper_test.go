@@ -0,0 +1,333 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testAccessContract is the address the Veriteem genesis installs the
+// AccessRights contract at.
+var testAccessContract = common.HexToAddress("0x0000000000000000000000000000000000000100")
+
+// testGas is the gas the tests call and create contracts with.
+const testGas uint64 = 4000000
+
+// accessRightsCode returns the runtime code of the AccessRights contract from
+// the alloc of the Veriteem genesis.
+func accessRightsCode(t testing.TB) []byte {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "genesis.json"))
+	if err != nil {
+		t.Fatalf("failed to read genesis: %v", err)
+	}
+	var genesis struct {
+		Alloc map[string]struct {
+			Code hexutil.Bytes `json:"code"`
+		} `json:"alloc"`
+	}
+	if err := json.Unmarshal(data, &genesis); err != nil {
+		t.Fatalf("failed to parse genesis: %v", err)
+	}
+	for addr, account := range genesis.Alloc {
+		if common.HexToAddress(addr) == testAccessContract && len(account.Code) > 0 {
+			return account.Code
+		}
+	}
+	t.Fatalf("genesis has no code at %x", testAccessContract)
+	return nil
+}
+
+// newAccessTestState returns an empty state holding the AccessRights contract.
+func newAccessTestState(t testing.TB) *state.StateDB {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	if err != nil {
+		t.Fatalf("failed to create state: %v", err)
+	}
+	statedb.SetCode(testAccessContract, accessRightsCode(t))
+	return statedb
+}
+
+// newAccessTestConfig returns a chain config with every protocol change, under
+// the access control of veriteem.
+func newAccessTestConfig(veriteem *params.VeriteemConfig) *params.ChainConfig {
+	config := *params.AllEthashProtocolChanges
+	config.Veriteem = veriteem
+	return &config
+}
+
+// allAccessForks returns an access control config with every fork active from
+// genesis on.
+func allAccessForks(native bool) *params.VeriteemConfig {
+	return &params.VeriteemConfig{
+		AccessContract:    testAccessContract,
+		Native:            native,
+		CallKindsBlock:    new(big.Int),
+		DenialErrorsBlock: new(big.Int),
+		CreateDenialBlock: new(big.Int),
+		StaticReadBlock:   new(big.Int),
+		RateLimitBlock:    new(big.Int),
+		AccessGasBlock:    new(big.Int),
+		MigrationBlock:    new(big.Int),
+		LifecycleBlock:    new(big.Int),
+		SelectorBlock:     new(big.Int),
+	}
+}
+
+// newAccessTestEVM returns an EVM running block number on statedb.
+func newAccessTestEVM(statedb StateDB, config *params.ChainConfig, number uint64, vmConfig Config) *EVM {
+	ctx := Context{
+		CanTransfer: func(db StateDB, addr common.Address, amount *big.Int) bool {
+			return db.GetBalance(addr).Cmp(amount) >= 0
+		},
+		Transfer: func(db StateDB, sender, recipient common.Address, amount *big.Int) {
+			db.SubBalance(sender, amount)
+			db.AddBalance(recipient, amount)
+		},
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		GasPrice:    new(big.Int),
+		GasLimit:    8000000,
+		BlockNumber: new(big.Int).SetUint64(number),
+		Time:        new(big.Int),
+		Difficulty:  new(big.Int),
+	}
+	return NewEVM(ctx, statedb, config, vmConfig)
+}
+
+// newAccessTest returns an EVM running block number on a fresh state, where
+// guardian is the guardian of the first guardianship and member one of its
+// contributors, writing at most once every limit blocks.
+func newAccessTest(t testing.TB, config *params.ChainConfig, number, limit uint64) (evm *EVM, guardian, member common.Address) {
+	guardian = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	member = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+
+	evm = newAccessTestEVM(newAccessTestState(t), config, number, Config{})
+	adminAccess(t, evm, guardian, "InitGuardianship()")
+	adminAccess(t, evm, guardian, "CreateContributor(address,string,uint256,uint256)", member, "member", limit, uint64(0))
+	return evm, guardian, member
+}
+
+// adminAccess calls the method of the access contract with the given signature
+// on behalf of from. The AccessRights contract ignores unauthorized calls
+// instead of failing them, so success doesn't mean the tables changed.
+func adminAccess(t testing.TB, evm *EVM, from common.Address, signature string, args ...interface{}) []byte {
+	output, _, err := evm.Call(AccountRef(from), testAccessContract, packAccessMethod(signature, args...), testGas, new(big.Int))
+	if err != nil {
+		t.Fatalf("%s by %x failed: %v", signature, from, err)
+	}
+	return output
+}
+
+// packAccessMethod returns the input calling the method with the given signature
+// on args, which may be addresses, bools, uint64s and strings.
+func packAccessMethod(signature string, args ...interface{}) []byte {
+	var head, tail []byte
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case common.Address:
+			head = append(head, arg.Hash().Bytes()...)
+		case bool:
+			var word uint64
+			if arg {
+				word = 1
+			}
+			head = append(head, uint64Hash(word).Bytes()...)
+		case uint64:
+			head = append(head, uint64Hash(arg).Bytes()...)
+		case string:
+			head = append(head, uint64Hash(uint64(32*len(args)+len(tail))).Bytes()...)
+			tail = append(tail, uint64Hash(uint64(len(arg))).Bytes()...)
+			tail = append(tail, common.RightPadBytes([]byte(arg), (len(arg)+31)/32*32)...)
+		default:
+			panic("unsupported argument type")
+		}
+	}
+	input := append(crypto.Keccak256([]byte(signature))[:4], head...)
+	return append(input, tail...)
+}
+
+// writeContractInfo sets the flags of a contract owned by the guardianship of
+// guardian, keeping it active.
+func writeContractInfo(t testing.TB, evm *EVM, guardian, contract common.Address, writeValid, globalReadValid bool) {
+	adminAccess(t, evm, guardian, "WriteContractInfo(address,string,address,bool,bool,uint256)",
+		contract, "contract", common.Address{}, writeValid, globalReadValid, uint64(1))
+}
+
+// deployTestContract runs creation code as from and returns the address of the
+// contract, which the access contract assigns to the guardianship of from.
+func deployTestContract(t testing.TB, evm *EVM, from common.Address, code []byte) common.Address {
+	_, contract, _, err := evm.Create(AccountRef(from), code, testGas, new(big.Int))
+	if err != nil {
+		t.Fatalf("creation by %x failed: %v", from, err)
+	}
+	return contract
+}
+
+// testCounterCode returns the creation code of a contract counting the calls
+// made to it in storage slot zero.
+func testCounterCode() []byte {
+	// PUSH1 0 SLOAD PUSH1 1 ADD PUSH1 0 SSTORE STOP
+	return testDeployCode([]byte{0x60, 0x00, 0x54, 0x60, 0x01, 0x01, 0x60, 0x00, 0x55, 0x00})
+}
+
+// testReaderCode returns the creation code of a contract returning the word 1
+// to any call, without touching the state.
+func testReaderCode() []byte {
+	// PUSH1 1 PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	return testDeployCode([]byte{0x60, 0x01, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3})
+}
+
+// testProxyCode returns the creation code of a contract forwarding its calldata
+// to target with op, one of CALL, CALLCODE, DELEGATECALL and STATICCALL, and
+// failing if the forwarded call does.
+func testProxyCode(op OpCode, target common.Address) []byte {
+	// CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY PUSH1 0 PUSH1 0 CALLDATASIZE PUSH1 0
+	code := []byte{0x36, 0x60, 0x00, 0x60, 0x00, 0x37, 0x60, 0x00, 0x60, 0x00, 0x36, 0x60, 0x00}
+	if op == CALL || op == CALLCODE {
+		// PUSH1 0: no value
+		code = append(code, 0x60, 0x00)
+	}
+	// PUSH20 target GAS op
+	code = append(code, 0x73)
+	code = append(code, target.Bytes()...)
+	code = append(code, 0x5a, byte(op))
+
+	// PUSH1 ok JUMPI PUSH1 0 DUP1 REVERT, ok: JUMPDEST STOP
+	code = append(code, 0x60, byte(len(code)+7), 0x57, 0x60, 0x00, 0x80, 0xfd, 0x5b, 0x00)
+	return testDeployCode(code)
+}
+
+// testReturnCode returns runtime code returning words.
+func testReturnCode(words ...common.Hash) []byte {
+	var code []byte
+	for i, word := range words {
+		// PUSH32 word PUSH1 offset MSTORE
+		code = append(code, 0x7f)
+		code = append(code, word.Bytes()...)
+		code = append(code, 0x60, byte(32*i), 0x52)
+	}
+	// PUSH1 size PUSH1 0 RETURN
+	return append(code, 0x60, byte(32*len(words)), 0x60, 0x00, 0xf3)
+}
+
+// testDeployCode returns creation code deploying runtime code of up to 255
+// bytes.
+func testDeployCode(code []byte) []byte {
+	// PUSH1 len DUP1 PUSH1 11 PUSH1 0 CODECOPY PUSH1 0 RETURN
+	return append([]byte{0x60, byte(len(code)), 0x80, 0x60, 0x0b, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}, code...)
+}
+
+// testContractEntry is an entry of the AccessRights ContractTable.
+type testContractEntry struct {
+	Guardianship     uint64
+	NewAddress       common.Address
+	WriteValid       bool
+	GlobalReadValid  bool
+	State            ContractState
+	SubGuardianships []uint64
+	Functions        []testFunction
+}
+
+// testFunction is an entry of the FunctionList of a contract.
+type testFunction struct {
+	Selector    [4]byte
+	AccessGroup uint64
+}
+
+// testContributorEntry is an entry of the AccessRights ContributorTable.
+type testContributorEntry struct {
+	Guardianship    uint64
+	Limit           uint64
+	AccessGroup     uint64
+	LastBlockNumber uint64
+}
+
+// accessTables writes the tables of an access contract straight into its
+// storage, following the layout of scripts/AccessRights.sol.
+type accessTables struct {
+	statedb  StateDB
+	contract common.Address
+}
+
+// setGuardians sets the GuardianList of the guardianship at index.
+func (tables accessTables) setGuardians(index uint64, first, second common.Address) {
+	base := guardianshipTableSlot + index*guardianshipStructSize + guardianshipGuardianListOffset
+	tables.set(common.BigToHash(new(big.Int).SetUint64(base)), first.Hash())
+	tables.set(common.BigToHash(new(big.Int).SetUint64(base+1)), second.Hash())
+}
+
+// setContract sets the ContractTable entry of contract.
+func (tables accessTables) setContract(contract common.Address, entry testContractEntry) {
+	base := mappingSlot(contract, contractTableSlot)
+
+	var flags common.Hash
+	copy(flags[common.HashLength-common.AddressLength:], entry.NewAddress.Bytes())
+	if entry.WriteValid {
+		flags[common.HashLength-1-contractWriteValidByte] = 1
+	}
+	if entry.GlobalReadValid {
+		flags[common.HashLength-1-contractGlobalReadValidByte] = 1
+	}
+	tables.set(slotAt(base, contractGuardianshipOffset), uint64Hash(entry.Guardianship))
+	tables.set(slotAt(base, contractFlagsOffset), flags)
+	tables.set(slotAt(base, contractStateOffset), uint64Hash(uint64(entry.State)))
+	for i := uint64(0); i < maxGuardianship; i++ {
+		var sub uint64
+		if i < uint64(len(entry.SubGuardianships)) {
+			sub = entry.SubGuardianships[i]
+		}
+		tables.set(slotAt(base, contractSubGuardianshipOffset+i), uint64Hash(sub))
+	}
+	for i := uint64(0); i < maxFuncList; i++ {
+		var function testFunction
+		if i < uint64(len(entry.Functions)) {
+			function = entry.Functions[i]
+		}
+		tables.set(slotAt(base, contractFunctionListOffset+i), FunctionAddress(function.Selector).Hash())
+		tables.set(slotAt(base, contractAccessGroupOffset+i), uint64Hash(function.AccessGroup))
+	}
+}
+
+// setContributor sets the ContributorTable entry of contributor.
+func (tables accessTables) setContributor(contributor common.Address, entry testContributorEntry) {
+	base := mappingSlot(contributor, contributorTableSlot)
+
+	tables.set(slotAt(base, contributorGuardianshipOffset), uint64Hash(entry.Guardianship))
+	tables.set(slotAt(base, contributorLimitOffset), uint64Hash(entry.Limit))
+	tables.set(slotAt(base, contributorAccessGroupOffset), uint64Hash(entry.AccessGroup))
+	tables.set(slotAt(base, contributorLastBlockNumberOffset), uint64Hash(entry.LastBlockNumber))
+}
+
+// set writes a storage slot of the access contract.
+func (tables accessTables) set(slot, value common.Hash) {
+	tables.statedb.SetState(tables.contract, slot, value)
+}
+
+// uint64Hash returns n as a storage word.
+func uint64Hash(n uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(n))
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that from the rate limit fork on, an allowed write records the block
+// as the contributor's last write, so that it has to wait Limit blocks before
+// writing again. Before the fork the contributor is never rate limited.
+func TestRateLimit(t *testing.T) {
+	for _, native := range []bool{false, true} {
+		for _, forked := range []bool{false, true} {
+			veriteem := allAccessForks(native)
+			if !forked {
+				veriteem.RateLimitBlock = nil
+			}
+			config := newAccessTestConfig(veriteem)
+
+			// Creating the contributor counts as its first write
+			evm, guardian, member := newAccessTest(t, config, 1, 5)
+			counter := deployTestContract(t, evm, guardian, testCounterCode())
+
+			write := func(number uint64) error {
+				evm := newAccessTestEVM(evm.StateDB, config, number, Config{})
+				_, _, err := evm.Call(AccountRef(member), counter, nil, testGas, new(big.Int))
+				return err
+			}
+			if err := write(10); err != nil {
+				t.Fatalf("native %v forked %v: first write failed: %v", native, forked, err)
+			}
+			last := evm.StateDB.GetState(testAccessContract, slotAt(mappingSlot(member, contributorTableSlot), contributorLastBlockNumberOffset))
+			want := uint64Hash(1)
+			if forked {
+				want = uint64Hash(10)
+			}
+			if last != want {
+				t.Errorf("native %v forked %v: last write block %x, want %x", native, forked, last, want)
+			}
+			err := write(14)
+			switch {
+			case !forked && err != nil:
+				t.Errorf("native %v forked %v: second write failed: %v", native, forked, err)
+			case forked && err == nil:
+				t.Errorf("native %v forked %v: second write within limit succeeded", native, forked)
+			case forked && native && err != ErrRateLimited:
+				t.Errorf("native %v forked %v: second write: have %v, want %v", native, forked, err, ErrRateLimited)
+			}
+			if err := write(15); err != nil {
+				t.Errorf("native %v forked %v: write after limit failed: %v", native, forked, err)
+			}
+			count := uint64(2)
+			if !forked {
+				count = 3
+			}
+			if have := evm.StateDB.GetState(counter, common.Hash{}); have != uint64Hash(count) {
+				t.Errorf("native %v forked %v: count %x, want %d", native, forked, have, count)
+			}
+		}
+	}
+}
+
+// Tests that only the writes of contributors are recorded, which guardians and
+// contracts calling each other aren't, and that nothing is recorded within a
+// static call, where the access contract couldn't store anything.
+func TestRateLimitCallers(t *testing.T) {
+	for _, native := range []bool{false, true} {
+		config := newAccessTestConfig(allAccessForks(native))
+		evm, guardian, member := newAccessTest(t, config, 1, 5)
+
+		counter := deployTestContract(t, evm, guardian, testCounterCode())
+		reader := deployTestContract(t, evm, guardian, testReaderCode())
+		inner := deployTestContract(t, evm, guardian, testProxyCode(CALL, reader))
+		writeContractInfo(t, evm, guardian, inner, true, true)
+		outer := deployTestContract(t, evm, guardian, testProxyCode(STATICCALL, inner))
+		adminAccess(t, evm, guardian, "CreateContributor(address,string,uint256,uint256)", inner, "inner", uint64(5), uint64(0))
+
+		lastWrite := func(caller common.Address) common.Hash {
+			return evm.StateDB.GetState(testAccessContract, slotAt(mappingSlot(caller, contributorTableSlot), contributorLastBlockNumberOffset))
+		}
+		evm = newAccessTestEVM(evm.StateDB, config, 10, Config{})
+		if _, _, err := evm.Call(AccountRef(guardian), counter, nil, testGas, new(big.Int)); err != nil {
+			t.Fatalf("native %v: write by guardian failed: %v", native, err)
+		}
+		if last := lastWrite(guardian); last != (common.Hash{}) {
+			t.Errorf("native %v: write of guardian recorded in block %x", native, last)
+		}
+		// The proxy making the static call isn't a contributor, the one it
+		// calls is, but may not record anything
+		if _, _, err := evm.Call(AccountRef(member), outer, nil, testGas, new(big.Int)); err != nil {
+			t.Fatalf("native %v: static call through proxies failed: %v", native, err)
+		}
+		if last := lastWrite(member); last != uint64Hash(10) {
+			t.Errorf("native %v: last write block of member %x, want 10", native, last)
+		}
+		if last := lastWrite(inner); last != uint64Hash(1) {
+			t.Errorf("native %v: last write block of proxy %x, want 1", native, last)
+		}
+	}
+}
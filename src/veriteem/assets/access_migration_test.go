@@ -0,0 +1,89 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that from the migration fork on, calls to a contract migrated to a new
+// address are rejected with an error naming the new address, or forwarded to
+// it if the chain is configured to. Before the fork the migration is ignored.
+func TestMigration(t *testing.T) {
+	tests := []struct {
+		name      string
+		native    bool
+		forked    bool
+		forward   bool
+		forwarded bool
+		rejected  bool
+	}{
+		{"legacy", false, false, false, false, false},
+		{"rejected", false, true, false, false, true},
+		{"forwarded", false, true, true, true, false},
+		{"native/rejected", true, true, false, false, true},
+		{"native/forwarded", true, true, true, true, false},
+	}
+	for _, tt := range tests {
+		veriteem := allAccessForks(tt.native)
+		if !tt.forked {
+			veriteem.MigrationBlock = nil
+		}
+		veriteem.ForwardMigrated = tt.forward
+		config := newAccessTestConfig(veriteem)
+		evm, guardian, member := newAccessTest(t, config, 1, 0)
+
+		old := deployTestContract(t, evm, guardian, testCounterCode())
+		current := deployTestContract(t, evm, guardian, testCounterCode())
+		adminAccess(t, evm, guardian, "WriteContractInfo(address,string,address,bool,bool,uint256)",
+			old, "old", current, true, true, uint64(ContractStateMigrated))
+
+		output, _, err := evm.Call(AccountRef(member), old, nil, testGas, new(big.Int))
+		if tt.rejected {
+			want := &ContractMigratedError{Contract: old, NewAddress: current}
+			if merr, ok := err.(*ContractMigratedError); !ok || *merr != *want {
+				t.Errorf("%s: call of migrated contract: have %v, want %v", tt.name, err, want)
+			}
+			if !bytes.Equal(output, packRevertReason(want)) {
+				t.Errorf("%s: rejection output %x doesn't carry the reason", tt.name, output)
+			}
+			to := old
+			if err := CheckTransactionAccess(evm.StateDB, config, evm.BlockNumber, member, &to, nil); err == nil {
+				t.Errorf("%s: transaction to migrated contract passed the access check", tt.name)
+			}
+		} else if err != nil {
+			t.Errorf("%s: call of migrated contract failed: %v", tt.name, err)
+		}
+		var wantOld, wantCurrent uint64
+		switch {
+		case tt.forwarded:
+			wantCurrent = 1
+		case !tt.rejected:
+			wantOld = 1
+		}
+		if have := evm.StateDB.GetState(old, common.Hash{}); have != uint64Hash(wantOld) {
+			t.Errorf("%s: counter of migrated contract %x, want %d", tt.name, have, wantOld)
+		}
+		if have := evm.StateDB.GetState(current, common.Hash{}); have != uint64Hash(wantCurrent) {
+			t.Errorf("%s: counter of new contract %x, want %d", tt.name, have, wantCurrent)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Storage layout of the AccessRights contract (scripts/AccessRights.sol).
+const (
+	maxGuardianship = 20 // MAX_GUARDIANSHIP
+	maxFuncList     = 50 // MAX_FUNC_LIST
+
+	contractTableSlot     = 0   // mapping (address => ContractStruct) ContractTable
+	guardianshipTableSlot = 1   // GuardianshipStruct [MAX_GUARDIANSHIP] GuardianshipTable
+	contributorTableSlot  = 141 // mapping (address => ContributorStruct) ContributorTable
+
+	// ContractStruct member offsets
+	contractNameOffset            = 0
+	contractGuardianshipOffset    = 1
+	contractFlagsOffset           = 2 // NewAddress, WriteValid and GlobalReadValid share a slot
+	contractStateOffset           = 3
+	contractSubGuardianshipOffset = 4
+	contractFunctionListOffset    = contractSubGuardianshipOffset + maxGuardianship
+	contractAccessGroupOffset     = contractFunctionListOffset + maxFuncList
+
+	// Byte positions, counted from the low order end, of the bools packed
+	// after NewAddress
+	contractWriteValidByte      = 20
+	contractGlobalReadValidByte = 21
+
+	// GuardianshipStruct member offsets and size
+	guardianshipNameOffset            = 0
+	guardianshipGuardianListOffset    = 1
+	guardianshipContractListOffset    = 3
+	guardianshipContributorListOffset = 4
+	guardianshipAddVoteOffset         = 5
+	guardianshipRemoveVoteOffset      = 6
+	guardianshipStructSize            = 7
+
+	// ContributorStruct member offsets
+	contributorNameOffset            = 0
+	contributorGuardianshipOffset    = 1
+	contributorLimitOffset           = 2
+	contributorAccessGroupOffset     = 3
+	contributorLastBlockNumberOffset = 4
+)
+
+// errAccessInvalidGuardianship is returned if a table references a guardianship
+// outside GuardianshipTable, which makes the contract code throw.
+var errAccessInvalidGuardianship = errors.New("access contract references invalid guardianship")
+
+// tt256 is 2**256, the modulus of storage slot and uint arithmetic.
+var tt256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// nativeAccessPolicy is an AccessPolicy that evaluates the decisions of the
+// AccessRights contract by reading its storage directly instead of running its
+// code. Writes, such as registering contracts, are left to the contract.
+type nativeAccessPolicy struct {
+	*contractAccessPolicy
+}
+
+// newNativeAccessPolicy returns an AccessPolicy that answers permission checks
+// from the storage of the access contract configured by access.
+func newNativeAccessPolicy(evm *EVM, access params.VeriteemAccess) *nativeAccessPolicy {
+	return &nativeAccessPolicy{
+		contractAccessPolicy: newContractAccessPolicy(evm, access),
+	}
+}
+
+// CanCreate implements AccessPolicy, mirroring GuardianshipIndex(caller).Match.
+func (p *nativeAccessPolicy) CanCreate(caller common.Address) (bool, error) {
+	_, match := p.guardianshipIndex(caller)
+	return match, nil
+}
+
+// CheckAccess implements AccessPolicy, mirroring VerifyContractAccess. Unlike
+// the contract, it also reports why access was restricted.
+func (p *nativeAccessPolicy) CheckAccess(caller, contract common.Address, selector [4]byte) (AccessDecision, error) {
+	var (
+		contractBase    = mappingSlot(contract, contractTableSlot)
+		contributorBase = mappingSlot(caller, contributorTableSlot)
+	)
+	// Block all access to unowned contracts
+	guardianship := p.load(slotAt(contractBase, contractGuardianshipOffset)).Big()
+	owned, err := p.guardianshipOwned(guardianship)
+	if err != nil {
+		return AccessDecision{}, err
+	}
+	if !owned {
+		return AccessDecision{Reason: AccessUnownedContract}, nil
+	}
+	// Block all access if the function is restricted to other access groups
+	if group, match := p.functionAccessGroup(contractBase, FunctionAddress(selector)); match {
+		contributorGroup := p.load(slotAt(contributorBase, contributorAccessGroupOffset)).Big()
+		if new(big.Int).And(group, contributorGroup).Sign() == 0 {
+			return AccessDecision{Reason: AccessGroupMismatch}, nil
+		}
+	}
+	flags := p.load(slotAt(contractBase, contractFlagsOffset))
+	decision := AccessDecision{
+		Read: flags[common.HashLength-1-contractGlobalReadValidByte] != 0,
+	}
+	// Contributors of the owning guardianship or a sub guardianship may write,
+	// subject to their rate limit
+	contributorGuardianship := p.load(slotAt(contributorBase, contributorGuardianshipOffset)).Big()
+	member, err := p.subGuardianshipMember(contractBase, guardianship, contributorGuardianship)
+	if err != nil {
+		return AccessDecision{}, err
+	}
+	if !member {
+		decision.Reason = AccessNoGuardianship
+		return decision, nil
+	}
+	if flags[common.HashLength-1-contractWriteValidByte] == 0 {
+		decision.Reason = AccessWriteDisabled
+		return decision, nil
+	}
+	limit := p.load(slotAt(contributorBase, contributorLimitOffset)).Big()
+	if limit.Sign() > 0 {
+		next := p.load(slotAt(contributorBase, contributorLastBlockNumberOffset)).Big()
+		next.Add(next, limit).Mod(next, tt256)
+		if next.Cmp(p.evm.BlockNumber) > 0 {
+			decision.Reason = AccessRateLimited
+			return decision, nil
+		}
+	}
+	decision.Write = true
+	if !decision.Read {
+		decision.Reason = AccessReadDenied
+	}
+	return decision, nil
+}
+
+// RecordWrite implements AccessPolicy, mirroring UpdateContributorBlock. The
+// EVM doesn't record writes within static calls, where the contract couldn't
+// store anything, so the storage is written directly, leaving the same state as
+// the contract would.
+func (p *nativeAccessPolicy) RecordWrite(caller common.Address) error {
+	slot := slotAt(mappingSlot(caller, contributorTableSlot), contributorLastBlockNumberOffset)
+	p.evm.StateDB.SetState(p.access.Contract, slot, common.BigToHash(p.evm.BlockNumber))
+	return nil
+}
+
+// ContractStatus implements AccessPolicy, mirroring ReadContractInfo.
+func (p *nativeAccessPolicy) ContractStatus(contract common.Address) (ContractStatus, error) {
+	contractBase := mappingSlot(contract, contractTableSlot)
+
+	flags := p.load(slotAt(contractBase, contractFlagsOffset))
+	return ContractStatus{
+		State:      newContractState(p.load(slotAt(contractBase, contractStateOffset)).Bytes()),
+		NewAddress: common.BytesToAddress(flags[common.HashLength-common.AddressLength:]),
+	}, nil
+}
+
+// ContributorAccessGroup implements AccessPolicy, mirroring ReadContributor.
+func (p *nativeAccessPolicy) ContributorAccessGroup(caller common.Address) (*big.Int, error) {
+	return p.load(slotAt(mappingSlot(caller, contributorTableSlot), contributorAccessGroupOffset)).Big(), nil
+}
+
+// IsContributor implements AccessPolicy, mirroring the Guardianship returned
+// by ReadContributor.
+func (p *nativeAccessPolicy) IsContributor(caller common.Address) (bool, error) {
+	return p.load(slotAt(mappingSlot(caller, contributorTableSlot), contributorGuardianshipOffset)) != (common.Hash{}), nil
+}
+
+// AccessDetails implements AccessPolicy, reading the table entries
+// VerifyContractAccess consults.
+func (p *nativeAccessPolicy) AccessDetails(caller, contract common.Address, selector [4]byte) (*AccessDetails, error) {
+	var (
+		contractBase    = mappingSlot(contract, contractTableSlot)
+		contributorBase = mappingSlot(caller, contributorTableSlot)
+	)
+	details := &AccessDetails{
+		Guardianship:            (*hexutil.Big)(p.load(slotAt(contractBase, contractGuardianshipOffset)).Big()),
+		ContributorGuardianship: (*hexutil.Big)(p.load(slotAt(contributorBase, contributorGuardianshipOffset)).Big()),
+		AccessGroup:             (*hexutil.Big)(p.load(slotAt(contributorBase, contributorAccessGroupOffset)).Big()),
+		Limit:                   (*hexutil.Big)(p.load(slotAt(contributorBase, contributorLimitOffset)).Big()),
+		LastBlockNumber:         (*hexutil.Big)(p.load(slotAt(contributorBase, contributorLastBlockNumberOffset)).Big()),
+	}
+	if group, match := p.functionAccessGroup(contractBase, FunctionAddress(selector)); match {
+		details.FunctionAccessGroup = (*hexutil.Big)(group)
+	}
+	return details, nil
+}
+
+// CheckRead implements AccessPolicy. Reads only depend on the contract, never
+// on the guardianship or rate limit of the caller, so those aren't looked up.
+// The caller's access group is only read if the function is restricted.
+func (p *nativeAccessPolicy) CheckRead(caller, contract common.Address, selector [4]byte) (AccessDecision, error) {
+	contractBase := mappingSlot(contract, contractTableSlot)
+
+	guardianship := p.load(slotAt(contractBase, contractGuardianshipOffset)).Big()
+	owned, err := p.guardianshipOwned(guardianship)
+	if err != nil {
+		return AccessDecision{}, err
+	}
+	if !owned {
+		return AccessDecision{Reason: AccessUnownedContract}, nil
+	}
+	if group, match := p.functionAccessGroup(contractBase, FunctionAddress(selector)); match {
+		contributorGroup := p.load(slotAt(mappingSlot(caller, contributorTableSlot), contributorAccessGroupOffset)).Big()
+		if new(big.Int).And(group, contributorGroup).Sign() == 0 {
+			return AccessDecision{Reason: AccessGroupMismatch}, nil
+		}
+	}
+	flags := p.load(slotAt(contractBase, contractFlagsOffset))
+	return newReadDecision(flags[common.HashLength-1-contractGlobalReadValidByte] != 0), nil
+}
+
+// guardianshipIndex mirrors GuardianshipIndex, returning the first guardianship
+// that lists guardian, or the first empty one if guardian is the zero address.
+func (p *nativeAccessPolicy) guardianshipIndex(guardian common.Address) (uint64, bool) {
+	for index := uint64(1); index < maxGuardianship; index++ {
+		first, second := p.guardians(index)
+		if guardian == (common.Address{}) {
+			if first == guardian && second == guardian {
+				return index, true
+			}
+		} else if first == guardian || second == guardian {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+// guardianshipOwned reports whether the guardianship at index has a guardian.
+func (p *nativeAccessPolicy) guardianshipOwned(index *big.Int) (bool, error) {
+	if !index.IsUint64() || index.Uint64() >= maxGuardianship {
+		return false, errAccessInvalidGuardianship
+	}
+	first, second := p.guardians(index.Uint64())
+	return first != (common.Address{}) || second != (common.Address{}), nil
+}
+
+// subGuardianshipMember mirrors VerifyContractSubGuardianshipList, reporting
+// whether guardianship owns the contract or is on its SubGuardianshipList.
+func (p *nativeAccessPolicy) subGuardianshipMember(contractBase common.Hash, owner, guardianship *big.Int) (bool, error) {
+	if guardianship.Sign() == 0 {
+		return false, nil
+	}
+	owned, err := p.guardianshipOwned(owner)
+	if err != nil || !owned {
+		return false, err
+	}
+	if owner.Cmp(guardianship) == 0 {
+		return true, nil
+	}
+	for i := uint64(0); i < maxGuardianship; i++ {
+		if p.load(slotAt(contractBase, contractSubGuardianshipOffset+i)).Big().Cmp(guardianship) == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// functionAccessGroup mirrors ReadContractFunctionAccessGroup, looking function
+// up in the contract's FunctionList, which ends at the first empty entry.
+func (p *nativeAccessPolicy) functionAccessGroup(contractBase common.Hash, function common.Address) (*big.Int, bool) {
+	for i := uint64(0); i < maxFuncList; i++ {
+		entry := common.BytesToAddress(p.load(slotAt(contractBase, contractFunctionListOffset+i)).Bytes())
+		if entry == (common.Address{}) {
+			break
+		}
+		if entry == function {
+			return p.load(slotAt(contractBase, contractAccessGroupOffset+i)).Big(), true
+		}
+	}
+	return nil, false
+}
+
+// guardians returns the GuardianList of the guardianship at index.
+func (p *nativeAccessPolicy) guardians(index uint64) (common.Address, common.Address) {
+	base := guardianshipTableSlot + index*guardianshipStructSize + guardianshipGuardianListOffset
+	first := common.BytesToAddress(p.load(common.BigToHash(new(big.Int).SetUint64(base))).Bytes())
+	second := common.BytesToAddress(p.load(common.BigToHash(new(big.Int).SetUint64(base + 1))).Bytes())
+	return first, second
+}
+
+// load reads a storage slot of the access contract.
+func (p *nativeAccessPolicy) load(slot common.Hash) common.Hash {
+	return p.evm.StateDB.GetState(p.access.Contract, slot)
+}
+
+// mappingSlot returns the storage slot Solidity assigns to the value stored
+// under key in the mapping declared at slot.
+func mappingSlot(key common.Address, slot uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(key.Bytes(), 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(slot).Bytes(), 32),
+	)
+}
+
+// slotAt returns the storage slot offset slots after base.
+func slotAt(base common.Hash, offset uint64) common.Hash {
+	slot := new(big.Int).Add(base.Big(), new(big.Int).SetUint64(offset))
+	return common.BigToHash(slot.Mod(slot, tt256))
+}
+
+// NewAccessPolicy returns the policy the EVM uses for the access contract
+// configured by access: a native evaluation of its storage if the contract is
+// declared to use the AccessRights layout, or nested calls into its code.
+func NewAccessPolicy(evm *EVM, access params.VeriteemAccess) AccessPolicy {
+	if access.Native {
+		return newNativeAccessPolicy(evm, access)
+	}
+	return newContractAccessPolicy(evm, access)
+}
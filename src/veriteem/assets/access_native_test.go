@@ -0,0 +1,218 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// compareAccessPolicies evaluates two policies against every combination of the
+// given callers, contracts and selectors, returning an error describing the
+// first answer they disagree on. The read checks of have are held against the
+// full checks of want too.
+func compareAccessPolicies(want, have AccessPolicy, callers, contracts []common.Address, selectors [][4]byte) error {
+	for _, caller := range callers {
+		wantGroup, wantErr := want.ContributorAccessGroup(caller)
+		haveGroup, haveErr := have.ContributorAccessGroup(caller)
+		if (wantErr == nil) != (haveErr == nil) || (wantErr == nil && wantGroup.Cmp(haveGroup) != 0) {
+			return fmt.Errorf("access group of %x: have %v (%v), want %v (%v)", caller, haveGroup, haveErr, wantGroup, wantErr)
+		}
+		wantMember, wantErr := want.IsContributor(caller)
+		haveMember, haveErr := have.IsContributor(caller)
+		if wantMember != haveMember || (wantErr == nil) != (haveErr == nil) {
+			return fmt.Errorf("contributor %x: have %v (%v), want %v (%v)", caller, haveMember, haveErr, wantMember, wantErr)
+		}
+		wantCreate, wantErr := want.CanCreate(caller)
+		haveCreate, haveErr := have.CanCreate(caller)
+		if wantCreate != haveCreate || (wantErr == nil) != (haveErr == nil) {
+			return fmt.Errorf("create by %x: have %v (%v), want %v (%v)", caller, haveCreate, haveErr, wantCreate, wantErr)
+		}
+		for _, contract := range contracts {
+			wantStatus, wantErr := want.ContractStatus(contract)
+			haveStatus, haveErr := have.ContractStatus(contract)
+			if wantStatus != haveStatus || (wantErr == nil) != (haveErr == nil) {
+				return fmt.Errorf("status of %x: have %+v (%v), want %+v (%v)", contract, haveStatus, haveErr, wantStatus, wantErr)
+			}
+			for _, selector := range selectors {
+				wantDecision, wantErr := want.CheckAccess(caller, contract, selector)
+				haveDecision, haveErr := have.CheckAccess(caller, contract, selector)
+				if wantDecision.Read != haveDecision.Read || wantDecision.Write != haveDecision.Write || (wantErr == nil) != (haveErr == nil) {
+					return fmt.Errorf("access by %x to %x/%x: have read %v write %v (%v), want read %v write %v (%v)",
+						caller, contract, selector, haveDecision.Read, haveDecision.Write, haveErr, wantDecision.Read, wantDecision.Write, wantErr)
+				}
+				haveRead, haveErr := have.CheckRead(caller, contract, selector)
+				if wantDecision.Read != haveRead.Read || (wantErr == nil) != (haveErr == nil) {
+					return fmt.Errorf("read by %x from %x/%x: have %v (%v), want %v (%v)",
+						caller, contract, selector, haveRead.Read, haveErr, wantDecision.Read, wantErr)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// randomAccessTables fills the tables of an access contract with random
+// entries, returning the callers, contracts and selectors they refer to. The
+// guardians are drawn from the callers, so that some of them may create
+// contracts, and the zero selector of fallback calls is always included.
+func randomAccessTables(rnd *rand.Rand, tables accessTables) (callers, contracts []common.Address, selectors [][4]byte) {
+	for i := 0; i < 8; i++ {
+		callers = append(callers, randomAddress(rnd))
+	}
+	for i := 0; i < 6; i++ {
+		contracts = append(contracts, randomAddress(rnd))
+	}
+	selectors = append(selectors, [4]byte{})
+	for i := 0; i < 6; i++ {
+		var selector [4]byte
+		rnd.Read(selector[:])
+		selector[0] |= 0x80 // Never the zero selector ending a FunctionList
+		selectors = append(selectors, selector)
+	}
+	pick := func() common.Address {
+		if rnd.Intn(3) == 0 {
+			return common.Address{}
+		}
+		return callers[rnd.Intn(len(callers))]
+	}
+	for index := uint64(1); index < maxGuardianship; index++ {
+		if rnd.Intn(2) == 0 {
+			tables.setGuardians(index, pick(), pick())
+		}
+	}
+	// Now and then a guardianship outside GuardianshipTable, on which the
+	// contract code throws if a contract is owned by it
+	guardianship := func() uint64 {
+		if rnd.Intn(8) == 0 {
+			return maxGuardianship + uint64(rnd.Intn(3))
+		}
+		return uint64(rnd.Intn(maxGuardianship))
+	}
+	for _, contract := range contracts {
+		entry := testContractEntry{
+			Guardianship:    guardianship(),
+			WriteValid:      rnd.Intn(2) == 0,
+			GlobalReadValid: rnd.Intn(2) == 0,
+			State:           ContractState(rnd.Intn(6)),
+		}
+		if rnd.Intn(4) == 0 {
+			entry.NewAddress = contracts[rnd.Intn(len(contracts))]
+		}
+		for i := rnd.Intn(4); i > 0; i-- {
+			entry.SubGuardianships = append(entry.SubGuardianships, guardianship())
+		}
+		for i := rnd.Intn(len(selectors)); i > 0; i-- {
+			entry.Functions = append(entry.Functions, testFunction{
+				Selector:    selectors[1+rnd.Intn(len(selectors)-1)],
+				AccessGroup: uint64(rnd.Intn(8)),
+			})
+		}
+		tables.setContract(contract, entry)
+	}
+	for _, caller := range callers {
+		tables.setContributor(caller, testContributorEntry{
+			Guardianship:    guardianship(),
+			Limit:           uint64(rnd.Intn(3)),
+			AccessGroup:     uint64(rnd.Intn(8)),
+			LastBlockNumber: uint64(rnd.Intn(20)),
+		})
+	}
+	return callers, contracts, selectors
+}
+
+// randomAddress returns a random address.
+func randomAddress(rnd *rand.Rand) common.Address {
+	var addr common.Address
+	rnd.Read(addr[:])
+	return addr
+}
+
+// Tests that the native evaluation of the AccessRights storage answers every
+// question exactly like the contract code, over randomized tables.
+func TestNativeAccessPolicy(t *testing.T) {
+	config := newAccessTestConfig(allAccessForks(false))
+	for seed := int64(0); seed < 25; seed++ {
+		var (
+			rnd     = rand.New(rand.NewSource(seed))
+			statedb = newAccessTestState(t)
+		)
+		callers, contracts, selectors := randomAccessTables(rnd, accessTables{statedb, testAccessContract})
+
+		evm := newAccessTestEVM(statedb, config, uint64(rnd.Intn(25)), Config{})
+		access, _ := config.VeriteemAccess(evm.BlockNumber)
+		want := newContractAccessPolicy(evm, access)
+		have := newNativeAccessPolicy(evm, access)
+		if err := compareAccessPolicies(want, have, callers, contracts, selectors); err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+		}
+	}
+}
+
+// BenchmarkAccessPolicy compares the cost of a permission check answered by the
+// contract code with its native evaluation, on the longest path through the
+// tables: a function at the end of a full FunctionList, called by a rate limited
+// contributor of the last sub guardianship.
+func BenchmarkAccessPolicy(b *testing.B) {
+	var (
+		statedb  = newAccessTestState(b)
+		tables   = accessTables{statedb, testAccessContract}
+		config   = newAccessTestConfig(allAccessForks(false))
+		guardian = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		caller   = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+		contract = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+		selector = [4]byte{0xde, 0xad, 0xbe, 0xef}
+	)
+	tables.setGuardians(1, guardian, common.Address{})
+
+	entry := testContractEntry{Guardianship: 1, WriteValid: true, GlobalReadValid: true, State: ContractStateActive}
+	for i := uint64(0); i < maxGuardianship-1; i++ {
+		entry.SubGuardianships = append(entry.SubGuardianships, 100+i)
+	}
+	entry.SubGuardianships = append(entry.SubGuardianships, 2)
+	for i := uint32(0); i < maxFuncList-1; i++ {
+		entry.Functions = append(entry.Functions, testFunction{Selector: [4]byte{0x01, 0x00, byte(i >> 8), byte(i)}, AccessGroup: 2})
+	}
+	entry.Functions = append(entry.Functions, testFunction{Selector: selector, AccessGroup: 1})
+	tables.setContract(contract, entry)
+	tables.setContributor(caller, testContributorEntry{Guardianship: 2, Limit: 5, AccessGroup: 1, LastBlockNumber: 10})
+
+	policies := []struct {
+		name   string
+		policy func(evm *EVM, access params.VeriteemAccess) AccessPolicy
+	}{
+		{"contract", func(evm *EVM, access params.VeriteemAccess) AccessPolicy { return newContractAccessPolicy(evm, access) }},
+		{"native", func(evm *EVM, access params.VeriteemAccess) AccessPolicy { return newNativeAccessPolicy(evm, access) }},
+	}
+	for _, bench := range policies {
+		b.Run(bench.name, func(b *testing.B) {
+			access, _ := config.VeriteemAccess(common.Big0)
+			policy := bench.policy(newAccessTestEVM(statedb, config, 100, Config{}), access)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if decision, err := policy.CheckAccess(caller, contract, selector); err != nil || !decision.Write {
+					b.Fatalf("check failed: %+v, %v", decision, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// maxAccessListLength bounds the length of the storage arrays and strings read
+// from the access contract, so a corrupt length can't make a snapshot read
+// storage forever.
+const maxAccessListLength = 1 << 16
+
+// AccessSnapshot is the complete permission state held by an access contract
+// using the AccessRights storage layout. The ContractTable and ContributorTable
+// mappings can't be enumerated, so their entries are found through the
+// ContractList and ContributorList of every guardianship.
+type AccessSnapshot struct {
+	Contract      common.Address         `json:"contract"` // Address of the access contract
+	Guardianships []GuardianshipSnapshot `json:"guardianships"`
+	Contracts     []ContractSnapshot     `json:"contracts"`    // Entries of the listed contracts, in list order
+	Contributors  []ContributorSnapshot  `json:"contributors"` // Entries of the listed contributors, in list order
+}
+
+// GuardianshipSnapshot is an entry of the GuardianshipTable. Entries without
+// guardians are only part of a snapshot if their lists aren't empty, which is
+// the case after a guardianship was voted out.
+type GuardianshipSnapshot struct {
+	Index        hexutil.Uint64    `json:"index"`
+	Name         string            `json:"name"`
+	Guardians    [2]common.Address `json:"guardians"`
+	AddVote      common.Address    `json:"addVote"`
+	RemoveVote   common.Address    `json:"removeVote"`
+	Contracts    []common.Address  `json:"contracts"`    // ContractList
+	Contributors []common.Address  `json:"contributors"` // ContributorList, without deleted entries
+}
+
+// Active reports whether the guardianship has a guardian.
+func (g *GuardianshipSnapshot) Active() bool {
+	return g.Guardians[0] != (common.Address{}) || g.Guardians[1] != (common.Address{})
+}
+
+// ContractSnapshot is an entry of the ContractTable.
+type ContractSnapshot struct {
+	Address          common.Address   `json:"address"`
+	Name             string           `json:"name"`
+	Guardianship     hexutil.Uint64   `json:"guardianship"` // Owning guardianship, zero if unowned
+	NewAddress       common.Address   `json:"newAddress"`
+	WriteValid       bool             `json:"writeValid"`
+	GlobalReadValid  bool             `json:"globalReadValid"`
+	State            string           `json:"state"`            // Name of the lifecycle state
+	StateCode        hexutil.Uint64   `json:"stateCode"`        // Raw State of the entry
+	SubGuardianships []hexutil.Uint64 `json:"subGuardianships"` // SubGuardianshipList, without empty slots
+	Functions        []AccessFunction `json:"functions"`        // FunctionList up to its first empty slot
+}
+
+// AccessFunction is an entry of the FunctionList of a contract, restricting the
+// function with the given selector to a set of access groups.
+type AccessFunction struct {
+	Index       hexutil.Uint64 `json:"index"`
+	Selector    hexutil.Bytes  `json:"selector"`
+	AccessGroup *hexutil.Big   `json:"accessGroup"`
+}
+
+// ContributorSnapshot is an entry of the ContributorTable.
+type ContributorSnapshot struct {
+	Address         common.Address `json:"address"`
+	Name            string         `json:"name"`
+	Guardianship    hexutil.Uint64 `json:"guardianship"` // Owning guardianship, zero if deleted
+	Limit           *hexutil.Big   `json:"limit"`
+	AccessGroup     *hexutil.Big   `json:"accessGroup"`
+	LastBlockNumber *hexutil.Big   `json:"lastBlockNumber"`
+}
+
+// accessStorage reads the storage of an access contract using the AccessRights
+// layout.
+type accessStorage struct {
+	state    StateDB
+	contract common.Address
+}
+
+// ReadAccessSnapshot walks the storage of the access contract at contract,
+// which has to use the AccessRights storage layout, and returns its permission
+// tables. Contracts and contributors listed by several guardianships, which
+// happens once one was voted out, are only reported once.
+func ReadAccessSnapshot(statedb StateDB, contract common.Address) (*AccessSnapshot, error) {
+	s := accessStorage{state: statedb, contract: contract}
+	snapshot := &AccessSnapshot{
+		Contract:      contract,
+		Guardianships: []GuardianshipSnapshot{},
+		Contracts:     []ContractSnapshot{},
+		Contributors:  []ContributorSnapshot{},
+	}
+	var (
+		contracts    = make(map[common.Address]bool)
+		contributors = make(map[common.Address]bool)
+	)
+	for index := uint64(1); index < maxGuardianship; index++ {
+		guardianship, err := s.guardianship(index)
+		if err != nil {
+			return nil, err
+		}
+		if !guardianship.Active() && len(guardianship.Contracts) == 0 && len(guardianship.Contributors) == 0 {
+			continue
+		}
+		snapshot.Guardianships = append(snapshot.Guardianships, *guardianship)
+
+		for _, address := range guardianship.Contracts {
+			if contracts[address] {
+				continue
+			}
+			contracts[address] = true
+			snapshot.Contracts = append(snapshot.Contracts, s.contractEntry(address))
+		}
+		for _, address := range guardianship.Contributors {
+			if contributors[address] {
+				continue
+			}
+			contributors[address] = true
+			snapshot.Contributors = append(snapshot.Contributors, s.contributorEntry(address))
+		}
+	}
+	return snapshot, nil
+}
+
+// guardianship reads the entry at index of the GuardianshipTable.
+func (s accessStorage) guardianship(index uint64) (*GuardianshipSnapshot, error) {
+	base := common.BigToHash(new(big.Int).SetUint64(guardianshipTableSlot + index*guardianshipStructSize))
+
+	contracts, err := s.addressList(slotAt(base, guardianshipContractListOffset))
+	if err != nil {
+		return nil, fmt.Errorf("ContractList of guardianship %d: %v", index, err)
+	}
+	contributors, err := s.addressList(slotAt(base, guardianshipContributorListOffset))
+	if err != nil {
+		return nil, fmt.Errorf("ContributorList of guardianship %d: %v", index, err)
+	}
+	// Deleted contributors leave a zero entry behind
+	listed := contributors[:0]
+	for _, contributor := range contributors {
+		if contributor != (common.Address{}) {
+			listed = append(listed, contributor)
+		}
+	}
+	return &GuardianshipSnapshot{
+		Index: hexutil.Uint64(index),
+		Name:  s.loadString(slotAt(base, guardianshipNameOffset)),
+		Guardians: [2]common.Address{
+			s.loadAddress(slotAt(base, guardianshipGuardianListOffset)),
+			s.loadAddress(slotAt(base, guardianshipGuardianListOffset+1)),
+		},
+		AddVote:      s.loadAddress(slotAt(base, guardianshipAddVoteOffset)),
+		RemoveVote:   s.loadAddress(slotAt(base, guardianshipRemoveVoteOffset)),
+		Contracts:    contracts,
+		Contributors: listed,
+	}, nil
+}
+
+// contractEntry reads the entry of contract in the ContractTable.
+func (s accessStorage) contractEntry(contract common.Address) ContractSnapshot {
+	base := mappingSlot(contract, contractTableSlot)
+
+	flags := s.load(slotAt(base, contractFlagsOffset))
+	state := s.load(slotAt(base, contractStateOffset))
+	entry := ContractSnapshot{
+		Address:          contract,
+		Name:             s.loadString(slotAt(base, contractNameOffset)),
+		Guardianship:     hexutil.Uint64(s.load(slotAt(base, contractGuardianshipOffset)).Big().Uint64()),
+		NewAddress:       common.BytesToAddress(flags[common.HashLength-common.AddressLength:]),
+		WriteValid:       flags[common.HashLength-1-contractWriteValidByte] != 0,
+		GlobalReadValid:  flags[common.HashLength-1-contractGlobalReadValidByte] != 0,
+		State:            newContractState(state.Bytes()).String(),
+		StateCode:        hexutil.Uint64(state.Big().Uint64()),
+		SubGuardianships: []hexutil.Uint64{},
+		Functions:        []AccessFunction{},
+	}
+	for i := uint64(0); i < maxGuardianship; i++ {
+		if index := s.load(slotAt(base, contractSubGuardianshipOffset+i)).Big(); index.Sign() != 0 {
+			entry.SubGuardianships = append(entry.SubGuardianships, hexutil.Uint64(index.Uint64()))
+		}
+	}
+	for i := uint64(0); i < maxFuncList; i++ {
+		function := s.loadAddress(slotAt(base, contractFunctionListOffset+i))
+		if function == (common.Address{}) {
+			break
+		}
+		entry.Functions = append(entry.Functions, AccessFunction{
+			Index:       hexutil.Uint64(i),
+			Selector:    common.CopyBytes(function[common.AddressLength-4:]),
+			AccessGroup: (*hexutil.Big)(s.load(slotAt(base, contractAccessGroupOffset+i)).Big()),
+		})
+	}
+	return entry
+}
+
+// contributorEntry reads the entry of contributor in the ContributorTable.
+func (s accessStorage) contributorEntry(contributor common.Address) ContributorSnapshot {
+	base := mappingSlot(contributor, contributorTableSlot)
+
+	return ContributorSnapshot{
+		Address:         contributor,
+		Name:            s.loadString(slotAt(base, contributorNameOffset)),
+		Guardianship:    hexutil.Uint64(s.load(slotAt(base, contributorGuardianshipOffset)).Big().Uint64()),
+		Limit:           (*hexutil.Big)(s.load(slotAt(base, contributorLimitOffset)).Big()),
+		AccessGroup:     (*hexutil.Big)(s.load(slotAt(base, contributorAccessGroupOffset)).Big()),
+		LastBlockNumber: (*hexutil.Big)(s.load(slotAt(base, contributorLastBlockNumberOffset)).Big()),
+	}
+}
+
+// addressList reads the dynamic address array declared at slot, whose length
+// is stored at slot and whose elements start at keccak256(slot).
+func (s accessStorage) addressList(slot common.Hash) ([]common.Address, error) {
+	length := s.load(slot).Big()
+	if !length.IsUint64() || length.Uint64() > maxAccessListLength {
+		return nil, fmt.Errorf("invalid length %v", length)
+	}
+	data := crypto.Keccak256Hash(slot[:])
+
+	list := make([]common.Address, length.Uint64())
+	for i := range list {
+		list[i] = s.loadAddress(slotAt(data, uint64(i)))
+	}
+	return list, nil
+}
+
+// loadString reads the string declared at slot. Strings shorter than 32 bytes
+// are stored in the slot itself with twice their length in the low order byte,
+// longer ones store twice their length plus one in the slot and their content
+// from keccak256(slot) on. Strings too long to be genuine are cut short.
+func (s accessStorage) loadString(slot common.Hash) string {
+	word := s.load(slot)
+	if word[common.HashLength-1]&1 == 0 {
+		length := int(word[common.HashLength-1] / 2)
+		if length >= common.HashLength {
+			length = common.HashLength - 1
+		}
+		return string(word[:length])
+	}
+	length := new(big.Int).Rsh(word.Big(), 1)
+	if !length.IsUint64() || length.Uint64() > maxAccessListLength {
+		length.SetUint64(maxAccessListLength)
+	}
+	data := crypto.Keccak256Hash(slot[:])
+
+	content := make([]byte, 0, length.Uint64()+common.HashLength)
+	for i := uint64(0); uint64(len(content)) < length.Uint64(); i++ {
+		word := s.load(slotAt(data, i))
+		content = append(content, word[:]...)
+	}
+	return string(content[:length.Uint64()])
+}
+
+// loadAddress reads the address stored in the low order bytes of slot.
+func (s accessStorage) loadAddress(slot common.Hash) common.Address {
+	return common.BytesToAddress(s.load(slot).Bytes())
+}
+
+// load reads a storage slot of the access contract.
+func (s accessStorage) load(slot common.Hash) common.Hash {
+	return s.state.GetState(s.contract, slot)
+}
@@ -0,0 +1,689 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that from the call kinds fork on a contract nobody may read from or
+// write to can't be reached through a proxy with any of the call kinds, while
+// the same proxies reach a contract anyone may read from. Before the fork only
+// CALL is checked.
+func TestCallKinds(t *testing.T) {
+	for _, forked := range []bool{false, true} {
+		veriteem := &params.VeriteemConfig{AccessContract: testAccessContract}
+		if forked {
+			veriteem.CallKindsBlock = new(big.Int)
+		}
+		for _, op := range []OpCode{CALL, CALLCODE, DELEGATECALL, STATICCALL} {
+			evm, guardian, member := newAccessTest(t, newAccessTestConfig(veriteem), 1, 0)
+
+			open := deployTestContract(t, evm, guardian, testReaderCode())
+			blocked := deployTestContract(t, evm, guardian, testReaderCode())
+			writeContractInfo(t, evm, guardian, blocked, false, false)
+
+			tests := []struct {
+				name   string
+				target common.Address
+				want   error
+			}{
+				{"open", open, nil},
+				{"blocked", blocked, errExecutionReverted},
+			}
+			if !forked && op != CALL {
+				tests[1].want = nil
+			}
+			for _, tt := range tests {
+				proxy := deployTestContract(t, evm, guardian, testProxyCode(op, tt.target))
+				if _, _, err := evm.Call(AccountRef(member), proxy, nil, testGas, new(big.Int)); err != tt.want {
+					t.Errorf("forked %v: %v to %s contract: have %v, want %v", forked, op, tt.name, err, tt.want)
+				}
+			}
+		}
+	}
+}
+
+// Tests that the writes a call kind makes are reverted unless the account it is
+// checked for may write to the contract whose code runs: the calling contract,
+// or for DELEGATECALL the caller of the proxy, a contributor.
+func TestCallKindWrites(t *testing.T) {
+	config := newAccessTestConfig(&params.VeriteemConfig{AccessContract: testAccessContract, CallKindsBlock: new(big.Int)})
+	for _, op := range []OpCode{CALL, CALLCODE, DELEGATECALL} {
+		for _, writer := range []bool{false, true} {
+			evm, guardian, member := newAccessTest(t, config, 1, 0)
+
+			counter := deployTestContract(t, evm, guardian, testCounterCode())
+			proxy := deployTestContract(t, evm, guardian, testProxyCode(op, counter))
+			if writer {
+				adminAccess(t, evm, guardian, "CreateContributor(address,string,uint256,uint256)", proxy, "proxy", uint64(0), uint64(0))
+			}
+			if _, _, err := evm.Call(AccountRef(member), proxy, nil, testGas, new(big.Int)); err != nil {
+				t.Fatalf("%v by writer %v: call failed: %v", op, writer, err)
+			}
+			// Only CALL runs the counter on its own storage
+			storage := proxy
+			if op == CALL {
+				storage = counter
+			}
+			want := common.Hash{}
+			if writer || op == DELEGATECALL {
+				want = uint64Hash(1)
+			}
+			if have := evm.StateDB.GetState(storage, common.Hash{}); have != want {
+				t.Errorf("%v by writer %v: count %x, want %x", op, writer, have, want)
+			}
+		}
+	}
+}
+
+// Tests that contract creations are checked against the access contract the
+// chain config schedules at the block, and not at all before access control
+// activates.
+func TestAccessSchedule(t *testing.T) {
+	var (
+		guardian = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		outsider = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+		upgraded = common.HexToAddress("0x0000000000000000000000000000000000000200")
+	)
+	config := *params.AllEthashProtocolChanges
+	config.Veriteem = &params.VeriteemConfig{
+		AccessContract: testAccessContract,
+		AccessBlock:    big.NewInt(5),
+		Upgrades:       []params.VeriteemUpgrade{{Block: big.NewInt(10), AccessContract: upgraded}},
+	}
+	statedb := newAccessTestState(t)
+	statedb.SetCode(upgraded, accessRightsCode(t))
+
+	// Only the original access contract knows the guardian
+	adminAccess(t, newAccessTestEVM(statedb, &config, 5, Config{}), guardian, "InitGuardianship()")
+
+	tests := []struct {
+		number  uint64
+		creator common.Address
+		want    bool
+	}{
+		{4, outsider, true},
+		{5, outsider, false},
+		{5, guardian, true},
+		{10, guardian, false},
+	}
+	for _, tt := range tests {
+		evm := newAccessTestEVM(statedb, &config, tt.number, Config{})
+		contract := deployTestContract(t, evm, tt.creator, testReaderCode())
+		if deployed := len(statedb.GetCode(contract)) > 0; deployed != tt.want {
+			t.Errorf("block %d creation by %x: deployed %v, want %v", tt.number, tt.creator, deployed, tt.want)
+		}
+	}
+}
+
+// Tests that the contract policy answers as the AccessRights contract does.
+func TestContractAccessPolicy(t *testing.T) {
+	evm, guardian, member := newAccessTest(t, params.AllEthashProtocolChanges, 1, 0)
+	contract := deployTestContract(t, evm, guardian, testCounterCode())
+	outsider := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+
+	access, _ := evm.ChainConfig().VeriteemAccess(evm.BlockNumber)
+	policy := newContractAccessPolicy(evm, access)
+	for caller, want := range map[common.Address]bool{guardian: true, member: false, outsider: false} {
+		if allowed, err := policy.CanCreate(caller); err != nil || allowed != want {
+			t.Errorf("create by %x: have %v (%v), want %v", caller, allowed, err, want)
+		}
+	}
+	for caller, want := range map[common.Address]bool{member: true, outsider: false} {
+		decision, err := policy.CheckAccess(caller, contract, [4]byte{})
+		if err != nil || !decision.Read || decision.Write != want {
+			t.Errorf("access by %x: have %+v (%v), want read true write %v", caller, decision, err, want)
+		}
+	}
+}
+
+// Tests that the contract policy fails, instead of panicking, on the answers of
+// an access contract that doesn't answer as AccessRights does, and that calls
+// are denied when it does.
+func TestContractAccessPolicyMalformed(t *testing.T) {
+	var (
+		caller   = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+		contract = common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	)
+	tests := []struct {
+		name string
+		code []byte
+	}{
+		{"no code", nil},
+		{"empty", []byte{0x00}},
+		{"reverting", []byte{0x60, 0x00, 0x80, 0xfd}},
+		{"short", testReturnCode(uint64Hash(1))},
+		{"malformed", testReturnCode(uint64Hash(2), uint64Hash(1))},
+	}
+	for _, tt := range tests {
+		statedb := newAccessTestState(t)
+		statedb.SetCode(testAccessContract, tt.code)
+		statedb.SetCode(contract, []byte{0x00})
+
+		evm := newAccessTestEVM(statedb, params.AllEthashProtocolChanges, 1, Config{})
+		access, _ := evm.ChainConfig().VeriteemAccess(evm.BlockNumber)
+		policy := newContractAccessPolicy(evm, access)
+		if _, err := policy.CanCreate(caller); err == nil {
+			t.Errorf("%s: create check succeeded", tt.name)
+		}
+		if _, err := policy.CheckAccess(caller, contract, [4]byte{}); err == nil {
+			t.Errorf("%s: access check succeeded", tt.name)
+		}
+		if _, _, err := evm.Call(AccountRef(caller), contract, nil, testGas, new(big.Int)); err != ErrContractDisabled {
+			t.Errorf("%s: call error %v, want %v", tt.name, err, ErrContractDisabled)
+		}
+	}
+}
+
+func TestUnpackBool(t *testing.T) {
+	tests := []struct {
+		word []byte
+		want bool
+		err  error
+	}{
+		{uint64Hash(0).Bytes(), false, nil},
+		{uint64Hash(1).Bytes(), true, nil},
+		{uint64Hash(2).Bytes(), false, errAccessMalformedReturn},
+		{uint64Hash(1 << 8).Bytes(), false, errAccessMalformedReturn},
+		{uint64Hash(1).Bytes()[1:], false, errAccessShortReturn},
+		{nil, false, errAccessShortReturn},
+	}
+	for i, tt := range tests {
+		if have, err := unpackBool(tt.word); have != tt.want || err != tt.err {
+			t.Errorf("test %d: have %v (%v), want %v (%v)", i, have, err, tt.want, tt.err)
+		}
+	}
+}
+
+// Tests that the policies report why they restricted access. The contract
+// policy can't tell why all access was denied, nor whether a contributor of
+// another guardianship belongs to a sub guardianship.
+func TestAccessReasons(t *testing.T) {
+	var (
+		guardian   = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		member     = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+		outsider   = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+		sub        = common.HexToAddress("0x00000000000000000000000000000000000000dd")
+		limited    = common.HexToAddress("0x00000000000000000000000000000000000000ee")
+		contract   = common.HexToAddress("0x00000000000000000000000000000000000000d1")
+		private    = common.HexToAddress("0x00000000000000000000000000000000000000d2")
+		disabled   = common.HexToAddress("0x00000000000000000000000000000000000000d3")
+		unowned    = common.HexToAddress("0x00000000000000000000000000000000000000d4")
+		shared     = common.HexToAddress("0x00000000000000000000000000000000000000d5")
+		restricted = [4]byte{0x12, 0x34, 0x56, 0x78}
+	)
+	statedb := newAccessTestState(t)
+	tables := accessTables{statedb, testAccessContract}
+	tables.setGuardians(1, guardian, common.Address{})
+	tables.setContract(contract, testContractEntry{Guardianship: 1, WriteValid: true, GlobalReadValid: true, State: ContractStateActive, Functions: []testFunction{{restricted, 2}}})
+	tables.setContract(private, testContractEntry{Guardianship: 1, WriteValid: true, State: ContractStateActive})
+	tables.setContract(disabled, testContractEntry{Guardianship: 1, GlobalReadValid: true, State: ContractStateActive, SubGuardianships: []uint64{2}})
+	tables.setContract(shared, testContractEntry{Guardianship: 1, WriteValid: true, GlobalReadValid: true, State: ContractStateActive, SubGuardianships: []uint64{3}})
+	tables.setContributor(member, testContributorEntry{Guardianship: 1, AccessGroup: 1})
+	tables.setContributor(sub, testContributorEntry{Guardianship: 2})
+	tables.setContributor(limited, testContributorEntry{Guardianship: 1, Limit: 5, LastBlockNumber: 8})
+
+	for _, native := range []bool{false, true} {
+		evm := newAccessTestEVM(statedb, newAccessTestConfig(allAccessForks(native)), 10, Config{})
+		policy := NewAccessPolicy(evm, evm.access)
+
+		tests := []struct {
+			caller   common.Address
+			contract common.Address
+			selector [4]byte
+			want     AccessDecision
+			reason   AccessReason // Reason given by the contract policy
+		}{
+			{member, contract, [4]byte{}, AccessDecision{Read: true, Write: true}, AccessGranted},
+			{member, unowned, [4]byte{}, AccessDecision{Reason: AccessUnownedContract}, AccessDenied},
+			{member, contract, restricted, AccessDecision{Reason: AccessGroupMismatch}, AccessDenied},
+			{outsider, contract, [4]byte{}, AccessDecision{Read: true, Reason: AccessNoGuardianship}, AccessNoGuardianship},
+			{sub, shared, [4]byte{}, AccessDecision{Read: true, Reason: AccessNoGuardianship}, AccessNoGuardianship},
+			{member, disabled, [4]byte{}, AccessDecision{Read: true, Reason: AccessWriteDisabled}, AccessWriteDisabled},
+			{sub, disabled, [4]byte{}, AccessDecision{Read: true, Reason: AccessWriteDisabled}, AccessWriteDenied},
+			{limited, contract, [4]byte{}, AccessDecision{Read: true, Reason: AccessRateLimited}, AccessRateLimited},
+			{member, private, [4]byte{}, AccessDecision{Write: true, Reason: AccessReadDenied}, AccessReadDenied},
+		}
+		for i, tt := range tests {
+			want := tt.want
+			if !native {
+				want.Reason = tt.reason
+			}
+			if have, err := policy.CheckAccess(tt.caller, tt.contract, tt.selector); err != nil || have != want {
+				t.Errorf("native %v test %d: have %+v (%v), want %+v", native, i, have, err, want)
+			}
+		}
+	}
+}
+
+// Tests that calls made by a transaction fail with the reason access was
+// denied from the denial errors fork on, and with ErrContractDisabled before.
+func TestDenialErrors(t *testing.T) {
+	outsider := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	for _, forked := range []bool{false, true} {
+		veriteem := allAccessForks(true)
+		if !forked {
+			veriteem.DenialErrorsBlock = nil
+		}
+		evm, guardian, member := newAccessTest(t, newAccessTestConfig(veriteem), 1, 0)
+
+		private := deployTestContract(t, evm, guardian, testReaderCode())
+		writeContractInfo(t, evm, guardian, private, true, false)
+
+		want, wantOutput := ErrContractDisabled, []byte(nil)
+		if forked {
+			want, wantOutput = ErrNoGuardianship, packRevertReason(ErrNoGuardianship)
+		}
+		output, _, err := evm.Call(AccountRef(outsider), private, nil, testGas, new(big.Int))
+		if err != want || !bytes.Equal(output, wantOutput) {
+			t.Errorf("forked %v: read of private contract: have %x (%v), want %x (%v)", forked, output, err, wantOutput, want)
+		}
+		// Discarded writes only fail the call after the fork
+		counter := deployTestContract(t, evm, guardian, testCounterCode())
+		writeContractInfo(t, evm, guardian, counter, false, true)
+
+		want = nil
+		if forked {
+			want = ErrContractDisabled
+		}
+		if _, _, err := evm.Call(AccountRef(member), counter, nil, testGas, new(big.Int)); err != want {
+			t.Errorf("forked %v: write to disabled contract: have %v, want %v", forked, err, want)
+		}
+		if count := evm.StateDB.GetState(counter, common.Hash{}); count != (common.Hash{}) {
+			t.Errorf("forked %v: write to disabled contract kept", forked)
+		}
+	}
+}
+
+// Tests that the outermost call of eth_call, whose state changes are thrown
+// away, is only checked for read access, while denied reads still fail.
+func TestReadOnlyAccess(t *testing.T) {
+	outsider := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	config := newAccessTestConfig(allAccessForks(true))
+	evm, guardian, _ := newAccessTest(t, config, 1, 0)
+
+	counter := deployTestContract(t, evm, guardian, testCounterCode())
+	private := deployTestContract(t, evm, guardian, testReaderCode())
+	writeContractInfo(t, evm, guardian, private, true, false)
+
+	if _, _, err := evm.Call(AccountRef(outsider), counter, nil, testGas, new(big.Int)); err != ErrNoGuardianship {
+		t.Errorf("write by transaction: have %v, want %v", err, ErrNoGuardianship)
+	}
+	readOnly := newAccessTestEVM(evm.StateDB, config, 1, Config{ReadOnlyAccess: true})
+	if _, _, err := readOnly.Call(AccountRef(outsider), counter, nil, testGas, new(big.Int)); err != nil {
+		t.Errorf("write by read only call: have %v, want none", err)
+	}
+	if _, _, err := readOnly.Call(AccountRef(outsider), private, nil, testGas, new(big.Int)); err != ErrNoGuardianship {
+		t.Errorf("read of private contract by read only call: have %v, want %v", err, ErrNoGuardianship)
+	}
+}
+
+func TestAccessReasonErr(t *testing.T) {
+	tests := map[AccessReason]error{
+		AccessGranted:         nil,
+		AccessDenied:          ErrWriteDenied,
+		AccessWriteDenied:     ErrWriteDenied,
+		AccessReadDenied:      ErrReadDenied,
+		AccessUnownedContract: ErrUnownedContract,
+		AccessGroupMismatch:   ErrAccessGroupDenied,
+		AccessNoGuardianship:  ErrNoGuardianship,
+		AccessRateLimited:     ErrRateLimited,
+		AccessWriteDisabled:   ErrContractDisabled,
+		AccessContractPaused:  ErrContractPaused,
+		AccessContractRetired: ErrContractRetired,
+		AccessCreateDenied:    ErrCreateDenied,
+	}
+	for reason, want := range tests {
+		if have := reason.Err(); have != want {
+			t.Errorf("%v: have %v, want %v", reason, have, want)
+		}
+	}
+}
+
+func TestPackRevertReason(t *testing.T) {
+	want := append(common.Hex2Bytes("08c379a0"), uint64Hash(32).Bytes()...)
+	want = append(want, uint64Hash(28).Bytes()...)
+	want = append(want, common.RightPadBytes([]byte("contract write access denied"), 32)...)
+
+	if have := packRevertReason(ErrWriteDenied); !bytes.Equal(have, want) {
+		t.Errorf("have %x, want %x", have, want)
+	}
+}
+
+// Tests that from the access gas fork on, every permission check costs a fixed
+// amount of gas, whichever way the policy is evaluated, while calls to the
+// access contract itself and to precompiled contracts stay free, and that
+// TransactionAccessGas agrees.
+func TestAccessCheckGas(t *testing.T) {
+	ecrecover := common.BytesToAddress([]byte{1})
+	for _, native := range []bool{false, true} {
+		used := make(map[bool][4]uint64)
+		for _, charged := range []bool{false, true} {
+			veriteem := allAccessForks(native)
+			if !charged {
+				veriteem.AccessGasBlock = nil
+			}
+			evm, guardian, member := newAccessTest(t, newAccessTestConfig(veriteem), 1, 0)
+
+			_, counter, createGas, err := evm.Create(AccountRef(guardian), testCounterCode(), testGas, new(big.Int))
+			if err != nil {
+				t.Fatalf("native %v charged %v: creation failed: %v", native, charged, err)
+			}
+			_, callGas, err := evm.Call(AccountRef(member), counter, nil, testGas, new(big.Int))
+			if err != nil {
+				t.Fatalf("native %v charged %v: call failed: %v", native, charged, err)
+			}
+			_, accessGas, err := evm.Call(AccountRef(member), testAccessContract, packAccessMethod("InitGuardianship()"), testGas, new(big.Int))
+			if err != nil {
+				t.Fatalf("native %v charged %v: call of access contract failed: %v", native, charged, err)
+			}
+			// The precompile isn't registered with the access contract, so the
+			// call is denied, but only after paying for its check
+			_, precompileGas, _ := evm.Call(AccountRef(member), ecrecover, nil, testGas, new(big.Int))
+			used[charged] = [4]uint64{testGas - createGas, testGas - callGas, testGas - accessGas, testGas - precompileGas}
+
+			// Calls that can't pay for their check fail without running
+			if charged {
+				_, left, err := evm.Call(AccountRef(member), counter, nil, params.VeriteemAccessCheckGas-1, new(big.Int))
+				if err != ErrOutOfGas || left != 0 {
+					t.Errorf("native %v: underpaid call: have %d gas left (%v), want 0 (%v)", native, left, err, ErrOutOfGas)
+				}
+			}
+		}
+		var (
+			config   = newAccessTestConfig(allAccessForks(native))
+			contract = common.HexToAddress("0x00000000000000000000000000000000000000c0")
+			names    = []string{"creation", "call", "access contract call", "precompile call"}
+			want     = [4]uint64{params.VeriteemCreateCheckGas, params.VeriteemAccessCheckGas, 0, 0}
+		)
+		for i, to := range []*common.Address{nil, &contract, &testAccessContract, &ecrecover} {
+			if have := used[true][i] - used[false][i]; have != want[i] {
+				t.Errorf("native %v: %s charged %d gas, want %d", native, names[i], have, want[i])
+			}
+			if have := TransactionAccessGas(config, big.NewInt(1), to); have != want[i] {
+				t.Errorf("native %v: transaction access gas of %s %d, want %d", native, names[i], have, want[i])
+			}
+		}
+	}
+}
+
+// Tests that from the lifecycle fork on, paused contracts can only be read from
+// and retired ones can't be called at all, for both policies. Before the fork
+// the lifecycle state of a contract doesn't matter.
+func TestContractLifecycle(t *testing.T) {
+	for _, native := range []bool{false, true} {
+		for _, forked := range []bool{false, true} {
+			for _, state := range []ContractState{ContractStateActive, ContractStatePaused, ContractStateDeprecated, ContractStateRetired} {
+				veriteem := allAccessForks(native)
+				if !forked {
+					veriteem.LifecycleBlock = nil
+				}
+				evm, guardian, member := newAccessTest(t, newAccessTestConfig(veriteem), 1, 0)
+
+				counter := deployTestContract(t, evm, guardian, testCounterCode())
+				reader := deployTestContract(t, evm, guardian, testReaderCode())
+				for _, contract := range []common.Address{counter, reader} {
+					adminAccess(t, evm, guardian, "WriteContractInfo(address,string,address,bool,bool,uint256)",
+						contract, "contract", common.Address{}, true, true, uint64(state))
+				}
+				var writeErr, readErr error
+				if forked {
+					switch state {
+					case ContractStatePaused:
+						// Transactions are told that their writes were dropped
+						writeErr, readErr = ErrContractPaused, ErrContractPaused
+					case ContractStateRetired:
+						writeErr, readErr = ErrContractRetired, ErrContractRetired
+					}
+				}
+				if _, _, err := evm.Call(AccountRef(member), counter, nil, testGas, new(big.Int)); err != writeErr {
+					t.Errorf("native %v forked %v %v: write: have %v, want %v", native, forked, state, err, writeErr)
+				}
+				count := uint64(1)
+				if writeErr != nil {
+					count = 0
+				}
+				if have := evm.StateDB.GetState(counter, common.Hash{}); have != uint64Hash(count) {
+					t.Errorf("native %v forked %v %v: count %x, want %d", native, forked, state, have, count)
+				}
+				output, _, err := evm.Call(AccountRef(member), reader, nil, testGas, new(big.Int))
+				if err != readErr || (readErr != ErrContractRetired && !bytes.Equal(output, uint64Hash(1).Bytes())) {
+					t.Errorf("native %v forked %v %v: read: have %x (%v), want %v", native, forked, state, output, err, readErr)
+				}
+			}
+		}
+	}
+}
+
+// testDispatcherCode is the start of a Solidity dispatcher for the functions
+// transfer(address,uint256) and approve(address,uint256), with a PUSH32 whose
+// data holds what would otherwise read as PUSH4 0xdeadbeef.
+var testDispatcherCode = common.Hex2Bytes(
+	"60e060020a600035048063a9059cbb14601d578063095ea7b314601d57" +
+		"7f0000000000000000000000000000000000000000000000000063deadbeef00005b00" +
+		"630102")
+
+func TestDispatchedSelectors(t *testing.T) {
+	selectors := dispatchedSelectors(testDispatcherCode)
+	tests := []struct {
+		selector   [4]byte
+		dispatched bool
+	}{
+		{[4]byte{0xa9, 0x05, 0x9c, 0xbb}, true},
+		{[4]byte{0x09, 0x5e, 0xa7, 0xb3}, true},
+		{[4]byte{0x00, 0x00, 0x00, 0xe0}, true},  // PUSH1 0xe0, harmless
+		{[4]byte{0xde, 0xad, 0xbe, 0xef}, false}, // Push data
+		{[4]byte{0x01, 0x02, 0x00, 0x00}, false}, // Truncated push at the end
+		{[4]byte{0x12, 0x34, 0x56, 0x78}, false},
+	}
+	for _, tt := range tests {
+		if _, ok := selectors[tt.selector]; ok != tt.dispatched {
+			t.Errorf("selector %x: dispatched %v, want %v", tt.selector, ok, tt.dispatched)
+		}
+	}
+}
+
+func TestCallSelector(t *testing.T) {
+	var (
+		contract = common.HexToAddress("0x00000000000000000000000000000000000000dd")
+		account  = common.HexToAddress("0x00000000000000000000000000000000000000ee")
+		transfer = [4]byte{0xa9, 0x05, 0x9c, 0xbb}
+		unknown  = [4]byte{0x12, 0x34, 0x56, 0x78}
+	)
+	tests := []struct {
+		forked   bool
+		addr     common.Address
+		input    []byte
+		selector [4]byte
+		fallback bool
+	}{
+		{false, contract, nil, [4]byte{}, false},
+		{false, contract, []byte{0xa9, 0x05}, [4]byte{0xa9, 0x05}, false},
+		{false, contract, append(unknown[:], 0x01), unknown, false},
+		{true, contract, nil, [4]byte{}, true},
+		{true, contract, []byte{0xa9, 0x05}, [4]byte{}, true},
+		{true, contract, append(transfer[:], 0x01), transfer, false},
+		{true, contract, unknown[:], unknown, true},
+		{true, contract, append(unknown[:], 0x01), unknown, true},
+		{true, account, unknown[:], unknown, false},
+	}
+	for i, tt := range tests {
+		veriteem := allAccessForks(true)
+		if !tt.forked {
+			veriteem.SelectorBlock = nil
+		}
+		statedb := newAccessTestState(t)
+		statedb.SetCode(contract, testDispatcherCode)
+		statedb.AddBalance(account, big.NewInt(1))
+
+		evm := newAccessTestEVM(statedb, newAccessTestConfig(veriteem), 1, Config{})
+		selector, fallback := evm.callSelector(tt.addr, tt.input)
+		if selector != tt.selector || fallback != tt.fallback {
+			t.Errorf("test %d: have %x fallback %v, want %x fallback %v", i, selector, fallback, tt.selector, tt.fallback)
+		}
+	}
+}
+
+// Tests that calls with a selector the contract doesn't dispatch are held
+// against the fallback access group, like calls without one.
+func TestFallbackAccessGroup(t *testing.T) {
+	var (
+		statedb  = newAccessTestState(t)
+		tables   = accessTables{statedb, testAccessContract}
+		guardian = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		caller   = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+		contract = common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	)
+	tables.setGuardians(1, guardian, common.Address{})
+	tables.setContract(contract, testContractEntry{Guardianship: 1, WriteValid: true, State: ContractStateActive})
+	tables.setContributor(caller, testContributorEntry{Guardianship: 1, AccessGroup: 1})
+	statedb.SetCode(contract, testDispatcherCode)
+
+	veriteem := allAccessForks(true)
+	veriteem.FallbackAccessGroup = big.NewInt(2)
+	evm := newAccessTestEVM(statedb, newAccessTestConfig(veriteem), 1, Config{})
+
+	tests := []struct {
+		input   []byte
+		allowed bool
+	}{
+		{common.Hex2Bytes("a9059cbb"), true},
+		{common.Hex2Bytes("095ea7b30000"), true},
+		{nil, false},
+		{common.Hex2Bytes("a905"), false},
+		{common.Hex2Bytes("12345678"), false},
+		{common.Hex2Bytes("deadbeef0000"), false},
+	}
+	for _, tt := range tests {
+		read, write, denial, _ := evm.verifyContractAccess(AccountRef(caller), contract, tt.input, false)
+		if read != tt.allowed || write != tt.allowed {
+			t.Errorf("input %x: read %v write %v (%v), want %v", tt.input, read, write, denial, tt.allowed)
+		}
+		if !tt.allowed && denial != AccessGroupMismatch.Err() {
+			t.Errorf("input %x: denial %v, want %v", tt.input, denial, AccessGroupMismatch.Err())
+		}
+	}
+}
+
+func TestDelegateCaller(t *testing.T) {
+	var (
+		sender = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+		proxy  = common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	)
+	evm := newAccessTestEVM(newAccessTestState(t), newAccessTestConfig(allAccessForks(true)), 1, Config{})
+
+	// The code of the proxy, called by sender, delegates
+	contract := NewContract(AccountRef(sender), AccountRef(proxy), new(big.Int), 0)
+	if have := evm.delegateCaller(contract).Address(); have != sender {
+		t.Errorf("delegated call checked for %x, want %x", have, sender)
+	}
+	// Callers not running any code are checked for themselves
+	if have := evm.delegateCaller(AccountRef(sender)).Address(); have != sender {
+		t.Errorf("direct call checked for %x, want %x", have, sender)
+	}
+}
+
+// Tests the default selectors against the signatures of the AccessRights
+// methods they invoke, as declared in scripts/AccessRights.sol. A mistyped
+// selector would silently turn every permission check into a call to the
+// contract's fallback.
+func TestAccessSelectors(t *testing.T) {
+	tests := []struct {
+		selector  []byte
+		signature string
+	}{
+		{params.VeriteemVerifyAccessSelector, "VerifyContractAccess(address,address)"},
+		{params.VeriteemGuardianshipIndexSelector, "GuardianshipIndex(address)"},
+		{params.VeriteemCreateContractSelector, "CreateContract(address)"},
+		{params.VeriteemUpdateContributorBlockSelector, "UpdateContributorBlock()"},
+		{params.VeriteemReadContractInfoSelector, "ReadContractInfo(address)"},
+		{params.VeriteemReadContributorSelector, "ReadContributor(address)"},
+	}
+	for _, tt := range tests {
+		if want := crypto.Keccak256([]byte(tt.signature))[:4]; !bytes.Equal(tt.selector, want) {
+			t.Errorf("selector of %s: have %x, want %x", tt.signature, tt.selector, want)
+		}
+	}
+}
+
+// Tests that function selectors are passed to the access contract in the word
+// layout its FunctionList holds: the selector in the four low order bytes.
+func TestFunctionAddress(t *testing.T) {
+	want := common.HexToAddress("0x00000000000000000000000000000000a9059cbb")
+	if have := FunctionAddress([4]byte{0xa9, 0x05, 0x9c, 0xbb}); have != want {
+		t.Errorf("function address: have %x, want %x", have, want)
+	}
+	input := packAccessCall(params.VeriteemVerifyAccessSelector, common.Address{0x01}, want)
+	if len(input) != 4+2*32 {
+		t.Fatalf("input length: have %d, want %d", len(input), 4+2*32)
+	}
+	if word := common.BytesToHash(input[4+32:]); word != common.HexToHash("0xa9059cbb") {
+		t.Errorf("selector word: have %x", word)
+	}
+}
+
+// Tests that functions registered with WriteContractFunctionIndex under their
+// FunctionAddress can only be called by contributors of their access groups,
+// for both policies.
+func TestFunctionAccessGroup(t *testing.T) {
+	var (
+		restricted = [4]byte{0x12, 0x34, 0x56, 0x78}
+		open       = [4]byte{0x9a, 0xbc, 0xde, 0xf0}
+		member     = common.HexToAddress("0x00000000000000000000000000000000000000b1")
+		outsider   = common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	)
+	for _, native := range []bool{false, true} {
+		evm, guardian, _ := newAccessTest(t, newAccessTestConfig(allAccessForks(native)), 1, 0)
+		adminAccess(t, evm, guardian, "CreateContributor(address,string,uint256,uint256)", member, "member", uint64(0), uint64(1))
+		adminAccess(t, evm, guardian, "CreateContributor(address,string,uint256,uint256)", outsider, "outsider", uint64(0), uint64(2))
+
+		counter := deployTestContract(t, evm, guardian, testCounterCode())
+		adminAccess(t, evm, guardian, "WriteContractFunctionIndex(address,uint256,address,uint256)",
+			counter, uint64(0), FunctionAddress(restricted), uint64(1))
+
+		tests := []struct {
+			caller   common.Address
+			selector [4]byte
+			allowed  bool
+		}{
+			{member, restricted, true},
+			{outsider, restricted, false},
+			{member, open, true},
+			{outsider, open, true},
+		}
+		var count uint64
+		for _, tt := range tests {
+			_, _, err := evm.Call(AccountRef(tt.caller), counter, tt.selector[:], testGas, new(big.Int))
+			if (err == nil) != tt.allowed || (native && !tt.allowed && err != ErrAccessGroupDenied) {
+				t.Errorf("native %v: call of %x by %x: have %v, want allowed %v", native, tt.selector, tt.caller, err, tt.allowed)
+			}
+			if tt.allowed {
+				count++
+			}
+		}
+		if have := evm.StateDB.GetState(counter, common.Hash{}); have != uint64Hash(count) {
+			t.Errorf("native %v: count %x, want %d", native, have, count)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// AccessTracer is implemented by tracers that want to observe the permission
+// checks of the EVM on top of its execution. The EVM reports every decision
+// taken by its access policy to a vm.Config.Tracer implementing it.
+type AccessTracer interface {
+	// CaptureAccessCheck is called for every call, of any kind, checked
+	// against the access policy.
+	CaptureAccessCheck(env *EVM, caller, contract common.Address, selector [4]byte, read, write bool, reason AccessReason) error
+
+	// CaptureCreateCheck is called for every contract creation checked
+	// against the access policy.
+	CaptureCreateCheck(env *EVM, caller common.Address, allowed bool) error
+}
+
+// accessTracer returns the tracer to report permission checks to, if any.
+func (evm *EVM) accessTracer() AccessTracer {
+	if !evm.vmConfig.Debug {
+		return nil
+	}
+	tracer, _ := evm.vmConfig.Tracer.(AccessTracer)
+	return tracer
+}
+
+// AccessCheckLog is a permission check captured by the AccessLogger.
+type AccessCheckLog struct {
+	Depth    int            `json:"depth"`
+	Create   bool           `json:"create"`
+	Caller   common.Address `json:"caller"`
+	Contract common.Address `json:"contract"`           // Zero for creations
+	Selector hexutil.Bytes  `json:"selector,omitempty"` // Only set for calls
+	Read     bool           `json:"read"`
+	Write    bool           `json:"write"` // Whether the contract may be created for creations
+	Reason   string         `json:"reason"`
+	Details  *AccessDetails `json:"details,omitempty"` // Only set for calls whose details the policy could read
+}
+
+// AccessDetails is the state of the AccessRights tables behind a permission
+// check, as read by the access policy in force.
+type AccessDetails struct {
+	Guardianship            *hexutil.Big `json:"guardianship"`                  // Guardianship owning the contract
+	ContributorGuardianship *hexutil.Big `json:"contributorGuardianship"`       // Guardianship of the caller
+	AccessGroup             *hexutil.Big `json:"accessGroup"`                   // Access groups of the caller
+	FunctionAccessGroup     *hexutil.Big `json:"functionAccessGroup,omitempty"` // Access groups allowed to call the function, if restricted
+	Limit                   *hexutil.Big `json:"limit"`                         // Minimum number of blocks between writes of the caller
+	LastBlockNumber         *hexutil.Big `json:"lastBlockNumber"`               // Block of the caller's last write
+	RateLimited             bool         `json:"rateLimited"`                   // Whether the caller has to wait before writing again
+}
+
+// AccessLogger is a tracer recording the permission checks taken while running
+// a transaction, ignoring the execution itself. If it is given a writer, every
+// check is also written to it as a line of JSON.
+type AccessLogger struct {
+	encoder *json.Encoder
+	checks  []AccessCheckLog
+	output  []byte
+	err     error
+}
+
+// NewAccessLogger creates a tracer recording permission checks, streaming them
+// as JSON to writer if it isn't nil.
+func NewAccessLogger(writer io.Writer) *AccessLogger {
+	l := new(AccessLogger)
+	if writer != nil {
+		l.encoder = json.NewEncoder(writer)
+	}
+	return l
+}
+
+// CaptureAccessCheck implements AccessTracer.
+func (l *AccessLogger) CaptureAccessCheck(env *EVM, caller, contract common.Address, selector [4]byte, read, write bool, reason AccessReason) error {
+	details, err := env.accessPolicy.AccessDetails(caller, contract, selector)
+	if err == nil {
+		details.RateLimited = reason == AccessRateLimited
+	}
+
+	return l.capture(AccessCheckLog{
+		Depth:    env.depth,
+		Caller:   caller,
+		Contract: contract,
+		Selector: common.CopyBytes(selector[:]),
+		Read:     read,
+		Write:    write,
+		Reason:   reason.String(),
+		Details:  details,
+	})
+}
+
+// CaptureCreateCheck implements AccessTracer.
+func (l *AccessLogger) CaptureCreateCheck(env *EVM, caller common.Address, allowed bool) error {
+	check := AccessCheckLog{
+		Depth:  env.depth,
+		Create: true,
+		Caller: caller,
+		Read:   allowed,
+		Write:  allowed,
+		Reason: AccessGranted.String(),
+	}
+	if !allowed {
+		check.Reason = AccessCreateDenied.String()
+	}
+	return l.capture(check)
+}
+
+// capture records a permission check.
+func (l *AccessLogger) capture(check AccessCheckLog) error {
+	l.checks = append(l.checks, check)
+	if l.encoder != nil {
+		return l.encoder.Encode(check)
+	}
+	return nil
+}
+
+// CaptureStart implements Tracer.
+func (l *AccessLogger) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState implements Tracer.
+func (l *AccessLogger) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureFault implements Tracer.
+func (l *AccessLogger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements Tracer, recording the outcome of the transaction.
+func (l *AccessLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	l.output = output
+	l.err = err
+	return nil
+}
+
+// AccessChecks returns the permission checks captured so far.
+func (l *AccessLogger) AccessChecks() []AccessCheckLog { return l.checks }
+
+// Error returns the VM error captured by the trace.
+func (l *AccessLogger) Error() error { return l.err }
+
+// Output returns the VM return value captured by the trace.
+func (l *AccessLogger) Output() []byte { return l.output }
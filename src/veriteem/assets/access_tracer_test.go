@@ -0,0 +1,182 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Tests that the access logger records the permission checks of nested calls
+// and creations, along with the table entries behind them as read by the policy
+// in force, and streams them as JSON lines.
+func TestAccessLogger(t *testing.T) {
+	outsider := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	for _, native := range []bool{false, true} {
+		evm, guardian, _ := newAccessTest(t, newAccessTestConfig(allAccessForks(native)), 1, 0)
+
+		private := deployTestContract(t, evm, guardian, testReaderCode())
+		writeContractInfo(t, evm, guardian, private, true, false)
+		proxy := deployTestContract(t, evm, guardian, testProxyCode(CALL, private))
+
+		var (
+			stream   = new(bytes.Buffer)
+			logger   = NewAccessLogger(stream)
+			traced   = newAccessTestEVM(evm.StateDB, evm.ChainConfig(), evm.BlockNumber.Uint64(), Config{Debug: true, Tracer: logger})
+			selector = hexutil.Bytes{0x12, 0x34, 0x56, 0x78}
+		)
+		output, _, err := traced.Call(AccountRef(outsider), proxy, selector, testGas, new(big.Int))
+		if err == nil {
+			t.Fatalf("native %v: call of denied contract through proxy succeeded", native)
+		}
+		if logger.Error() != err || !bytes.Equal(logger.Output(), output) {
+			t.Errorf("native %v: captured outcome %x (%v), want %x (%v)", native, logger.Output(), logger.Error(), output, err)
+		}
+		traced.Create(AccountRef(guardian), testReaderCode(), testGas, new(big.Int))
+		traced.Create(AccountRef(outsider), testReaderCode(), testGas, new(big.Int))
+
+		// The contributor tables know nothing of outsider and proxy
+		details := func() *AccessDetails {
+			return &AccessDetails{
+				Guardianship:            (*hexutil.Big)(big.NewInt(1)),
+				ContributorGuardianship: new(hexutil.Big),
+				AccessGroup:             new(hexutil.Big),
+				Limit:                   new(hexutil.Big),
+				LastBlockNumber:         new(hexutil.Big),
+			}
+		}
+		want := []AccessCheckLog{
+			{Depth: 0, Caller: outsider, Contract: proxy, Selector: selector, Read: true, Reason: AccessNoGuardianship.String(), Details: details()},
+			{Depth: 1, Caller: proxy, Contract: private, Selector: selector, Reason: AccessNoGuardianship.String(), Details: details()},
+			{Depth: 0, Create: true, Caller: guardian, Read: true, Write: true, Reason: AccessGranted.String()},
+			{Depth: 0, Create: true, Caller: outsider, Reason: AccessCreateDenied.String()},
+		}
+		if !native {
+			// The contract doesn't tell why all access was denied
+			want[1].Reason = AccessDenied.String()
+		}
+		// Compare the JSON encodings, which the logger streams line by line
+		var lines [][]byte
+		for _, check := range want {
+			line, _ := json.Marshal(check)
+			lines = append(lines, append(line, '\n'))
+		}
+		have, _ := json.Marshal(logger.AccessChecks())
+		if all, _ := json.Marshal(want); !bytes.Equal(have, all) {
+			t.Fatalf("native %v: captured checks mismatch:\nhave %s\nwant %s", native, have, all)
+		}
+		if streamed := stream.Bytes(); !bytes.Equal(streamed, bytes.Join(lines, nil)) {
+			t.Errorf("native %v: streamed checks mismatch:\nhave %s\nwant %s", native, streamed, bytes.Join(lines, nil))
+		}
+	}
+}
+
+// testFrameTracer is an access logger counting the frames reported to it.
+type testFrameTracer struct {
+	*AccessLogger
+	starts, ends int
+}
+
+// CaptureStart implements Tracer.
+func (t *testFrameTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.starts++
+	return t.AccessLogger.CaptureStart(from, to, create, input, gas, value)
+}
+
+// CaptureEnd implements Tracer.
+func (t *testFrameTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	t.ends++
+	return t.AccessLogger.CaptureEnd(output, gasUsed, d, err)
+}
+
+// Tests that the calls the EVM makes to register a contract and to record a
+// write aren't reported as frames, so that a trace, such as the one served by
+// debug_traceAccess, ends with the outcome of a failed creation rather than
+// that of registering its address.
+func TestAccessLoggerFailedCreation(t *testing.T) {
+	for _, native := range []bool{false, true} {
+		evm, guardian, member := newAccessTest(t, newAccessTestConfig(allAccessForks(native)), 1, 0)
+		counter := deployTestContract(t, evm, guardian, testCounterCode())
+
+		tracer := &testFrameTracer{AccessLogger: NewAccessLogger(nil)}
+		traced := newAccessTestEVM(evm.StateDB, evm.ChainConfig(), 1, Config{Debug: true, Tracer: tracer})
+
+		// PUSH1 0 DUP1 REVERT
+		_, _, _, err := traced.Create(AccountRef(guardian), []byte{0x60, 0x00, 0x80, 0xfd}, testGas, new(big.Int))
+		if err != errExecutionReverted {
+			t.Fatalf("native %v: reverting creation: have %v, want %v", native, err, errExecutionReverted)
+		}
+		if tracer.Error() != err {
+			t.Errorf("native %v: captured error %v, want %v", native, tracer.Error(), err)
+		}
+		// Writes of contributors are recorded for their rate limit
+		if _, _, err := traced.Call(AccountRef(member), counter, nil, testGas, new(big.Int)); err != nil {
+			t.Fatalf("native %v: write by member failed: %v", native, err)
+		}
+		if tracer.starts != 2 || tracer.ends != 2 {
+			t.Errorf("native %v: %d frames started, %d ended, want 2", native, tracer.starts, tracer.ends)
+		}
+	}
+}
+
+// Tests that the details of a check report the table entries behind it, for
+// both policies. The contract doesn't expose the access groups of functions.
+func TestAccessDetails(t *testing.T) {
+	var (
+		limited    = common.HexToAddress("0x00000000000000000000000000000000000000ee")
+		contract   = common.HexToAddress("0x00000000000000000000000000000000000000d1")
+		restricted = [4]byte{0x12, 0x34, 0x56, 0x78}
+	)
+	statedb := newAccessTestState(t)
+	tables := accessTables{statedb, testAccessContract}
+	tables.setContract(contract, testContractEntry{Guardianship: 2, WriteValid: true, GlobalReadValid: true, State: ContractStateActive, Functions: []testFunction{{restricted, 6}}})
+	tables.setContributor(limited, testContributorEntry{Guardianship: 3, Limit: 5, AccessGroup: 4, LastBlockNumber: 8})
+
+	for _, native := range []bool{false, true} {
+		evm := newAccessTestEVM(statedb, newAccessTestConfig(allAccessForks(native)), 10, Config{})
+
+		tests := []struct {
+			selector [4]byte
+			function *big.Int
+		}{
+			{[4]byte{}, nil},
+			{restricted, big.NewInt(6)},
+		}
+		for _, tt := range tests {
+			details, err := evm.accessPolicy.AccessDetails(limited, contract, tt.selector)
+			if err != nil {
+				t.Fatalf("native %v selector %x: failed to read details: %v", native, tt.selector, err)
+			}
+			if details.Guardianship.ToInt().Uint64() != 2 || details.ContributorGuardianship.ToInt().Uint64() != 3 ||
+				details.AccessGroup.ToInt().Uint64() != 4 || details.Limit.ToInt().Uint64() != 5 || details.LastBlockNumber.ToInt().Uint64() != 8 {
+				t.Errorf("native %v selector %x: details mismatch: %+v", native, tt.selector, details)
+			}
+			if !native {
+				tt.function = nil
+			}
+			if have := (*big.Int)(details.FunctionAccessGroup); (have == nil) != (tt.function == nil) || (have != nil && have.Cmp(tt.function) != 0) {
+				t.Errorf("native %v selector %x: function access group %v, want %v", native, tt.selector, have, tt.function)
+			}
+		}
+	}
+}
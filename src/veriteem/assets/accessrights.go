@@ -0,0 +1,953 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package accessrights
+
+import (
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// AccessRightsABI is the input ABI used to generate the binding from.
+const AccessRightsABI = "[{\"constant\":false,\"inputs\":[{\"name\":\"ContractAddress\",\"type\":\"address\"},{\"name\":\"SubGuardianId\",\"type\":\"address\"},{\"name\":\"Index\",\"type\":\"uint256\"}],\"name\":\"WriteContractSubGuardianship\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"GuardianAddress\",\"type\":\"address\"}],\"name\":\"GuardianshipIndex\",\"outputs\":[{\"name\":\"Match\",\"type\":\"bool\"},{\"name\":\"Index\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"Name\",\"type\":\"string\"}],\"name\":\"WriteGuardianshipName\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"GuardianAddress\",\"type\":\"address\"},{\"name\":\"ListIndex\",\"type\":\"uint256\"}],\"name\":\"ReadGuardianshipContributor\",\"outputs\":[{\"name\":\"RetContributorAddress\",\"type\":\"address\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"GuardianAddress\",\"type\":\"address\"}],\"name\":\"ReadGuardianship\",\"outputs\":[{\"name\":\"Match\",\"type\":\"bool\"},{\"name\":\"GIndex\",\"type\":\"uint256\"},{\"name\":\"GuardianA\",\"type\":\"address\"},{\"name\":\"GuardianB\",\"type\":\"address\"},{\"name\":\"Name\",\"type\":\"string\"},{\"name\":\"ContractListLength\",\"type\":\"uint256\"},{\"name\":\"ContributorListLength\",\"type\":\"uint256\"},{\"name\":\"AddVote\",\"type\":\"address\"},{\"name\":\"RemoveVote\",\"type\":\"address\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"ContributorAddress\",\"type\":\"address\"},{\"name\":\"Name\",\"type\":\"string\"},{\"name\":\"Limit\",\"type\":\"uint256\"},{\"name\":\"AccessGroup\",\"type\":\"uint256\"}],\"name\":\"WriteContributor\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"NewGuardianAddress\",\"type\":\"address\"}],\"name\":\"WriteGuardian\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"ContractAddress\",\"type\":\"address\"},{\"name\":\"Index\",\"type\":\"uint256\"}],\"name\":\"ReadContractSubGuardianship\",\"outputs\":[{\"name\":\"SubGuardianship\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"ContractId\",\"type\":\"address\"},{\"name\":\"FunctionAddress\",\"type\":\"address\"}],\"name\":\"VerifyContractAccess\",\"outputs\":[{\"name\":\"ReadApproved\",\"type\":\"bool\"},{\"name\":\"WriteApproved\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"VoteId\",\"type\":\"address\"},{\"name\":\"AddVote\",\"type\":\"bool\"}],\"name\":\"GuardianshipVote\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"ContributorAddress\",\"type\":\"address\"},{\"name\":\"Name\",\"type\":\"string\"},{\"name\":\"Limit\",\"type\":\"uint256\"},{\"name\":\"AccessGroup\",\"type\":\"uint256\"}],\"name\":\"CreateContributor\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"ContractAddress\",\"type\":\"address\"},{\"name\":\"Name\",\"type\":\"string\"},{\"name\":\"NewAddress\",\"type\":\"address\"},{\"name\":\"WriteValid\",\"type\":\"bool\"},{\"name\":\"GlobalReadValid\",\"type\":\"bool\"},{\"name\":\"State\",\"type\":\"uint256\"}],\"name\":\"WriteContractInfo\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"ContributorAddress\",\"type\":\"address\"}],\"name\":\"DeleteContributor\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"ContractId\",\"type\":\"address\"},{\"name\":\"Guardianship\",\"type\":\"uint256\"}],\"name\":\"VerifyContractSubGuardianshipList\",\"outputs\":[{\"name\":\"Match\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"ContractAddress\",\"type\":\"address\"}],\"name\":\"ReadContractInfo\",\"outputs\":[{\"name\":\"Guardianship\",\"type\":\"uint256\"},{\"name\":\"Name\",\"type\":\"string\"},{\"name\":\"NewAddress\",\"type\":\"address\"},{\"name\":\"WriteValid\",\"type\":\"bool\"},{\"name\":\"GlobalReadValid\",\"type\":\"bool\"},{\"name\":\"State\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"Contract\",\"type\":\"address\"},{\"name\":\"FunctionIndex\",\"type\":\"uint256\"},{\"name\":\"FunctionAddress\",\"type\":\"address\"},{\"name\":\"AccessGroup\",\"type\":\"uint256\"}],\"name\":\"WriteContractFunctionIndex\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"Contract\",\"type\":\"address\"},{\"name\":\"FunctionIndex\",\"type\":\"uint256\"}],\"name\":\"ReadContractFunctionIndex\",\"outputs\":[{\"name\":\"FunctionAddress\",\"type\":\"address\"},{\"name\":\"AccessGroup\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"Index\",\"type\":\"uint256\"}],\"name\":\"ReadGuardianshipIndex\",\"outputs\":[{\"name\":\"GuardianA\",\"type\":\"address\"},{\"name\":\"GuardianB\",\"type\":\"address\"},{\"name\":\"Name\",\"type\":\"string\"},{\"name\":\"ContractListLength\",\"type\":\"uint256\"},{\"name\":\"ContributorListLength\",\"type\":\"uint256\"},{\"name\":\"AddVote\",\"type\":\"address\"},{\"name\":\"RemoveVote\",\"type\":\"address\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[],\"name\":\"UpdateContributorBlock\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[],\"name\":\"InitGuardianship\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"GuardianAddress\",\"type\":\"address\"},{\"name\":\"ListIndex\",\"type\":\"uint256\"}],\"name\":\"ReadGuardianshipContract\",\"outputs\":[{\"name\":\"RetContractAddress\",\"type\":\"address\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"Contract\",\"type\":\"address\"},{\"name\":\"FunctionAddress\",\"type\":\"address\"}],\"name\":\"ReadContractFunctionAccessGroup\",\"outputs\":[{\"name\":\"Match\",\"type\":\"bool\"},{\"name\":\"AccessGroup\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"ContributorAddress\",\"type\":\"address\"}],\"name\":\"ReadContributor\",\"outputs\":[{\"name\":\"Guardianship\",\"type\":\"uint256\"},{\"name\":\"Name\",\"type\":\"string\"},{\"name\":\"Limit\",\"type\":\"uint256\"},{\"name\":\"LastBlockNumber\",\"type\":\"uint256\"},{\"name\":\"AccessGroup\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"NewGuardian\",\"type\":\"address\"}],\"name\":\"GuardianshipVoteStats\",\"outputs\":[{\"name\":\"AddAgreeCount\",\"type\":\"uint256\"},{\"name\":\"AddDisagreeCount\",\"type\":\"uint256\"},{\"name\":\"RemoveAgreeCount\",\"type\":\"uint256\"},{\"name\":\"RemoveDisagreeCount\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"ContractAddress\",\"type\":\"address\"}],\"name\":\"CreateContract\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+
+// AccessRightsBin is the compiled bytecode used for deploying new contracts.
+const AccessRightsBin = `608060405234801561001057600080fd5b50612521806100206000396000f3006080604052600436106101485763ffffffff7c01000000000000000000000000000000000000000000000000000000006000350416630af346d4811461014d57806310bd7fc5146101795780631a1a84de146101b55780631dd8de6a1461020e578063271c30901461024e578063275b19fd1461036b5780632d7e44e6146103db578063334546ef146103fc57806345e4e5e4146104325780635486491f146104745780636615df5e1461049a57806372ccdfe11461050a578063735b89a01461058f5780637591495f146105b057806376599917146105e85780639cb6d8a3146106b3578063abc24275146106e1578063cd2e6e0f14610728578063cf0d1ea01461082a578063d36bef801461083f578063d85c4a9314610854578063e41c135b14610878578063e7bf14c11461089f578063ed84545214610954578063f5b7f3f61461099b575b600080fd5b34801561015957600080fd5b50610177600160a060020a03600435811690602435166044356109bc565b005b34801561018557600080fd5b5061019a600160a060020a0360043516610a4c565b60408051921515835260208301919091528051918290030190f35b3480156101c157600080fd5b506040805160206004803580820135601f8101849004840285018401909552848452610177943694929360249392840191908190840183828082843750949750610b8f9650505050505050565b34801561021a57600080fd5b50610232600160a060020a0360043516602435610bdb565b60408051600160a060020a039092168252519081900360200190f35b34801561025a57600080fd5b5061026f600160a060020a0360043516610c3b565b604051808a15151515815260200189815260200188600160a060020a0316600160a060020a0316815260200187600160a060020a0316600160a060020a031681526020018060200186815260200185815260200184600160a060020a0316600160a060020a0316815260200183600160a060020a0316600160a060020a03168152602001828103825287818151815260200191508051906020019080838360005b83811015610328578181015183820152602001610310565b50505050905090810190601f1680156103555780820380516001836020036101000a031916815260200191505b509a505050505050505050505060405180910390f35b34801561037757600080fd5b5060408051602060046024803582810135601f8101859004850286018501909652858552610177958335600160a060020a0316953695604494919390910191908190840183828082843750949750508435955050506020909201359150610e1d9050565b3480156103e757600080fd5b50610177600160a060020a0360043516610ebf565b34801561040857600080fd5b50610420600160a060020a0360043516602435610fce565b60408051918252519081900360200190f35b34801561043e57600080fd5b50610459600160a060020a0360043581169060243516610ffd565b60408051921515835290151560208301528051918290030190f35b34801561048057600080fd5b50610177600160a060020a036004351660243515156111fe565b3480156104a657600080fd5b5060408051602060046024803582810135601f8101859004850286018501909652858552610177958335600160a060020a031695369560449491939091019190819084018382808284375094975050843595505050602090920135915061168f9050565b34801561051657600080fd5b5060408051602060046024803582810135601f8101859004850286018501909652858552610177958335600160a060020a031695369560449491939091019190819084018382808284375094975050600160a060020a038535169550505050506020810135151590604081013515159060600135611808565b34801561059b57600080fd5b50610177600160a060020a03600435166118fd565b3480156105bc57600080fd5b506105d4600160a060020a0360043516602435611a84565b604080519115158252519081900360200190f35b3480156105f457600080fd5b50610609600160a060020a0360043516611bb8565b60408051878152600160a060020a038616918101919091528315156060820152821515608082015260a0810182905260c06020808301828152885192840192909252875160e084019189019080838360005b8381101561067357818101518382015260200161065b565b50505050905090810190601f1680156106a05780820380516001836020036101000a031916815260200191505b5097505050505050505060405180910390f35b3480156106bf57600080fd5b50610177600160a060020a036004358116906024359060443516606435611cb5565b3480156106ed57600080fd5b50610705600160a060020a0360043516602435611d66565b60408051600160a060020a03909316835260208301919091528051918290030190f35b34801561073457600080fd5b50610740600435611dc6565b6040518088600160a060020a0316600160a060020a0316815260200187600160a060020a0316600160a060020a031681526020018060200186815260200185815260200184600160a060020a0316600160a060020a0316815260200183600160a060020a0316600160a060020a03168152602001828103825287818151815260200191508051906020019080838360005b838110156107e95781810151838201526020016107d1565b50505050905090810190601f1680156108165780820380516001836020036101000a031916815260200191505b509850505050505050505060405180910390f35b34801561083657600080fd5b50610177611f51565b34801561084b57600080fd5b50610177611f6a565b34801561086057600080fd5b50610232600160a060020a0360043516602435611f90565b34801561088457600080fd5b5061019a600160a060020a0360043581169060243516611fd0565b3480156108ab57600080fd5b506108c0600160a060020a036004351661209d565b6040518086815260200180602001858152602001848152602001838152602001828103825286818151815260200191508051906020019080838360005b838110156109155781810151838201526020016108fd565b50505050905090810190601f1680156109425780820380516001836020036101000a031916815260200191505b50965050505050505060405180910390f35b34801561096057600080fd5b50610975600160a060020a0360043516612187565b604080519485526020850193909352838301919091526060830152519081900360800190f35b3480156109a757600080fd5b50610177600160a060020a0360043516612292565b6000806109c833610a4c565b90925090508115156109d957610a45565b600160a060020a0385166000908152602081905260409020600101548114610a0057610a45565b610a0984610a4c565b9092509050811515610a1a57610a45565b600160a060020a038516600090815260208190526040902081906004018460148110610a4257fe5b01555b5050505050565b60008060015b6014811015610b8957600160a060020a0384161515610af957600160a060020a03841660018260148110610a8257fe5b600702016001016000600281101515610a9757fe5b0154600160a060020a0316148015610ae35750600160a060020a03841660018260148110610ac157fe5b600702016001016001600281101515610ad657fe5b0154600160a060020a0316145b15610af45760019250809150610b89565b610b81565b600160a060020a03841660018260148110610b1057fe5b600702016001016000600281101515610b2557fe5b0154600160a060020a03161480610b705750600160a060020a03841660018260148110610b4e57fe5b600702016001016001600281101515610b6357fe5b0154600160a060020a0316145b15610b815760019250809150610b89565b600101610a52565b50915091565b600080610b9b33610a4c565b9092509050811515610bac57610bd6565b8260018260148110610bba57fe5b600702016000019080519060200190610bd492919061245a565b505b505050565b6000806000610be985610a4c565b9092509050811515610bfa57610c33565b60018160148110610c0757fe5b6007020160040184815481101515610c1b57fe5b600091825260209091200154600160a060020a031692505b505092915050565b60408051808201909152600481527f4e554c4c0000000000000000000000000000000000000000000000000000000060208201526000908190819081908180808080610c868b610a4c565b909a509050891515610c9757610e0f565b97508760018160148110610ca757fe5b600702016001016000600281101515610cbc57fe5b0154600160a060020a0316975060018160148110610cd657fe5b600702016001016001600281101515610ceb57fe5b0154600160a060020a0316965060018160148110610d0557fe5b60070201805460408051602060026001851615610100026000190190941693909304601f81018490048402820184019092528181529291830182828015610d8d5780601f10610d6257610100808354040283529160200191610d8d565b820191906000526020600020905b815481529060010190602001808311610d7057829003601f168201915b50505050509550600181601481101515610da357fe5b60070201600301805490509450600181601481101515610dbf57fe5b60070201600401805490509350600181601481101515610ddb57fe5b6007020160050154600160a060020a0316925060018160148110610dfb57fe5b6007020160060154600160a060020a031691505b509193959799909294969850565b600080610e2933610a4c565b9092509050811515610e3a57610eb7565b600160a060020a0386166000908152608d60205260409020600101548114610e6157610eb7565b600160a060020a0386166000908152608d602090815260409091208651610e8a9288019061245a565b50600160a060020a0386166000908152608d60205260409020600281018590554360048201556003018390555b505050505050565b600080610ecb33610a4c565b9092509050811515610edc57610bd6565b3360018260148110610eea57fe5b600702016001016000600281101515610eff57fe5b0154600160a060020a03161415610f53578260018260148110610f1e57fe5b600702016001016001600281101515610f3357fe5b018054600160a060020a031916600160a060020a03929092169190911790555b3360018260148110610f6157fe5b600702016001016001600281101515610f7657fe5b0154600160a060020a03161415610bd6578260018260148110610f9557fe5b600702016001016000600281101515610faa57fe5b018054600160a060020a031916600160a060020a0392909216919091179055505050565b600160a060020a03821660009081526020819052604081206004018260148110610ff457fe5b01549392505050565b600160a060020a0382166000908152602081905260408120600190810154829182918291906014811061102c57fe5b60070201600101600060028110151561104157fe5b0154600160a060020a031615801561109e5750600160a060020a03861660009081526020819052604090206001908101546014811061107c57fe5b60070201600101600160028110151561109157fe5b0154600160a060020a0316155b156110a8576111f5565b6110b28686611fd0565b90925090508180156110d65750336000908152608d60205260409020600301548116155b156110e0576111f5565b600160a060020a03861660009081526020818152604080832060020154338452608d9092529091206001015460a860020a90910460ff169450611124908790611a84565b156111f557600160a060020a03861660009081526020819052604090206002015460a860020a900460ff161561115957600193505b336000908152608d602052604081206002015411156111cc57336000908152608d602052604090206002810154600490910154439101111561119e57600092506111c7565b600160a060020a03861660009081526020819052604090206002015460a060020a900460ff1692505b6111f5565b600160a060020a03861660009081526020819052604090206002015460a060020a900460ff1692505b50509250929050565b60008060008060008060008060008061121633610a4c565b909a50985089151561122757611681565b8a1561126b578b60018a6014811061123b57fe5b6007020160050160006101000a815481600160a060020a030219169083600160a060020a031602179055506112a5565b8b60018a6014811061127957fe5b6007020160060160006101000a815481600160a060020a030219169083600160a060020a031602179055505b600160a060020a038c1615156112ba57611681565b6112c38c612187565b9298509096509450925084861180156112d957508a5b806112ec575082841180156112ec57508a155b15611681578a1561130b576113016000610a4c565b909a50965061131a565b6113148c610a4c565b909a5096505b8915611681578a156113b0578b6001886014811061133457fe5b60070201600101600060028110151561134957fe5b018054600160a060020a031916600160a060020a039290921691909117905560006001886014811061137757fe5b60070201600101600160028110151561138c57fe5b018054600160a060020a031916600160a060020a0392909216919091179055611681565b6000600188601481106113bf57fe5b6007020160010160006002811015156113d457fe5b018054600160a060020a031916600160a060020a039290921691909117905560006001886014811061140257fe5b60070201600101600160028110151561141757fe5b018054600160a060020a031916600160a060020a03929092169190911790556040805160208101909152600081526001886014811061145257fe5b60070201600001908051906020019061146c92919061245a565b5060006001886014811061147c57fe5b6007020160050160006101000a815481600160a060020a030219169083600160a060020a0316021790555060006001886014811015156114b857fe5b6007020160060160006101000a815481600160a060020a030219169083600160a060020a03160217905550600097505b600187601481106114f557fe5b60070201600301805490508810156115bb576001876014811061151457fe5b600702016003018881548110151561152857fe5b600091825260208083209091015460408051808401808352858252600160a060020a039093168086529385905293209251919450611566929161245a565b50600160a060020a0382166000908152602081905260408120600180820183905560028201805475ffffffffffffffffffffffffffffffffffffffffffff1916905560039091019190915597909701966114e8565b600097505b600187601481106115cd57fe5b600702016004018054905088101561168157600187601481106115ec57fe5b600702016004018881548110151561160057fe5b600091825260208083209091015460408051808401808352858252600160a060020a03909316808652608d9094529320925191935061163f929161245a565b50600160a060020a0381166000908152608d602052604081206001808201839055600282018390556003820183905560049091019190915597909701966115c0565b505050505050505050505050565b600080600061169d33610a4c565b90935091508215156116ae576117ff565b600160a060020a0387166000908152608d6020526040902060010154158015906116f35750600160a060020a0387166000908152608d60205260409020600101548214155b156116fd576117ff565b5060005b6001826014811061170e57fe5b600702016004018054905081101561177357600160a060020a0387166001836014811061173757fe5b600702016004018281548110151561174b57fe5b600091825260209091200154600160a060020a0316141561176b576117ff565b600101611701565b6001826014811061178057fe5b600702016004018054600181018255600091825260208083209091018054600160a060020a031916600160a060020a038b169081179091558252608d8152604090912087516117d19289019061245a565b50600160a060020a0387166000908152608d6020526040902060028101869055600181018390556003018490555b50505050505050565b60008061181433610a4c565b9092509050811515611825576118f3565b600160a060020a038816600090815260208190526040902060010154811461184c576118f3565b600160a060020a0388166000908152602081815260409091208851611873928a019061245a565b50600160a060020a038881166000908152602081905260409020600281018054600160a060020a0319169289169290921774ff0000000000000000000000000000000000000000191660a060020a881515021775ff000000000000000000000000000000000000000000191660a860020a87151502179091556003018390555b5050505050505050565b600080600061190b33610a4c565b909350915082151561191c57610bd4565b600160a060020a0384166000908152608d6020526040902060010154158015906119615750600160a060020a0384166000908152608d60205260409020600101548214155b1561196b57610bd4565b5060005b6001826014811061197c57fe5b6007020160040180549050811015610bd457600160a060020a038416600183601481106119a557fe5b60070201600401828154811015156119b957fe5b600091825260209091200154600160a060020a03161415611a7c576000600183601481106119e357fe5b60070201600401828154811015156119f757fe5b60009182526020808320919091018054600160a060020a031916600160a060020a03948516179055604080518083018083528482529489168452608d9092529091209051611a45929061245a565b50600160a060020a0384166000908152608d6020526040812060028101829055600181018290556004810182905560030155610bd4565b60010161196f565b600080821515611a9357611bb1565b600160a060020a038416600090815260208190526040902060019081015460148110611abb57fe5b600702016001016000600281101515611ad057fe5b0154600160a060020a0316158015611b2d5750600160a060020a038416600090815260208190526040902060019081015460148110611b0b57fe5b600702016001016001600281101515611b2057fe5b0154600160a060020a0316155b15611b3757611bb1565b600160a060020a038416600090815260208190526040902060010154831415611b635760019150611bb1565b5060005b6014811015611bb157600160a060020a038416600090815260208190526040902083906004018260148110611b9857fe5b01541415611ba95760019150611bb1565b600101611b67565b5092915050565b600160a060020a03811660009081526020818152604080832060018082015482548451600293821615610100026000190190911692909204601f8101869004860283018601909452838252946060949093849384938493909290830182828015611c635780601f10611c3857610100808354040283529160200191611c63565b820191906000526020600020905b815481529060010190602001808311611c4657829003601f168201915b50505050600160a060020a0398891660009081526020819052604090206002810154600390910154989a929981169860ff60a060020a83048116995060a860020a909204909116965094509092505050565b600080611cc133610a4c565b9092509050811515611cd257610eb7565b600160a060020a0386166000908152602081905260409020600101548114611cf957610eb7565b600160a060020a038616600090815260208190526040902084906018018660328110611d2157fe5b018054600160a060020a031916600160a060020a03928316179055861660009081526020819052604090208390604a018660328110611d5c57fe5b0155505050505050565b600160a060020a038216600090815260208190526040812081906018018360328110611d8e57fe5b0154600160a060020a03858116600090815260208190526040902091169250604a018360328110611dbb57fe5b015490509250929050565b60008060608180808060018860148110611ddc57fe5b600702016001016000600281101515611df157fe5b0154600160a060020a0316965060018860148110611e0b57fe5b600702016001016001600281101515611e2057fe5b0154600160a060020a0316955060018860148110611e3a57fe5b60070201805460408051602060026001851615610100026000190190941693909304601f81018490048402820184019092528181529291830182828015611ec25780601f10611e9757610100808354040283529160200191611ec2565b820191906000526020600020905b815481529060010190602001808311611ea557829003601f168201915b50505050509450600188601481101515611ed857fe5b60070201600301805490509350600188601481101515611ef457fe5b60070201600401805490509250600188601481101515611f1057fe5b6007020160050154600160a060020a0316915060018860148110611f3057fe5b600702016006015496989597509395929491935091600160a060020a031690565b336000908152608d60205260409020436004909101555b565b600954600160a060020a03161515611f685760098054600160a060020a03191633179055565b6000806000611f9e85610a4c565b9092509050811515611faf57610c33565b60018160148110611fbc57fe5b6007020160030184815481101515610c1b57fe5b600063ffffffff815b603281101561209557600160a060020a0385166000908152602081905260409020601801816032811061200857fe5b0154600160a060020a0316151561201e57612095565b600160a060020a03858116600090815260208190526040902090851690601801826032811061204957fe5b0154600160a060020a0316141561208d57600160a060020a0385166000908152602081905260409020604a01816032811061208057fe5b0154915060019250612095565b600101611fd9565b509250929050565b600160a060020a0381166000908152608d6020908152604080832060018082015482548451600293821615610100026000190190911692909204601f810186900486028301860190945283825294606094909384938493929091908301828280156121495780601f1061211e57610100808354040283529160200191612149565b820191906000526020600020905b81548152906001019060200180831161212c57829003601f168201915b50505050600160a060020a03979097166000908152608d60205260409020600281015460048201546003909201549799929850969095509350915050565b600080808060015b601481101561228a57600181601481106121a557fe5b6007020160010160006002811015156121ba57fe5b0154600160a060020a03161515806121fd5750600181601481106121da57fe5b6007020160010160016002811015156121ef57fe5b0154600160a060020a031615155b1561228257600160a060020a0386166001826014811061221957fe5b6007020160050154600160a060020a0316141561223b57846001019450612242565b8360010193505b600160a060020a0386166001826014811061225957fe5b6007020160060154600160a060020a0316141561227b57826001019250612282565b8160010191505b60010161218f565b509193509193565b60008060006122a033610a4c565b90935091508215156122b157610bd4565b600160a060020a038416600090815260208190526040902060010154158015906122f65750600160a060020a0384166000908152602081905260409020600101548214155b1561230057610bd4565b5060005b6001826014811061231157fe5b600702016003018054905081101561237657600160a060020a0384166001836014811061233a57fe5b600702016003018281548110151561234e57fe5b600091825260209091200154600160a060020a0316141561236e57610bd4565b600101612304565b6001826014811061238357fe5b60070201600301805460018082018355600092835260208084209092018054600160a060020a031916600160a060020a03891690811790915580845283835260408085209283018790558051808501918290528581529185529390925290516123ec929061245a565b50600160a060020a038416600090815260208190526040902060028101805475ff0000000000000000000000000000000000000000001974ffffffffffffffffffffffffffffffffffffffffff1990911660a060020a171660a860020a179055600160039091015550505050565b828054600181600116156101000203166002900490600052602060002090601f016020900481019282601f1061249b57805160ff19168380011785556124c8565b828001600101855582156124c8579182015b828111156124c85782518255916020019190600101906124ad565b506124d49291506124d8565b5090565b6124f291905b808211156124d457600081556001016124de565b905600a165627a7a72305820af2cba311c1c69ec2fd1166fb921b7992615e5276165140128be9ce6df4965b20029`
+
+// DeployAccessRights deploys a new Ethereum contract, binding an instance of AccessRights to it.
+func DeployAccessRights(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *AccessRights, error) {
+	parsed, err := abi.JSON(strings.NewReader(AccessRightsABI))
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	address, tx, contract, err := bind.DeployContract(auth, parsed, common.FromHex(AccessRightsBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &AccessRights{{AccessRightsCaller: AccessRightsCaller{contract: contract}, AccessRightsTransactor: AccessRightsTransactor{contract: contract}, AccessRightsFilterer: AccessRightsFilterer{contract: contract}}}, nil
+}
+
+// AccessRights is an auto generated Go binding around an Ethereum contract.
+type AccessRights struct {
+	AccessRightsCaller     // Read-only binding to the contract
+	AccessRightsTransactor // Write-only binding to the contract
+	AccessRightsFilterer   // Log filterer for contract events
+}
+
+// AccessRightsCaller is an auto generated read-only Go binding around an Ethereum contract.
+type AccessRightsCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// AccessRightsTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type AccessRightsTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// AccessRightsFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type AccessRightsFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// AccessRightsSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type AccessRightsSession struct {
+	Contract     *AccessRights     // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// AccessRightsCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type AccessRightsCallerSession struct {
+	Contract *AccessRightsCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts       // Call options to use throughout this session
+}
+
+// AccessRightsTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type AccessRightsTransactorSession struct {
+	Contract     *AccessRightsTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts       // Transaction auth options to use throughout this session
+}
+
+// AccessRightsRaw is an auto generated low-level Go binding around an Ethereum contract.
+type AccessRightsRaw struct {
+	Contract *AccessRights // Generic contract binding to access the raw methods on
+}
+
+// AccessRightsCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type AccessRightsCallerRaw struct {
+	Contract *AccessRightsCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// AccessRightsTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type AccessRightsTransactorRaw struct {
+	Contract *AccessRightsTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewAccessRights creates a new instance of AccessRights, bound to a specific deployed contract.
+func NewAccessRights(address common.Address, backend bind.ContractBackend) (*AccessRights, error) {
+	contract, err := bindAccessRights(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessRights{{AccessRightsCaller: AccessRightsCaller{contract: contract}, AccessRightsTransactor: AccessRightsTransactor{contract: contract}, AccessRightsFilterer: AccessRightsFilterer{contract: contract}}}, nil
+}
+
+// NewAccessRightsCaller creates a new read-only instance of AccessRights, bound to a specific deployed contract.
+func NewAccessRightsCaller(address common.Address, caller bind.ContractCaller) (*AccessRightsCaller, error) {
+	contract, err := bindAccessRights(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessRightsCaller{contract: contract}, nil
+}
+
+// NewAccessRightsTransactor creates a new write-only instance of AccessRights, bound to a specific deployed contract.
+func NewAccessRightsTransactor(address common.Address, transactor bind.ContractTransactor) (*AccessRightsTransactor, error) {
+	contract, err := bindAccessRights(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessRightsTransactor{contract: contract}, nil
+}
+
+// NewAccessRightsFilterer creates a new log filterer instance of AccessRights, bound to a specific deployed contract.
+func NewAccessRightsFilterer(address common.Address, filterer bind.ContractFilterer) (*AccessRightsFilterer, error) {
+	contract, err := bindAccessRights(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessRightsFilterer{contract: contract}, nil
+}
+
+// bindAccessRights binds a generic wrapper to an already deployed contract.
+func bindAccessRights(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(AccessRightsABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_AccessRights *AccessRightsRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _AccessRights.Contract.AccessRightsCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_AccessRights *AccessRightsRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _AccessRights.Contract.AccessRightsTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_AccessRights *AccessRightsRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _AccessRights.Contract.AccessRightsTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_AccessRights *AccessRightsCallerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _AccessRights.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_AccessRights *AccessRightsTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _AccessRights.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_AccessRights *AccessRightsTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _AccessRights.Contract.contract.Transact(opts, method, params...)
+}
+
+// GuardianshipIndex is a free data retrieval call binding the contract method 0x10bd7fc5.
+//
+// Solidity: function GuardianshipIndex(GuardianAddress address) constant returns(Match bool, Index uint256)
+func (_AccessRights *AccessRightsCaller) GuardianshipIndex(opts *bind.CallOpts, GuardianAddress common.Address) (struct {
+	Match bool
+	Index *big.Int
+}, error) {
+	ret := new(struct {
+		Match bool
+		Index *big.Int
+	})
+	out := ret
+	err := _AccessRights.contract.Call(opts, out, "GuardianshipIndex", GuardianAddress)
+	return *ret, err
+}
+
+// GuardianshipIndex is a free data retrieval call binding the contract method 0x10bd7fc5.
+//
+// Solidity: function GuardianshipIndex(GuardianAddress address) constant returns(Match bool, Index uint256)
+func (_AccessRights *AccessRightsSession) GuardianshipIndex(GuardianAddress common.Address) (struct {
+	Match bool
+	Index *big.Int
+}, error) {
+	return _AccessRights.Contract.GuardianshipIndex(&_AccessRights.CallOpts, GuardianAddress)
+}
+
+// GuardianshipIndex is a free data retrieval call binding the contract method 0x10bd7fc5.
+//
+// Solidity: function GuardianshipIndex(GuardianAddress address) constant returns(Match bool, Index uint256)
+func (_AccessRights *AccessRightsCallerSession) GuardianshipIndex(GuardianAddress common.Address) (struct {
+	Match bool
+	Index *big.Int
+}, error) {
+	return _AccessRights.Contract.GuardianshipIndex(&_AccessRights.CallOpts, GuardianAddress)
+}
+
+// GuardianshipVoteStats is a free data retrieval call binding the contract method 0xed845452.
+//
+// Solidity: function GuardianshipVoteStats(NewGuardian address) constant returns(AddAgreeCount uint256, AddDisagreeCount uint256, RemoveAgreeCount uint256, RemoveDisagreeCount uint256)
+func (_AccessRights *AccessRightsCaller) GuardianshipVoteStats(opts *bind.CallOpts, NewGuardian common.Address) (struct {
+	AddAgreeCount       *big.Int
+	AddDisagreeCount    *big.Int
+	RemoveAgreeCount    *big.Int
+	RemoveDisagreeCount *big.Int
+}, error) {
+	ret := new(struct {
+		AddAgreeCount       *big.Int
+		AddDisagreeCount    *big.Int
+		RemoveAgreeCount    *big.Int
+		RemoveDisagreeCount *big.Int
+	})
+	out := ret
+	err := _AccessRights.contract.Call(opts, out, "GuardianshipVoteStats", NewGuardian)
+	return *ret, err
+}
+
+// GuardianshipVoteStats is a free data retrieval call binding the contract method 0xed845452.
+//
+// Solidity: function GuardianshipVoteStats(NewGuardian address) constant returns(AddAgreeCount uint256, AddDisagreeCount uint256, RemoveAgreeCount uint256, RemoveDisagreeCount uint256)
+func (_AccessRights *AccessRightsSession) GuardianshipVoteStats(NewGuardian common.Address) (struct {
+	AddAgreeCount       *big.Int
+	AddDisagreeCount    *big.Int
+	RemoveAgreeCount    *big.Int
+	RemoveDisagreeCount *big.Int
+}, error) {
+	return _AccessRights.Contract.GuardianshipVoteStats(&_AccessRights.CallOpts, NewGuardian)
+}
+
+// GuardianshipVoteStats is a free data retrieval call binding the contract method 0xed845452.
+//
+// Solidity: function GuardianshipVoteStats(NewGuardian address) constant returns(AddAgreeCount uint256, AddDisagreeCount uint256, RemoveAgreeCount uint256, RemoveDisagreeCount uint256)
+func (_AccessRights *AccessRightsCallerSession) GuardianshipVoteStats(NewGuardian common.Address) (struct {
+	AddAgreeCount       *big.Int
+	AddDisagreeCount    *big.Int
+	RemoveAgreeCount    *big.Int
+	RemoveDisagreeCount *big.Int
+}, error) {
+	return _AccessRights.Contract.GuardianshipVoteStats(&_AccessRights.CallOpts, NewGuardian)
+}
+
+// ReadContractFunctionAccessGroup is a free data retrieval call binding the contract method 0xe41c135b.
+//
+// Solidity: function ReadContractFunctionAccessGroup(Contract address, FunctionAddress address) constant returns(Match bool, AccessGroup uint256)
+func (_AccessRights *AccessRightsCaller) ReadContractFunctionAccessGroup(opts *bind.CallOpts, Contract common.Address, FunctionAddress common.Address) (struct {
+	Match       bool
+	AccessGroup *big.Int
+}, error) {
+	ret := new(struct {
+		Match       bool
+		AccessGroup *big.Int
+	})
+	out := ret
+	err := _AccessRights.contract.Call(opts, out, "ReadContractFunctionAccessGroup", Contract, FunctionAddress)
+	return *ret, err
+}
+
+// ReadContractFunctionAccessGroup is a free data retrieval call binding the contract method 0xe41c135b.
+//
+// Solidity: function ReadContractFunctionAccessGroup(Contract address, FunctionAddress address) constant returns(Match bool, AccessGroup uint256)
+func (_AccessRights *AccessRightsSession) ReadContractFunctionAccessGroup(Contract common.Address, FunctionAddress common.Address) (struct {
+	Match       bool
+	AccessGroup *big.Int
+}, error) {
+	return _AccessRights.Contract.ReadContractFunctionAccessGroup(&_AccessRights.CallOpts, Contract, FunctionAddress)
+}
+
+// ReadContractFunctionAccessGroup is a free data retrieval call binding the contract method 0xe41c135b.
+//
+// Solidity: function ReadContractFunctionAccessGroup(Contract address, FunctionAddress address) constant returns(Match bool, AccessGroup uint256)
+func (_AccessRights *AccessRightsCallerSession) ReadContractFunctionAccessGroup(Contract common.Address, FunctionAddress common.Address) (struct {
+	Match       bool
+	AccessGroup *big.Int
+}, error) {
+	return _AccessRights.Contract.ReadContractFunctionAccessGroup(&_AccessRights.CallOpts, Contract, FunctionAddress)
+}
+
+// ReadContractFunctionIndex is a free data retrieval call binding the contract method 0xabc24275.
+//
+// Solidity: function ReadContractFunctionIndex(Contract address, FunctionIndex uint256) constant returns(FunctionAddress address, AccessGroup uint256)
+func (_AccessRights *AccessRightsCaller) ReadContractFunctionIndex(opts *bind.CallOpts, Contract common.Address, FunctionIndex *big.Int) (struct {
+	FunctionAddress common.Address
+	AccessGroup     *big.Int
+}, error) {
+	ret := new(struct {
+		FunctionAddress common.Address
+		AccessGroup     *big.Int
+	})
+	out := ret
+	err := _AccessRights.contract.Call(opts, out, "ReadContractFunctionIndex", Contract, FunctionIndex)
+	return *ret, err
+}
+
+// ReadContractFunctionIndex is a free data retrieval call binding the contract method 0xabc24275.
+//
+// Solidity: function ReadContractFunctionIndex(Contract address, FunctionIndex uint256) constant returns(FunctionAddress address, AccessGroup uint256)
+func (_AccessRights *AccessRightsSession) ReadContractFunctionIndex(Contract common.Address, FunctionIndex *big.Int) (struct {
+	FunctionAddress common.Address
+	AccessGroup     *big.Int
+}, error) {
+	return _AccessRights.Contract.ReadContractFunctionIndex(&_AccessRights.CallOpts, Contract, FunctionIndex)
+}
+
+// ReadContractFunctionIndex is a free data retrieval call binding the contract method 0xabc24275.
+//
+// Solidity: function ReadContractFunctionIndex(Contract address, FunctionIndex uint256) constant returns(FunctionAddress address, AccessGroup uint256)
+func (_AccessRights *AccessRightsCallerSession) ReadContractFunctionIndex(Contract common.Address, FunctionIndex *big.Int) (struct {
+	FunctionAddress common.Address
+	AccessGroup     *big.Int
+}, error) {
+	return _AccessRights.Contract.ReadContractFunctionIndex(&_AccessRights.CallOpts, Contract, FunctionIndex)
+}
+
+// ReadContractInfo is a free data retrieval call binding the contract method 0x76599917.
+//
+// Solidity: function ReadContractInfo(ContractAddress address) constant returns(Guardianship uint256, Name string, NewAddress address, WriteValid bool, GlobalReadValid bool, State uint256)
+func (_AccessRights *AccessRightsCaller) ReadContractInfo(opts *bind.CallOpts, ContractAddress common.Address) (struct {
+	Guardianship    *big.Int
+	Name            string
+	NewAddress      common.Address
+	WriteValid      bool
+	GlobalReadValid bool
+	State           *big.Int
+}, error) {
+	ret := new(struct {
+		Guardianship    *big.Int
+		Name            string
+		NewAddress      common.Address
+		WriteValid      bool
+		GlobalReadValid bool
+		State           *big.Int
+	})
+	out := ret
+	err := _AccessRights.contract.Call(opts, out, "ReadContractInfo", ContractAddress)
+	return *ret, err
+}
+
+// ReadContractInfo is a free data retrieval call binding the contract method 0x76599917.
+//
+// Solidity: function ReadContractInfo(ContractAddress address) constant returns(Guardianship uint256, Name string, NewAddress address, WriteValid bool, GlobalReadValid bool, State uint256)
+func (_AccessRights *AccessRightsSession) ReadContractInfo(ContractAddress common.Address) (struct {
+	Guardianship    *big.Int
+	Name            string
+	NewAddress      common.Address
+	WriteValid      bool
+	GlobalReadValid bool
+	State           *big.Int
+}, error) {
+	return _AccessRights.Contract.ReadContractInfo(&_AccessRights.CallOpts, ContractAddress)
+}
+
+// ReadContractInfo is a free data retrieval call binding the contract method 0x76599917.
+//
+// Solidity: function ReadContractInfo(ContractAddress address) constant returns(Guardianship uint256, Name string, NewAddress address, WriteValid bool, GlobalReadValid bool, State uint256)
+func (_AccessRights *AccessRightsCallerSession) ReadContractInfo(ContractAddress common.Address) (struct {
+	Guardianship    *big.Int
+	Name            string
+	NewAddress      common.Address
+	WriteValid      bool
+	GlobalReadValid bool
+	State           *big.Int
+}, error) {
+	return _AccessRights.Contract.ReadContractInfo(&_AccessRights.CallOpts, ContractAddress)
+}
+
+// ReadContractSubGuardianship is a free data retrieval call binding the contract method 0x334546ef.
+//
+// Solidity: function ReadContractSubGuardianship(ContractAddress address, Index uint256) constant returns(SubGuardianship uint256)
+func (_AccessRights *AccessRightsCaller) ReadContractSubGuardianship(opts *bind.CallOpts, ContractAddress common.Address, Index *big.Int) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _AccessRights.contract.Call(opts, out, "ReadContractSubGuardianship", ContractAddress, Index)
+	return *ret0, err
+}
+
+// ReadContractSubGuardianship is a free data retrieval call binding the contract method 0x334546ef.
+//
+// Solidity: function ReadContractSubGuardianship(ContractAddress address, Index uint256) constant returns(SubGuardianship uint256)
+func (_AccessRights *AccessRightsSession) ReadContractSubGuardianship(ContractAddress common.Address, Index *big.Int) (*big.Int, error) {
+	return _AccessRights.Contract.ReadContractSubGuardianship(&_AccessRights.CallOpts, ContractAddress, Index)
+}
+
+// ReadContractSubGuardianship is a free data retrieval call binding the contract method 0x334546ef.
+//
+// Solidity: function ReadContractSubGuardianship(ContractAddress address, Index uint256) constant returns(SubGuardianship uint256)
+func (_AccessRights *AccessRightsCallerSession) ReadContractSubGuardianship(ContractAddress common.Address, Index *big.Int) (*big.Int, error) {
+	return _AccessRights.Contract.ReadContractSubGuardianship(&_AccessRights.CallOpts, ContractAddress, Index)
+}
+
+// ReadContributor is a free data retrieval call binding the contract method 0xe7bf14c1.
+//
+// Solidity: function ReadContributor(ContributorAddress address) constant returns(Guardianship uint256, Name string, Limit uint256, LastBlockNumber uint256, AccessGroup uint256)
+func (_AccessRights *AccessRightsCaller) ReadContributor(opts *bind.CallOpts, ContributorAddress common.Address) (struct {
+	Guardianship    *big.Int
+	Name            string
+	Limit           *big.Int
+	LastBlockNumber *big.Int
+	AccessGroup     *big.Int
+}, error) {
+	ret := new(struct {
+		Guardianship    *big.Int
+		Name            string
+		Limit           *big.Int
+		LastBlockNumber *big.Int
+		AccessGroup     *big.Int
+	})
+	out := ret
+	err := _AccessRights.contract.Call(opts, out, "ReadContributor", ContributorAddress)
+	return *ret, err
+}
+
+// ReadContributor is a free data retrieval call binding the contract method 0xe7bf14c1.
+//
+// Solidity: function ReadContributor(ContributorAddress address) constant returns(Guardianship uint256, Name string, Limit uint256, LastBlockNumber uint256, AccessGroup uint256)
+func (_AccessRights *AccessRightsSession) ReadContributor(ContributorAddress common.Address) (struct {
+	Guardianship    *big.Int
+	Name            string
+	Limit           *big.Int
+	LastBlockNumber *big.Int
+	AccessGroup     *big.Int
+}, error) {
+	return _AccessRights.Contract.ReadContributor(&_AccessRights.CallOpts, ContributorAddress)
+}
+
+// ReadContributor is a free data retrieval call binding the contract method 0xe7bf14c1.
+//
+// Solidity: function ReadContributor(ContributorAddress address) constant returns(Guardianship uint256, Name string, Limit uint256, LastBlockNumber uint256, AccessGroup uint256)
+func (_AccessRights *AccessRightsCallerSession) ReadContributor(ContributorAddress common.Address) (struct {
+	Guardianship    *big.Int
+	Name            string
+	Limit           *big.Int
+	LastBlockNumber *big.Int
+	AccessGroup     *big.Int
+}, error) {
+	return _AccessRights.Contract.ReadContributor(&_AccessRights.CallOpts, ContributorAddress)
+}
+
+// ReadGuardianship is a free data retrieval call binding the contract method 0x271c3090.
+//
+// Solidity: function ReadGuardianship(GuardianAddress address) constant returns(Match bool, GIndex uint256, GuardianA address, GuardianB address, Name string, ContractListLength uint256, ContributorListLength uint256, AddVote address, RemoveVote address)
+func (_AccessRights *AccessRightsCaller) ReadGuardianship(opts *bind.CallOpts, GuardianAddress common.Address) (struct {
+	Match                 bool
+	GIndex                *big.Int
+	GuardianA             common.Address
+	GuardianB             common.Address
+	Name                  string
+	ContractListLength    *big.Int
+	ContributorListLength *big.Int
+	AddVote               common.Address
+	RemoveVote            common.Address
+}, error) {
+	ret := new(struct {
+		Match                 bool
+		GIndex                *big.Int
+		GuardianA             common.Address
+		GuardianB             common.Address
+		Name                  string
+		ContractListLength    *big.Int
+		ContributorListLength *big.Int
+		AddVote               common.Address
+		RemoveVote            common.Address
+	})
+	out := ret
+	err := _AccessRights.contract.Call(opts, out, "ReadGuardianship", GuardianAddress)
+	return *ret, err
+}
+
+// ReadGuardianship is a free data retrieval call binding the contract method 0x271c3090.
+//
+// Solidity: function ReadGuardianship(GuardianAddress address) constant returns(Match bool, GIndex uint256, GuardianA address, GuardianB address, Name string, ContractListLength uint256, ContributorListLength uint256, AddVote address, RemoveVote address)
+func (_AccessRights *AccessRightsSession) ReadGuardianship(GuardianAddress common.Address) (struct {
+	Match                 bool
+	GIndex                *big.Int
+	GuardianA             common.Address
+	GuardianB             common.Address
+	Name                  string
+	ContractListLength    *big.Int
+	ContributorListLength *big.Int
+	AddVote               common.Address
+	RemoveVote            common.Address
+}, error) {
+	return _AccessRights.Contract.ReadGuardianship(&_AccessRights.CallOpts, GuardianAddress)
+}
+
+// ReadGuardianship is a free data retrieval call binding the contract method 0x271c3090.
+//
+// Solidity: function ReadGuardianship(GuardianAddress address) constant returns(Match bool, GIndex uint256, GuardianA address, GuardianB address, Name string, ContractListLength uint256, ContributorListLength uint256, AddVote address, RemoveVote address)
+func (_AccessRights *AccessRightsCallerSession) ReadGuardianship(GuardianAddress common.Address) (struct {
+	Match                 bool
+	GIndex                *big.Int
+	GuardianA             common.Address
+	GuardianB             common.Address
+	Name                  string
+	ContractListLength    *big.Int
+	ContributorListLength *big.Int
+	AddVote               common.Address
+	RemoveVote            common.Address
+}, error) {
+	return _AccessRights.Contract.ReadGuardianship(&_AccessRights.CallOpts, GuardianAddress)
+}
+
+// ReadGuardianshipContract is a free data retrieval call binding the contract method 0xd85c4a93.
+//
+// Solidity: function ReadGuardianshipContract(GuardianAddress address, ListIndex uint256) constant returns(RetContractAddress address)
+func (_AccessRights *AccessRightsCaller) ReadGuardianshipContract(opts *bind.CallOpts, GuardianAddress common.Address, ListIndex *big.Int) (common.Address, error) {
+	var (
+		ret0 = new(common.Address)
+	)
+	out := ret0
+	err := _AccessRights.contract.Call(opts, out, "ReadGuardianshipContract", GuardianAddress, ListIndex)
+	return *ret0, err
+}
+
+// ReadGuardianshipContract is a free data retrieval call binding the contract method 0xd85c4a93.
+//
+// Solidity: function ReadGuardianshipContract(GuardianAddress address, ListIndex uint256) constant returns(RetContractAddress address)
+func (_AccessRights *AccessRightsSession) ReadGuardianshipContract(GuardianAddress common.Address, ListIndex *big.Int) (common.Address, error) {
+	return _AccessRights.Contract.ReadGuardianshipContract(&_AccessRights.CallOpts, GuardianAddress, ListIndex)
+}
+
+// ReadGuardianshipContract is a free data retrieval call binding the contract method 0xd85c4a93.
+//
+// Solidity: function ReadGuardianshipContract(GuardianAddress address, ListIndex uint256) constant returns(RetContractAddress address)
+func (_AccessRights *AccessRightsCallerSession) ReadGuardianshipContract(GuardianAddress common.Address, ListIndex *big.Int) (common.Address, error) {
+	return _AccessRights.Contract.ReadGuardianshipContract(&_AccessRights.CallOpts, GuardianAddress, ListIndex)
+}
+
+// ReadGuardianshipContributor is a free data retrieval call binding the contract method 0x1dd8de6a.
+//
+// Solidity: function ReadGuardianshipContributor(GuardianAddress address, ListIndex uint256) constant returns(RetContributorAddress address)
+func (_AccessRights *AccessRightsCaller) ReadGuardianshipContributor(opts *bind.CallOpts, GuardianAddress common.Address, ListIndex *big.Int) (common.Address, error) {
+	var (
+		ret0 = new(common.Address)
+	)
+	out := ret0
+	err := _AccessRights.contract.Call(opts, out, "ReadGuardianshipContributor", GuardianAddress, ListIndex)
+	return *ret0, err
+}
+
+// ReadGuardianshipContributor is a free data retrieval call binding the contract method 0x1dd8de6a.
+//
+// Solidity: function ReadGuardianshipContributor(GuardianAddress address, ListIndex uint256) constant returns(RetContributorAddress address)
+func (_AccessRights *AccessRightsSession) ReadGuardianshipContributor(GuardianAddress common.Address, ListIndex *big.Int) (common.Address, error) {
+	return _AccessRights.Contract.ReadGuardianshipContributor(&_AccessRights.CallOpts, GuardianAddress, ListIndex)
+}
+
+// ReadGuardianshipContributor is a free data retrieval call binding the contract method 0x1dd8de6a.
+//
+// Solidity: function ReadGuardianshipContributor(GuardianAddress address, ListIndex uint256) constant returns(RetContributorAddress address)
+func (_AccessRights *AccessRightsCallerSession) ReadGuardianshipContributor(GuardianAddress common.Address, ListIndex *big.Int) (common.Address, error) {
+	return _AccessRights.Contract.ReadGuardianshipContributor(&_AccessRights.CallOpts, GuardianAddress, ListIndex)
+}
+
+// ReadGuardianshipIndex is a free data retrieval call binding the contract method 0xcd2e6e0f.
+//
+// Solidity: function ReadGuardianshipIndex(Index uint256) constant returns(GuardianA address, GuardianB address, Name string, ContractListLength uint256, ContributorListLength uint256, AddVote address, RemoveVote address)
+func (_AccessRights *AccessRightsCaller) ReadGuardianshipIndex(opts *bind.CallOpts, Index *big.Int) (struct {
+	GuardianA             common.Address
+	GuardianB             common.Address
+	Name                  string
+	ContractListLength    *big.Int
+	ContributorListLength *big.Int
+	AddVote               common.Address
+	RemoveVote            common.Address
+}, error) {
+	ret := new(struct {
+		GuardianA             common.Address
+		GuardianB             common.Address
+		Name                  string
+		ContractListLength    *big.Int
+		ContributorListLength *big.Int
+		AddVote               common.Address
+		RemoveVote            common.Address
+	})
+	out := ret
+	err := _AccessRights.contract.Call(opts, out, "ReadGuardianshipIndex", Index)
+	return *ret, err
+}
+
+// ReadGuardianshipIndex is a free data retrieval call binding the contract method 0xcd2e6e0f.
+//
+// Solidity: function ReadGuardianshipIndex(Index uint256) constant returns(GuardianA address, GuardianB address, Name string, ContractListLength uint256, ContributorListLength uint256, AddVote address, RemoveVote address)
+func (_AccessRights *AccessRightsSession) ReadGuardianshipIndex(Index *big.Int) (struct {
+	GuardianA             common.Address
+	GuardianB             common.Address
+	Name                  string
+	ContractListLength    *big.Int
+	ContributorListLength *big.Int
+	AddVote               common.Address
+	RemoveVote            common.Address
+}, error) {
+	return _AccessRights.Contract.ReadGuardianshipIndex(&_AccessRights.CallOpts, Index)
+}
+
+// ReadGuardianshipIndex is a free data retrieval call binding the contract method 0xcd2e6e0f.
+//
+// Solidity: function ReadGuardianshipIndex(Index uint256) constant returns(GuardianA address, GuardianB address, Name string, ContractListLength uint256, ContributorListLength uint256, AddVote address, RemoveVote address)
+func (_AccessRights *AccessRightsCallerSession) ReadGuardianshipIndex(Index *big.Int) (struct {
+	GuardianA             common.Address
+	GuardianB             common.Address
+	Name                  string
+	ContractListLength    *big.Int
+	ContributorListLength *big.Int
+	AddVote               common.Address
+	RemoveVote            common.Address
+}, error) {
+	return _AccessRights.Contract.ReadGuardianshipIndex(&_AccessRights.CallOpts, Index)
+}
+
+// VerifyContractAccess is a free data retrieval call binding the contract method 0x45e4e5e4.
+//
+// Solidity: function VerifyContractAccess(ContractId address, FunctionAddress address) constant returns(ReadApproved bool, WriteApproved bool)
+func (_AccessRights *AccessRightsCaller) VerifyContractAccess(opts *bind.CallOpts, ContractId common.Address, FunctionAddress common.Address) (struct {
+	ReadApproved  bool
+	WriteApproved bool
+}, error) {
+	ret := new(struct {
+		ReadApproved  bool
+		WriteApproved bool
+	})
+	out := ret
+	err := _AccessRights.contract.Call(opts, out, "VerifyContractAccess", ContractId, FunctionAddress)
+	return *ret, err
+}
+
+// VerifyContractAccess is a free data retrieval call binding the contract method 0x45e4e5e4.
+//
+// Solidity: function VerifyContractAccess(ContractId address, FunctionAddress address) constant returns(ReadApproved bool, WriteApproved bool)
+func (_AccessRights *AccessRightsSession) VerifyContractAccess(ContractId common.Address, FunctionAddress common.Address) (struct {
+	ReadApproved  bool
+	WriteApproved bool
+}, error) {
+	return _AccessRights.Contract.VerifyContractAccess(&_AccessRights.CallOpts, ContractId, FunctionAddress)
+}
+
+// VerifyContractAccess is a free data retrieval call binding the contract method 0x45e4e5e4.
+//
+// Solidity: function VerifyContractAccess(ContractId address, FunctionAddress address) constant returns(ReadApproved bool, WriteApproved bool)
+func (_AccessRights *AccessRightsCallerSession) VerifyContractAccess(ContractId common.Address, FunctionAddress common.Address) (struct {
+	ReadApproved  bool
+	WriteApproved bool
+}, error) {
+	return _AccessRights.Contract.VerifyContractAccess(&_AccessRights.CallOpts, ContractId, FunctionAddress)
+}
+
+// VerifyContractSubGuardianshipList is a free data retrieval call binding the contract method 0x7591495f.
+//
+// Solidity: function VerifyContractSubGuardianshipList(ContractId address, Guardianship uint256) constant returns(Match bool)
+func (_AccessRights *AccessRightsCaller) VerifyContractSubGuardianshipList(opts *bind.CallOpts, ContractId common.Address, Guardianship *big.Int) (bool, error) {
+	var (
+		ret0 = new(bool)
+	)
+	out := ret0
+	err := _AccessRights.contract.Call(opts, out, "VerifyContractSubGuardianshipList", ContractId, Guardianship)
+	return *ret0, err
+}
+
+// VerifyContractSubGuardianshipList is a free data retrieval call binding the contract method 0x7591495f.
+//
+// Solidity: function VerifyContractSubGuardianshipList(ContractId address, Guardianship uint256) constant returns(Match bool)
+func (_AccessRights *AccessRightsSession) VerifyContractSubGuardianshipList(ContractId common.Address, Guardianship *big.Int) (bool, error) {
+	return _AccessRights.Contract.VerifyContractSubGuardianshipList(&_AccessRights.CallOpts, ContractId, Guardianship)
+}
+
+// VerifyContractSubGuardianshipList is a free data retrieval call binding the contract method 0x7591495f.
+//
+// Solidity: function VerifyContractSubGuardianshipList(ContractId address, Guardianship uint256) constant returns(Match bool)
+func (_AccessRights *AccessRightsCallerSession) VerifyContractSubGuardianshipList(ContractId common.Address, Guardianship *big.Int) (bool, error) {
+	return _AccessRights.Contract.VerifyContractSubGuardianshipList(&_AccessRights.CallOpts, ContractId, Guardianship)
+}
+
+// CreateContract is a paid mutator transaction binding the contract method 0xf5b7f3f6.
+//
+// Solidity: function CreateContract(ContractAddress address) returns()
+func (_AccessRights *AccessRightsTransactor) CreateContract(opts *bind.TransactOpts, ContractAddress common.Address) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "CreateContract", ContractAddress)
+}
+
+// CreateContract is a paid mutator transaction binding the contract method 0xf5b7f3f6.
+//
+// Solidity: function CreateContract(ContractAddress address) returns()
+func (_AccessRights *AccessRightsSession) CreateContract(ContractAddress common.Address) (*types.Transaction, error) {
+	return _AccessRights.Contract.CreateContract(&_AccessRights.TransactOpts, ContractAddress)
+}
+
+// CreateContract is a paid mutator transaction binding the contract method 0xf5b7f3f6.
+//
+// Solidity: function CreateContract(ContractAddress address) returns()
+func (_AccessRights *AccessRightsTransactorSession) CreateContract(ContractAddress common.Address) (*types.Transaction, error) {
+	return _AccessRights.Contract.CreateContract(&_AccessRights.TransactOpts, ContractAddress)
+}
+
+// CreateContributor is a paid mutator transaction binding the contract method 0x6615df5e.
+//
+// Solidity: function CreateContributor(ContributorAddress address, Name string, Limit uint256, AccessGroup uint256) returns()
+func (_AccessRights *AccessRightsTransactor) CreateContributor(opts *bind.TransactOpts, ContributorAddress common.Address, Name string, Limit *big.Int, AccessGroup *big.Int) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "CreateContributor", ContributorAddress, Name, Limit, AccessGroup)
+}
+
+// CreateContributor is a paid mutator transaction binding the contract method 0x6615df5e.
+//
+// Solidity: function CreateContributor(ContributorAddress address, Name string, Limit uint256, AccessGroup uint256) returns()
+func (_AccessRights *AccessRightsSession) CreateContributor(ContributorAddress common.Address, Name string, Limit *big.Int, AccessGroup *big.Int) (*types.Transaction, error) {
+	return _AccessRights.Contract.CreateContributor(&_AccessRights.TransactOpts, ContributorAddress, Name, Limit, AccessGroup)
+}
+
+// CreateContributor is a paid mutator transaction binding the contract method 0x6615df5e.
+//
+// Solidity: function CreateContributor(ContributorAddress address, Name string, Limit uint256, AccessGroup uint256) returns()
+func (_AccessRights *AccessRightsTransactorSession) CreateContributor(ContributorAddress common.Address, Name string, Limit *big.Int, AccessGroup *big.Int) (*types.Transaction, error) {
+	return _AccessRights.Contract.CreateContributor(&_AccessRights.TransactOpts, ContributorAddress, Name, Limit, AccessGroup)
+}
+
+// DeleteContributor is a paid mutator transaction binding the contract method 0x735b89a0.
+//
+// Solidity: function DeleteContributor(ContributorAddress address) returns()
+func (_AccessRights *AccessRightsTransactor) DeleteContributor(opts *bind.TransactOpts, ContributorAddress common.Address) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "DeleteContributor", ContributorAddress)
+}
+
+// DeleteContributor is a paid mutator transaction binding the contract method 0x735b89a0.
+//
+// Solidity: function DeleteContributor(ContributorAddress address) returns()
+func (_AccessRights *AccessRightsSession) DeleteContributor(ContributorAddress common.Address) (*types.Transaction, error) {
+	return _AccessRights.Contract.DeleteContributor(&_AccessRights.TransactOpts, ContributorAddress)
+}
+
+// DeleteContributor is a paid mutator transaction binding the contract method 0x735b89a0.
+//
+// Solidity: function DeleteContributor(ContributorAddress address) returns()
+func (_AccessRights *AccessRightsTransactorSession) DeleteContributor(ContributorAddress common.Address) (*types.Transaction, error) {
+	return _AccessRights.Contract.DeleteContributor(&_AccessRights.TransactOpts, ContributorAddress)
+}
+
+// GuardianshipVote is a paid mutator transaction binding the contract method 0x5486491f.
+//
+// Solidity: function GuardianshipVote(VoteId address, AddVote bool) returns()
+func (_AccessRights *AccessRightsTransactor) GuardianshipVote(opts *bind.TransactOpts, VoteId common.Address, AddVote bool) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "GuardianshipVote", VoteId, AddVote)
+}
+
+// GuardianshipVote is a paid mutator transaction binding the contract method 0x5486491f.
+//
+// Solidity: function GuardianshipVote(VoteId address, AddVote bool) returns()
+func (_AccessRights *AccessRightsSession) GuardianshipVote(VoteId common.Address, AddVote bool) (*types.Transaction, error) {
+	return _AccessRights.Contract.GuardianshipVote(&_AccessRights.TransactOpts, VoteId, AddVote)
+}
+
+// GuardianshipVote is a paid mutator transaction binding the contract method 0x5486491f.
+//
+// Solidity: function GuardianshipVote(VoteId address, AddVote bool) returns()
+func (_AccessRights *AccessRightsTransactorSession) GuardianshipVote(VoteId common.Address, AddVote bool) (*types.Transaction, error) {
+	return _AccessRights.Contract.GuardianshipVote(&_AccessRights.TransactOpts, VoteId, AddVote)
+}
+
+// InitGuardianship is a paid mutator transaction binding the contract method 0xd36bef80.
+//
+// Solidity: function InitGuardianship() returns()
+func (_AccessRights *AccessRightsTransactor) InitGuardianship(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "InitGuardianship")
+}
+
+// InitGuardianship is a paid mutator transaction binding the contract method 0xd36bef80.
+//
+// Solidity: function InitGuardianship() returns()
+func (_AccessRights *AccessRightsSession) InitGuardianship() (*types.Transaction, error) {
+	return _AccessRights.Contract.InitGuardianship(&_AccessRights.TransactOpts)
+}
+
+// InitGuardianship is a paid mutator transaction binding the contract method 0xd36bef80.
+//
+// Solidity: function InitGuardianship() returns()
+func (_AccessRights *AccessRightsTransactorSession) InitGuardianship() (*types.Transaction, error) {
+	return _AccessRights.Contract.InitGuardianship(&_AccessRights.TransactOpts)
+}
+
+// UpdateContributorBlock is a paid mutator transaction binding the contract method 0xcf0d1ea0.
+//
+// Solidity: function UpdateContributorBlock() returns()
+func (_AccessRights *AccessRightsTransactor) UpdateContributorBlock(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "UpdateContributorBlock")
+}
+
+// UpdateContributorBlock is a paid mutator transaction binding the contract method 0xcf0d1ea0.
+//
+// Solidity: function UpdateContributorBlock() returns()
+func (_AccessRights *AccessRightsSession) UpdateContributorBlock() (*types.Transaction, error) {
+	return _AccessRights.Contract.UpdateContributorBlock(&_AccessRights.TransactOpts)
+}
+
+// UpdateContributorBlock is a paid mutator transaction binding the contract method 0xcf0d1ea0.
+//
+// Solidity: function UpdateContributorBlock() returns()
+func (_AccessRights *AccessRightsTransactorSession) UpdateContributorBlock() (*types.Transaction, error) {
+	return _AccessRights.Contract.UpdateContributorBlock(&_AccessRights.TransactOpts)
+}
+
+// WriteContractFunctionIndex is a paid mutator transaction binding the contract method 0x9cb6d8a3.
+//
+// Solidity: function WriteContractFunctionIndex(Contract address, FunctionIndex uint256, FunctionAddress address, AccessGroup uint256) returns()
+func (_AccessRights *AccessRightsTransactor) WriteContractFunctionIndex(opts *bind.TransactOpts, Contract common.Address, FunctionIndex *big.Int, FunctionAddress common.Address, AccessGroup *big.Int) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "WriteContractFunctionIndex", Contract, FunctionIndex, FunctionAddress, AccessGroup)
+}
+
+// WriteContractFunctionIndex is a paid mutator transaction binding the contract method 0x9cb6d8a3.
+//
+// Solidity: function WriteContractFunctionIndex(Contract address, FunctionIndex uint256, FunctionAddress address, AccessGroup uint256) returns()
+func (_AccessRights *AccessRightsSession) WriteContractFunctionIndex(Contract common.Address, FunctionIndex *big.Int, FunctionAddress common.Address, AccessGroup *big.Int) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteContractFunctionIndex(&_AccessRights.TransactOpts, Contract, FunctionIndex, FunctionAddress, AccessGroup)
+}
+
+// WriteContractFunctionIndex is a paid mutator transaction binding the contract method 0x9cb6d8a3.
+//
+// Solidity: function WriteContractFunctionIndex(Contract address, FunctionIndex uint256, FunctionAddress address, AccessGroup uint256) returns()
+func (_AccessRights *AccessRightsTransactorSession) WriteContractFunctionIndex(Contract common.Address, FunctionIndex *big.Int, FunctionAddress common.Address, AccessGroup *big.Int) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteContractFunctionIndex(&_AccessRights.TransactOpts, Contract, FunctionIndex, FunctionAddress, AccessGroup)
+}
+
+// WriteContractInfo is a paid mutator transaction binding the contract method 0x72ccdfe1.
+//
+// Solidity: function WriteContractInfo(ContractAddress address, Name string, NewAddress address, WriteValid bool, GlobalReadValid bool, State uint256) returns()
+func (_AccessRights *AccessRightsTransactor) WriteContractInfo(opts *bind.TransactOpts, ContractAddress common.Address, Name string, NewAddress common.Address, WriteValid bool, GlobalReadValid bool, State *big.Int) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "WriteContractInfo", ContractAddress, Name, NewAddress, WriteValid, GlobalReadValid, State)
+}
+
+// WriteContractInfo is a paid mutator transaction binding the contract method 0x72ccdfe1.
+//
+// Solidity: function WriteContractInfo(ContractAddress address, Name string, NewAddress address, WriteValid bool, GlobalReadValid bool, State uint256) returns()
+func (_AccessRights *AccessRightsSession) WriteContractInfo(ContractAddress common.Address, Name string, NewAddress common.Address, WriteValid bool, GlobalReadValid bool, State *big.Int) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteContractInfo(&_AccessRights.TransactOpts, ContractAddress, Name, NewAddress, WriteValid, GlobalReadValid, State)
+}
+
+// WriteContractInfo is a paid mutator transaction binding the contract method 0x72ccdfe1.
+//
+// Solidity: function WriteContractInfo(ContractAddress address, Name string, NewAddress address, WriteValid bool, GlobalReadValid bool, State uint256) returns()
+func (_AccessRights *AccessRightsTransactorSession) WriteContractInfo(ContractAddress common.Address, Name string, NewAddress common.Address, WriteValid bool, GlobalReadValid bool, State *big.Int) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteContractInfo(&_AccessRights.TransactOpts, ContractAddress, Name, NewAddress, WriteValid, GlobalReadValid, State)
+}
+
+// WriteContractSubGuardianship is a paid mutator transaction binding the contract method 0x0af346d4.
+//
+// Solidity: function WriteContractSubGuardianship(ContractAddress address, SubGuardianId address, Index uint256) returns()
+func (_AccessRights *AccessRightsTransactor) WriteContractSubGuardianship(opts *bind.TransactOpts, ContractAddress common.Address, SubGuardianId common.Address, Index *big.Int) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "WriteContractSubGuardianship", ContractAddress, SubGuardianId, Index)
+}
+
+// WriteContractSubGuardianship is a paid mutator transaction binding the contract method 0x0af346d4.
+//
+// Solidity: function WriteContractSubGuardianship(ContractAddress address, SubGuardianId address, Index uint256) returns()
+func (_AccessRights *AccessRightsSession) WriteContractSubGuardianship(ContractAddress common.Address, SubGuardianId common.Address, Index *big.Int) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteContractSubGuardianship(&_AccessRights.TransactOpts, ContractAddress, SubGuardianId, Index)
+}
+
+// WriteContractSubGuardianship is a paid mutator transaction binding the contract method 0x0af346d4.
+//
+// Solidity: function WriteContractSubGuardianship(ContractAddress address, SubGuardianId address, Index uint256) returns()
+func (_AccessRights *AccessRightsTransactorSession) WriteContractSubGuardianship(ContractAddress common.Address, SubGuardianId common.Address, Index *big.Int) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteContractSubGuardianship(&_AccessRights.TransactOpts, ContractAddress, SubGuardianId, Index)
+}
+
+// WriteContributor is a paid mutator transaction binding the contract method 0x275b19fd.
+//
+// Solidity: function WriteContributor(ContributorAddress address, Name string, Limit uint256, AccessGroup uint256) returns()
+func (_AccessRights *AccessRightsTransactor) WriteContributor(opts *bind.TransactOpts, ContributorAddress common.Address, Name string, Limit *big.Int, AccessGroup *big.Int) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "WriteContributor", ContributorAddress, Name, Limit, AccessGroup)
+}
+
+// WriteContributor is a paid mutator transaction binding the contract method 0x275b19fd.
+//
+// Solidity: function WriteContributor(ContributorAddress address, Name string, Limit uint256, AccessGroup uint256) returns()
+func (_AccessRights *AccessRightsSession) WriteContributor(ContributorAddress common.Address, Name string, Limit *big.Int, AccessGroup *big.Int) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteContributor(&_AccessRights.TransactOpts, ContributorAddress, Name, Limit, AccessGroup)
+}
+
+// WriteContributor is a paid mutator transaction binding the contract method 0x275b19fd.
+//
+// Solidity: function WriteContributor(ContributorAddress address, Name string, Limit uint256, AccessGroup uint256) returns()
+func (_AccessRights *AccessRightsTransactorSession) WriteContributor(ContributorAddress common.Address, Name string, Limit *big.Int, AccessGroup *big.Int) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteContributor(&_AccessRights.TransactOpts, ContributorAddress, Name, Limit, AccessGroup)
+}
+
+// WriteGuardian is a paid mutator transaction binding the contract method 0x2d7e44e6.
+//
+// Solidity: function WriteGuardian(NewGuardianAddress address) returns()
+func (_AccessRights *AccessRightsTransactor) WriteGuardian(opts *bind.TransactOpts, NewGuardianAddress common.Address) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "WriteGuardian", NewGuardianAddress)
+}
+
+// WriteGuardian is a paid mutator transaction binding the contract method 0x2d7e44e6.
+//
+// Solidity: function WriteGuardian(NewGuardianAddress address) returns()
+func (_AccessRights *AccessRightsSession) WriteGuardian(NewGuardianAddress common.Address) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteGuardian(&_AccessRights.TransactOpts, NewGuardianAddress)
+}
+
+// WriteGuardian is a paid mutator transaction binding the contract method 0x2d7e44e6.
+//
+// Solidity: function WriteGuardian(NewGuardianAddress address) returns()
+func (_AccessRights *AccessRightsTransactorSession) WriteGuardian(NewGuardianAddress common.Address) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteGuardian(&_AccessRights.TransactOpts, NewGuardianAddress)
+}
+
+// WriteGuardianshipName is a paid mutator transaction binding the contract method 0x1a1a84de.
+//
+// Solidity: function WriteGuardianshipName(Name string) returns()
+func (_AccessRights *AccessRightsTransactor) WriteGuardianshipName(opts *bind.TransactOpts, Name string) (*types.Transaction, error) {
+	return _AccessRights.contract.Transact(opts, "WriteGuardianshipName", Name)
+}
+
+// WriteGuardianshipName is a paid mutator transaction binding the contract method 0x1a1a84de.
+//
+// Solidity: function WriteGuardianshipName(Name string) returns()
+func (_AccessRights *AccessRightsSession) WriteGuardianshipName(Name string) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteGuardianshipName(&_AccessRights.TransactOpts, Name)
+}
+
+// WriteGuardianshipName is a paid mutator transaction binding the contract method 0x1a1a84de.
+//
+// Solidity: function WriteGuardianshipName(Name string) returns()
+func (_AccessRights *AccessRightsTransactorSession) WriteGuardianshipName(Name string) (*types.Transaction, error) {
+	return _AccessRights.Contract.WriteGuardianshipName(&_AccessRights.TransactOpts, Name)
+}
@@ -0,0 +1,399 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package accessrights provides Go bindings for the AccessRights contract of a
+// Veriteem chain, which holds the guardianships, contributors and contracts
+// the EVM checks calls against.
+//
+// The AccessRights type in accessrights.go is generated by abigen from
+// scripts/AccessRights.sol and exposes every method of the contract. It has to
+// be regenerated with abigen --sol AccessRights.sol --pkg accessrights whenever
+// the contract changes.
+//
+// Client wraps the binding with typed reads of the permission tables and the
+// helpers needed to administer them. It works with any bind.ContractBackend
+// that can also look up receipts, such as an ethclient or the simulated
+// backend of veriteemtest.
+package accessrights
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	MaxGuardianships = 20 // Size of the GuardianshipTable and of SubGuardianshipList, MAX_GUARDIANSHIP
+	MaxFunctions     = 50 // Size of the FunctionList of a contract, MAX_FUNC_LIST
+
+	// TxGas is the gas limit of the transactions sent by NewTransactor, enough
+	// for any administration method of the contract.
+	TxGas uint64 = 4000000
+)
+
+// DefaultAddress is the address the Veriteem genesis installs the AccessRights
+// contract at.
+var DefaultAddress = params.DefaultVeriteemConfig.AccessContract
+
+// Backend is the chain connection a Client needs: the contract backend of the
+// bindings, plus receipts to wait for administration transactions.
+type Backend interface {
+	bind.ContractBackend
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// Client administers the permission tables of an AccessRights contract.
+//
+// The contract ignores administration it doesn't authorize instead of
+// reverting, so a mined transaction doesn't mean the tables changed: callers
+// that need to know read the affected entry back.
+type Client struct {
+	*AccessRights
+
+	Address common.Address
+	backend Backend
+}
+
+// NewClient binds a client to the AccessRights contract at address.
+func NewClient(address common.Address, backend Backend) (*Client, error) {
+	contract, err := NewAccessRights(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{AccessRights: contract, Address: address, backend: backend}, nil
+}
+
+// Dial connects to the node at rawurl and binds a client to the AccessRights
+// contract at address.
+func Dial(rawurl string, address common.Address) (*Client, error) {
+	conn, err := ethclient.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(address, conn)
+}
+
+// NewTransactor returns transaction options signing with key. Veriteem chains
+// don't price gas, so transactions are sent with a zero gas price and a fixed
+// gas limit, which spares an estimate that the access checks of the node could
+// fail.
+func NewTransactor(key *ecdsa.PrivateKey) *bind.TransactOpts {
+	opts := bind.NewKeyedTransactor(key)
+	opts.GasPrice = new(big.Int)
+	opts.GasLimit = TxGas
+	return opts
+}
+
+// Wait waits for tx to be mined and fails if it didn't execute successfully.
+func (c *Client) Wait(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	receipt, err := bind.WaitMined(ctx, c.backend, tx)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("transaction %x failed", tx.Hash())
+	}
+	return receipt, nil
+}
+
+// Guardianship is an entry of the GuardianshipTable.
+type Guardianship struct {
+	Index        uint64            `json:"index"`
+	Name         string            `json:"name"`
+	Guardians    [2]common.Address `json:"guardians"`    // GuardianList, zero for unused slots
+	Contracts    uint64            `json:"contracts"`    // Length of the ContractList
+	Contributors uint64            `json:"contributors"` // Length of the ContributorList, deleted entries included
+	AddVote      common.Address    `json:"addVote"`      // Candidate the guardianship votes to add
+	RemoveVote   common.Address    `json:"removeVote"`   // Guardian the guardianship votes to remove
+}
+
+// Active reports whether the guardianship has a guardian.
+func (g *Guardianship) Active() bool {
+	return g.Guardians[0] != (common.Address{}) || g.Guardians[1] != (common.Address{})
+}
+
+// Guardianship reads the guardianship at index of the GuardianshipTable. Index
+// zero is never used.
+func (c *Client) Guardianship(opts *bind.CallOpts, index uint64) (*Guardianship, error) {
+	entry, err := c.ReadGuardianshipIndex(opts, new(big.Int).SetUint64(index))
+	if err != nil {
+		return nil, err
+	}
+	return &Guardianship{
+		Index:        index,
+		Name:         entry.Name,
+		Guardians:    [2]common.Address{entry.GuardianA, entry.GuardianB},
+		Contracts:    entry.ContractListLength.Uint64(),
+		Contributors: entry.ContributorListLength.Uint64(),
+		AddVote:      entry.AddVote,
+		RemoveVote:   entry.RemoveVote,
+	}, nil
+}
+
+// GuardianshipOf reads the guardianship guardian belongs to. The second return
+// value is false if guardian isn't a guardian.
+func (c *Client) GuardianshipOf(opts *bind.CallOpts, guardian common.Address) (*Guardianship, bool, error) {
+	if guardian == (common.Address{}) {
+		return nil, false, nil
+	}
+	match, err := c.GuardianshipIndex(opts, guardian)
+	if err != nil || !match.Match {
+		return nil, false, err
+	}
+	guardianship, err := c.Guardianship(opts, match.Index.Uint64())
+	if err != nil {
+		return nil, false, err
+	}
+	return guardianship, true, nil
+}
+
+// Guardianships reads the guardianships that have a guardian, by index.
+func (c *Client) Guardianships(opts *bind.CallOpts) ([]*Guardianship, error) {
+	var guardianships []*Guardianship
+	for index := uint64(1); index < MaxGuardianships; index++ {
+		guardianship, err := c.Guardianship(opts, index)
+		if err != nil {
+			return nil, err
+		}
+		if guardianship.Active() {
+			guardianships = append(guardianships, guardianship)
+		}
+	}
+	return guardianships, nil
+}
+
+// GuardianshipContracts reads the ContractList of the guardianship of guardian.
+func (c *Client) GuardianshipContracts(opts *bind.CallOpts, guardian common.Address) ([]common.Address, error) {
+	guardianship, ok, err := c.GuardianshipOf(opts, guardian)
+	if err != nil || !ok {
+		return nil, err
+	}
+	contracts := make([]common.Address, 0, guardianship.Contracts)
+	for i := uint64(0); i < guardianship.Contracts; i++ {
+		contract, err := c.ReadGuardianshipContract(opts, guardian, new(big.Int).SetUint64(i))
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, contract)
+	}
+	return contracts, nil
+}
+
+// GuardianshipContributors reads the ContributorList of the guardianship of
+// guardian, leaving out the entries of deleted contributors.
+func (c *Client) GuardianshipContributors(opts *bind.CallOpts, guardian common.Address) ([]common.Address, error) {
+	guardianship, ok, err := c.GuardianshipOf(opts, guardian)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var contributors []common.Address
+	for i := uint64(0); i < guardianship.Contributors; i++ {
+		contributor, err := c.ReadGuardianshipContributor(opts, guardian, new(big.Int).SetUint64(i))
+		if err != nil {
+			return nil, err
+		}
+		if contributor != (common.Address{}) {
+			contributors = append(contributors, contributor)
+		}
+	}
+	return contributors, nil
+}
+
+// VoteStats is the tally of the guardianship votes for a candidate.
+type VoteStats struct {
+	AddAgree       uint64 `json:"addAgree"`
+	AddDisagree    uint64 `json:"addDisagree"`
+	RemoveAgree    uint64 `json:"removeAgree"`
+	RemoveDisagree uint64 `json:"removeDisagree"`
+}
+
+// VoteStats tallies the votes of the guardianships to add or remove candidate.
+// A vote passes once more guardianships agree than disagree.
+func (c *Client) VoteStats(opts *bind.CallOpts, candidate common.Address) (*VoteStats, error) {
+	stats, err := c.GuardianshipVoteStats(opts, candidate)
+	if err != nil {
+		return nil, err
+	}
+	return &VoteStats{
+		AddAgree:       stats.AddAgreeCount.Uint64(),
+		AddDisagree:    stats.AddDisagreeCount.Uint64(),
+		RemoveAgree:    stats.RemoveAgreeCount.Uint64(),
+		RemoveDisagree: stats.RemoveDisagreeCount.Uint64(),
+	}, nil
+}
+
+// Contributor is an entry of the ContributorTable.
+type Contributor struct {
+	Address         common.Address `json:"address"`
+	Guardianship    uint64         `json:"guardianship"` // Index of the owning guardianship, zero if none
+	Name            string         `json:"name"`
+	Limit           uint64         `json:"limit"`           // Blocks between two writes
+	LastBlockNumber uint64         `json:"lastBlockNumber"` // Block of the last write
+	AccessGroup     *big.Int       `json:"accessGroup"`     // Bit mask of the contributor's access groups
+}
+
+// Contributor reads the entry of contributor in the ContributorTable.
+func (c *Client) Contributor(opts *bind.CallOpts, contributor common.Address) (*Contributor, error) {
+	entry, err := c.ReadContributor(opts, contributor)
+	if err != nil {
+		return nil, err
+	}
+	return &Contributor{
+		Address:         contributor,
+		Guardianship:    entry.Guardianship.Uint64(),
+		Name:            entry.Name,
+		Limit:           entry.Limit.Uint64(),
+		LastBlockNumber: entry.LastBlockNumber.Uint64(),
+		AccessGroup:     entry.AccessGroup,
+	}, nil
+}
+
+// ContractState is the lifecycle State of a contract in the ContractTable.
+type ContractState uint64
+
+// Lifecycle states the EVM enforces, from the lifecycle fork on.
+const (
+	ContractStateDisabled   ContractState = 0 // The guardian owning the contract was removed
+	ContractStateActive     ContractState = 1 // Set by CreateContract
+	ContractStateMigrated   ContractState = 2 // Superseded by the contract at NewAddress
+	ContractStatePaused     ContractState = 3 // Writes are suspended, reads still allowed
+	ContractStateDeprecated ContractState = 4 // Still usable, but calls are logged with a warning
+	ContractStateRetired    ContractState = 5 // All access is denied
+)
+
+// contractStateNames are the descriptions of the contract states.
+var contractStateNames = map[ContractState]string{
+	ContractStateDisabled:   "disabled",
+	ContractStateActive:     "active",
+	ContractStateMigrated:   "migrated",
+	ContractStatePaused:     "paused",
+	ContractStateDeprecated: "deprecated",
+	ContractStateRetired:    "retired",
+}
+
+// String implements fmt.Stringer.
+func (s ContractState) String() string {
+	if name, ok := contractStateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ContractInfo is the entry of a contract in the ContractTable, without its
+// lists.
+type ContractInfo struct {
+	Address         common.Address `json:"address"`
+	Guardianship    uint64         `json:"guardianship"` // Index of the owning guardianship, zero if unowned
+	Name            string         `json:"name"`
+	NewAddress      common.Address `json:"newAddress"` // Contract superseding it, if migrated
+	WriteValid      bool           `json:"writeValid"`
+	GlobalReadValid bool           `json:"globalReadValid"`
+	State           ContractState  `json:"state"`
+}
+
+// ContractInfo reads the entry of contract in the ContractTable.
+func (c *Client) ContractInfo(opts *bind.CallOpts, contract common.Address) (*ContractInfo, error) {
+	entry, err := c.ReadContractInfo(opts, contract)
+	if err != nil {
+		return nil, err
+	}
+	state := ContractState(entry.State.Uint64())
+	if !entry.State.IsUint64() {
+		state = ContractState(^uint64(0))
+	}
+	return &ContractInfo{
+		Address:         contract,
+		Guardianship:    entry.Guardianship.Uint64(),
+		Name:            entry.Name,
+		NewAddress:      entry.NewAddress,
+		WriteValid:      entry.WriteValid,
+		GlobalReadValid: entry.GlobalReadValid,
+		State:           state,
+	}, nil
+}
+
+// SetContractInfo writes the name, flags and state of info to the entry of
+// info.Address, which has to be owned by the guardianship of the sender.
+func (c *Client) SetContractInfo(opts *bind.TransactOpts, info *ContractInfo) (*types.Transaction, error) {
+	state := new(big.Int).SetUint64(uint64(info.State))
+	return c.WriteContractInfo(opts, info.Address, info.Name, info.NewAddress, info.WriteValid, info.GlobalReadValid, state)
+}
+
+// SubGuardianships reads the SubGuardianshipList of contract, whose
+// guardianships' contributors may also access it. Unused slots are zero.
+func (c *Client) SubGuardianships(opts *bind.CallOpts, contract common.Address) ([MaxGuardianships]uint64, error) {
+	var list [MaxGuardianships]uint64
+	for i := range list {
+		index, err := c.ReadContractSubGuardianship(opts, contract, big.NewInt(int64(i)))
+		if err != nil {
+			return list, err
+		}
+		list[i] = index.Uint64()
+	}
+	return list, nil
+}
+
+// FunctionGroup restricts a function of a contract to a set of access groups.
+type FunctionGroup struct {
+	Index       uint64        `json:"index"`       // Slot in the contract's FunctionList
+	Selector    hexutil.Bytes `json:"selector"`    // 4 byte selector of the function
+	AccessGroup *big.Int      `json:"accessGroup"` // Bit mask of the access groups allowed to call it
+}
+
+// FunctionGroups reads the FunctionList of contract. The contract stops
+// looking up functions at the first empty slot, so neither does this.
+func (c *Client) FunctionGroups(opts *bind.CallOpts, contract common.Address) ([]FunctionGroup, error) {
+	var groups []FunctionGroup
+	for i := uint64(0); i < MaxFunctions; i++ {
+		entry, err := c.ReadContractFunctionIndex(opts, contract, new(big.Int).SetUint64(i))
+		if err != nil {
+			return nil, err
+		}
+		if entry.FunctionAddress == (common.Address{}) {
+			break
+		}
+		groups = append(groups, FunctionGroup{
+			Index:       i,
+			Selector:    common.CopyBytes(entry.FunctionAddress[common.AddressLength-4:]),
+			AccessGroup: entry.AccessGroup,
+		})
+	}
+	return groups, nil
+}
+
+// SetFunctionGroup restricts the function of contract identified by selector
+// to accessGroup, in slot index of the contract's FunctionList.
+func (c *Client) SetFunctionGroup(opts *bind.TransactOpts, contract common.Address, index uint64, selector [4]byte, accessGroup *big.Int) (*types.Transaction, error) {
+	if index >= MaxFunctions {
+		return nil, fmt.Errorf("function index %d out of range, the FunctionList holds %d functions", index, MaxFunctions)
+	}
+	return c.WriteContractFunctionIndex(opts, contract, new(big.Int).SetUint64(index), FunctionAddress(selector), accessGroup)
+}
+
+// FunctionAddress converts a function selector into the address form the
+// contract stores in its FunctionList and expects in VerifyContractAccess: the
+// selector in the four low order bytes.
+func FunctionAddress(selector [4]byte) common.Address {
+	return common.BytesToAddress(selector[:])
+}
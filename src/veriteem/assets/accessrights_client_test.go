@@ -0,0 +1,155 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package accessrights
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/veriteem/veriteemtest"
+)
+
+// newTestClient boots a simulated chain from the Veriteem genesis in testdata,
+// with every Ethereum fork active from block zero so receipts carry a status,
+// and binds a client to its access contract.
+func newTestClient(t *testing.T) (*veriteemtest.Chain, *Client) {
+	genesis, err := veriteemtest.LoadGenesis(filepath.Join("testdata", "genesis.json"))
+	if err != nil {
+		t.Fatalf("failed to load genesis: %v", err)
+	}
+	config := *genesis.Config
+	config.HomesteadBlock = new(big.Int)
+	config.EIP150Block = new(big.Int)
+	config.EIP155Block = new(big.Int)
+	config.EIP158Block = new(big.Int)
+	config.ByzantiumBlock = new(big.Int)
+	genesis.Config = &config
+
+	chain, err := veriteemtest.NewChain(genesis)
+	if err != nil {
+		t.Fatalf("failed to boot chain: %v", err)
+	}
+	client, err := NewClient(chain.Access, chain.Backend)
+	if err != nil {
+		t.Fatalf("failed to bind client: %v", err)
+	}
+	return chain, client
+}
+
+// Tests that the administration methods sent through the client change the
+// tables the way its typed reads report them back.
+func TestClientRoundTrip(t *testing.T) {
+	chain, client := newTestClient(t)
+
+	var (
+		guardian  = veriteemtest.NewAccount()
+		candidate = veriteemtest.NewAccount()
+		member    = veriteemtest.NewAccount()
+		opts      = NewTransactor(guardian.Key)
+	)
+	send := func(method string, tx *types.Transaction, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("failed to send %s: %v", method, err)
+		}
+		chain.Backend.Commit()
+		if _, err := client.Wait(context.Background(), tx); err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+	}
+	tx, err := client.InitGuardianship(opts)
+	send("InitGuardianship", tx, err)
+
+	// Guardianships are read back by index through ReadGuardianshipIndex
+	first, ok, err := client.GuardianshipOf(nil, guardian.Address)
+	if err != nil || !ok {
+		t.Fatalf("guardianship of guardian not found: %v", err)
+	}
+	if first.Guardians[0] != guardian.Address {
+		t.Errorf("guardians of first guardianship %x, want %x", first.Guardians, guardian.Address)
+	}
+	// A vote of the only guardianship is a majority
+	tx, err = client.GuardianshipVote(opts, candidate.Address, true)
+	send("GuardianshipVote", tx, err)
+
+	second, ok, err := client.GuardianshipOf(nil, candidate.Address)
+	if err != nil || !ok {
+		t.Fatalf("guardianship of voted in candidate not found: %v", err)
+	}
+	if second.Index == first.Index || second.Guardians[0] != candidate.Address {
+		t.Errorf("guardianship of candidate %+v, want a new one guarded by %x", second, candidate.Address)
+	}
+	if entry, err := client.Guardianship(nil, second.Index); err != nil || !reflect.DeepEqual(entry, second) {
+		t.Errorf("guardianship %d: have %+v (%v), want %+v", second.Index, entry, err, second)
+	}
+	if guardianships, err := client.Guardianships(nil); err != nil || len(guardianships) != 2 {
+		t.Errorf("have %d active guardianships (%v), want 2", len(guardianships), err)
+	}
+
+	tx, err = client.CreateContributor(opts, member.Address, "member", big.NewInt(5), big.NewInt(3))
+	send("CreateContributor", tx, err)
+
+	contributor, err := client.Contributor(nil, member.Address)
+	if err != nil {
+		t.Fatalf("failed to read contributor: %v", err)
+	}
+	if contributor.Guardianship != first.Index || contributor.Name != "member" || contributor.Limit != 5 || contributor.AccessGroup.Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("contributor mismatch: have %+v", contributor)
+	}
+	if contributors, err := client.GuardianshipContributors(nil, guardian.Address); err != nil || len(contributors) != 1 || contributors[0] != member.Address {
+		t.Errorf("contributors of guardianship: have %x (%v), want [%x]", contributors, err, member.Address)
+	}
+
+	// PUSH1 1 PUSH1 12 PUSH1 0 CODECOPY PUSH1 1 PUSH1 0 RETURN, then STOP
+	contract, err := chain.Deploy(guardian, []byte{0x60, 0x01, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, 0x01, 0x60, 0x00, 0xf3, 0x00})
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	if contracts, err := client.GuardianshipContracts(nil, guardian.Address); err != nil || len(contracts) != 1 || contracts[0] != contract {
+		t.Errorf("contracts of guardianship: have %x (%v), want [%x]", contracts, err, contract)
+	}
+	want := &ContractInfo{
+		Address:         contract,
+		Guardianship:    first.Index,
+		Name:            "paused",
+		GlobalReadValid: true,
+		State:           ContractStatePaused,
+	}
+	tx, err = client.SetContractInfo(opts, want)
+	send("WriteContractInfo", tx, err)
+
+	if info, err := client.ContractInfo(nil, contract); err != nil || !reflect.DeepEqual(info, want) {
+		t.Errorf("contract info: have %+v (%v), want %+v", info, err, want)
+	}
+
+	selector := [4]byte{0x12, 0x34, 0x56, 0x78}
+	tx, err = client.SetFunctionGroup(opts, contract, 0, selector, big.NewInt(6))
+	send("WriteContractFunctionIndex", tx, err)
+
+	groups, err := client.FunctionGroups(nil, contract)
+	if err != nil {
+		t.Fatalf("failed to read function groups: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Index != 0 || !bytes.Equal(groups[0].Selector, selector[:]) || groups[0].AccessGroup.Cmp(big.NewInt(6)) != 0 {
+		t.Errorf("function groups: have %+v, want selector %x restricted to group 6", groups, selector)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// AccessTraceTarget is the transaction replayed by debug_traceAccess: either
+// the hash of a mined transaction or a call object as accepted by eth_call.
+type AccessTraceTarget struct {
+	Hash *common.Hash
+	Call *ethapi.CallArgs
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting a hash string or a call
+// object.
+func (t *AccessTraceTarget) UnmarshalJSON(input []byte) error {
+	if len(input) > 0 && input[0] == '"' {
+		t.Hash = new(common.Hash)
+		return json.Unmarshal(input, t.Hash)
+	}
+	t.Call = new(ethapi.CallArgs)
+	return json.Unmarshal(input, t.Call)
+}
+
+// AccessTraceResult is the outcome of debug_traceAccess: the permission checks
+// in the order the EVM took them, followed by the result of the transaction.
+type AccessTraceResult struct {
+	Checks      []vm.AccessCheckLog `json:"checks"`
+	Failed      bool                `json:"failed"`
+	Error       string              `json:"error,omitempty"`
+	ReturnValue hexutil.Bytes       `json:"returnValue"`
+}
+
+// TraceAccess replays a transaction, or executes a call on top of the state of
+// block blockNr (latest by default), and returns every permission decision the
+// access contract took while running it.
+func (api *PrivateDebugAPI) TraceAccess(ctx context.Context, target AccessTraceTarget, blockNr *rpc.BlockNumber) (*AccessTraceResult, error) {
+	switch {
+	case target.Hash != nil:
+		tx, blockHash, _, index := rawdb.ReadTransaction(api.eth.ChainDb(), *target.Hash)
+		if tx == nil {
+			return nil, fmt.Errorf("transaction %x not found", *target.Hash)
+		}
+		msg, vmctx, statedb, err := api.computeTxEnv(blockHash, int(index), defaultTraceReexec)
+		if err != nil {
+			return nil, err
+		}
+		return api.traceAccess(msg, vmctx, statedb)
+
+	case target.Call != nil:
+		number := rpc.LatestBlockNumber
+		if blockNr != nil {
+			number = *blockNr
+		}
+		statedb, header, err := api.eth.APIBackend.StateAndHeaderByNumber(ctx, number)
+		if statedb == nil || err != nil {
+			return nil, err
+		}
+		args := target.Call
+		gas := uint64(args.Gas)
+		if gas == 0 {
+			gas = header.GasLimit
+		}
+		msg := types.NewMessage(args.From, args.To, 0, args.Value.ToInt(), gas, args.GasPrice.ToInt(), args.Data, false)
+		vmctx := core.NewEVMContext(msg, header, api.eth.blockchain, nil)
+
+		return api.traceAccess(msg, vmctx, statedb)
+	}
+	return nil, fmt.Errorf("transaction hash or call object required")
+}
+
+// traceAccess runs message on top of statedb, capturing the permission checks.
+func (api *PrivateDebugAPI) traceAccess(message core.Message, vmctx vm.Context, statedb *state.StateDB) (*AccessTraceResult, error) {
+	tracer := vm.NewAccessLogger(nil)
+	vmenv := vm.NewEVM(vmctx, statedb, api.config, vm.Config{Debug: true, Tracer: tracer})
+
+	ret, _, failed, err := core.ApplyMessage(vmenv, message, new(core.GasPool).AddGas(message.Gas()))
+	if err != nil {
+		return nil, fmt.Errorf("tracing failed: %v", err)
+	}
+	result := &AccessTraceResult{
+		Checks:      tracer.AccessChecks(),
+		Failed:      failed,
+		ReturnValue: ret,
+	}
+	if result.Checks == nil {
+		result.Checks = []vm.AccessCheckLog{}
+	}
+	if err := tracer.Error(); err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
@@ -0,0 +1,245 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxDenialBlockRange is the maximum number of blocks a single call to
+	// veriteem_getDenials scans.
+	maxDenialBlockRange = 10000
+
+	// defaultDenialLimit and maxDenialLimit bound the number of denials
+	// returned by a single call to veriteem_getDenials.
+	defaultDenialLimit = 100
+	maxDenialLimit     = 1000
+)
+
+// veriteemAPIs returns the RPC services of the Veriteem access control.
+func veriteemAPIs(eth *Ethereum) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "veriteem",
+			Version:   "1.0",
+			Service:   NewPublicVeriteemAPI(eth),
+			Public:    true,
+		},
+	}
+}
+
+// PublicVeriteemAPI provides access to the access control state of the node.
+type PublicVeriteemAPI struct {
+	eth         *Ethereum
+	denials     *core.DenialStore
+	permissions *permissionFeed
+}
+
+// NewPublicVeriteemAPI creates a new Veriteem access control API.
+func NewPublicVeriteemAPI(eth *Ethereum) *PublicVeriteemAPI {
+	api := &PublicVeriteemAPI{
+		eth:     eth,
+		denials: core.NewDenialStore(eth.ChainDb()),
+	}
+	api.permissions = newPermissionFeed(api)
+	return api
+}
+
+// DenialFilter selects the access denials returned by veriteem_getDenials.
+type DenialFilter struct {
+	FromBlock *rpc.BlockNumber `json:"fromBlock"` // First block to scan, earliest by default
+	ToBlock   *rpc.BlockNumber `json:"toBlock"`   // Last block to scan, latest by default
+	Contract  *common.Address  `json:"contract"`  // Only denials of this contract
+	Caller    *common.Address  `json:"caller"`    // Only denials of this caller
+	Limit     *hexutil.Uint64  `json:"limit"`     // Maximum number of denials to return
+}
+
+// DenialPage is a page of access denials. If Next is set, the scan stopped
+// early and more denials may be found from block Next onwards.
+type DenialPage struct {
+	Denials []vm.AccessDenial `json:"denials"`
+	Next    *hexutil.Uint64   `json:"next"`
+}
+
+// GetDenials returns the calls and contract creations of canonical transactions
+// that the access policy denied, oldest first. Calls whose writes were denied
+// are only returned if the denial reverted any. Only the denials of the last
+// core.DenialRetention blocks are kept. A page never ends in the middle of a
+// block, so it may hold slightly more denials than the limit.
+func (api *PublicVeriteemAPI) GetDenials(filter DenialFilter) (*DenialPage, error) {
+	head := api.eth.BlockChain().CurrentBlock().NumberU64()
+
+	from, to := uint64(0), head
+	if filter.FromBlock != nil {
+		from = resolveBlockNumber(*filter.FromBlock, head)
+	}
+	if filter.ToBlock != nil {
+		to = resolveBlockNumber(*filter.ToBlock, head)
+	}
+	if head > core.DenialRetention && from <= head-core.DenialRetention {
+		from = head - core.DenialRetention + 1
+	}
+	limit := uint64(defaultDenialLimit)
+	if filter.Limit != nil {
+		limit = uint64(*filter.Limit)
+	}
+	if limit == 0 || limit > maxDenialLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxDenialLimit)
+	}
+	page := &DenialPage{Denials: []vm.AccessDenial{}}
+	for number := from; number <= to; number++ {
+		if number-from == maxDenialBlockRange || uint64(len(page.Denials)) >= limit {
+			next := hexutil.Uint64(number)
+			page.Next = &next
+			break
+		}
+		for _, denial := range api.denials.Denials(number) {
+			if filter.Contract != nil && denial.Contract != *filter.Contract {
+				continue
+			}
+			if filter.Caller != nil && denial.Caller != *filter.Caller {
+				continue
+			}
+			page.Denials = append(page.Denials, denial)
+		}
+	}
+	return page, nil
+}
+
+// resolveBlockNumber turns a block number of a filter into a height, with the
+// latest and pending blocks, and blocks past the head, resolving to head.
+func resolveBlockNumber(number rpc.BlockNumber, head uint64) uint64 {
+	if number < 0 || uint64(number) > head {
+		return head
+	}
+	return uint64(number)
+}
+
+// ContractLifecycle is the lifecycle state of a contract as recorded by the
+// access contract.
+type ContractLifecycle struct {
+	Contract   common.Address  `json:"contract"`
+	State      string          `json:"state"`                // Name of the state, "unknown" if undefined
+	StateCode  hexutil.Uint64  `json:"stateCode"`            // Raw State of the ContractTable entry
+	NewAddress *common.Address `json:"newAddress,omitempty"` // Address of the contract superseding it, if any
+}
+
+// GetContractState returns the lifecycle state of contract at block blockNr,
+// latest by default.
+func (api *PublicVeriteemAPI) GetContractState(ctx context.Context, contract common.Address, blockNr *rpc.BlockNumber) (*ContractLifecycle, error) {
+	statedb, header, err := api.stateAt(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	status, ok, err := vm.ReadContractStatus(statedb, api.eth.BlockChain().Config(), header.Number, contract)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("access control not active at block %d", header.Number)
+	}
+	lifecycle := &ContractLifecycle{
+		Contract:  contract,
+		State:     status.State.String(),
+		StateCode: hexutil.Uint64(status.State),
+	}
+	if status.NewAddress != (common.Address{}) {
+		lifecycle.NewAddress = &status.NewAddress
+	}
+	return lifecycle, nil
+}
+
+// PermissionSnapshot is the permission state of the chain at a block.
+type PermissionSnapshot struct {
+	Block hexutil.Uint64 `json:"block"`
+	Hash  common.Hash    `json:"hash"`
+	*vm.AccessSnapshot
+}
+
+// GetPermissionSnapshot returns the guardianships, contracts and contributors
+// recorded by the access contract at block blockNr, latest by default. The
+// storage of the contract is read assuming the layout of the AccessRights
+// contract, whether or not its checks are evaluated natively.
+func (api *PublicVeriteemAPI) GetPermissionSnapshot(ctx context.Context, blockNr *rpc.BlockNumber) (*PermissionSnapshot, error) {
+	statedb, header, err := api.stateAt(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	access, ok := api.eth.BlockChain().Config().VeriteemAccess(header.Number)
+	if !ok {
+		return nil, fmt.Errorf("access control not active at block %d", header.Number)
+	}
+	snapshot, err := vm.ReadAccessSnapshot(statedb, access.Contract)
+	if err != nil {
+		return nil, err
+	}
+	return &PermissionSnapshot{
+		Block:          hexutil.Uint64(header.Number.Uint64()),
+		Hash:           header.Hash(),
+		AccessSnapshot: snapshot,
+	}, nil
+}
+
+// PermissionDiff is the change of the permission state of the chain between
+// two blocks.
+type PermissionDiff struct {
+	FromBlock hexutil.Uint64 `json:"fromBlock"`
+	ToBlock   hexutil.Uint64 `json:"toBlock"`
+	*vm.AccessDiff
+}
+
+// GetPermissionDiff returns the permission changes between blocks fromBlock and
+// toBlock, latest by default. Only the net changes are reported: permissions
+// changed and restored within the period don't show.
+func (api *PublicVeriteemAPI) GetPermissionDiff(ctx context.Context, fromBlock rpc.BlockNumber, toBlock *rpc.BlockNumber) (*PermissionDiff, error) {
+	from, err := api.GetPermissionSnapshot(ctx, &fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.GetPermissionSnapshot(ctx, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil {
+		return nil, errors.New("block not found")
+	}
+	return &PermissionDiff{
+		FromBlock:  from.Block,
+		ToBlock:    to.Block,
+		AccessDiff: vm.DiffAccessSnapshots(from.AccessSnapshot, to.AccessSnapshot),
+	}, nil
+}
+
+// stateAt returns the state and header of block blockNr, latest by default.
+func (api *PublicVeriteemAPI) stateAt(ctx context.Context, blockNr *rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	number := rpc.LatestBlockNumber
+	if blockNr != nil {
+		number = *blockNr
+	}
+	return api.eth.APIBackend.StateAndHeaderByNumber(ctx, number)
+}
@@ -0,0 +1,314 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// permissionChainEventChanSize is the size of the channel listening to
+	// the chain events for the permission change subscriptions.
+	permissionChainEventChanSize = 10
+
+	// permissionBlockQueueSize is the number of new blocks queued for their
+	// permission changes to be derived.
+	permissionBlockQueueSize = 128
+
+	// permissionSubChanSize is the number of blocks worth of permission
+	// changes queued for a subscriber.
+	permissionSubChanSize = 128
+)
+
+// Types of permission change notifications.
+const (
+	GuardianAdded       = "guardianAdded"
+	GuardianRemoved     = "guardianRemoved"
+	ContributorChanged  = "contributorChanged"
+	ContractInfoChanged = "contractInfoChanged"
+)
+
+// PermissionChange is a change of the permission state made by a transaction.
+// The access contract emits no events, so the node derives them from the
+// storage of the contract before and after the transaction.
+type PermissionChange struct {
+	Type         string           `json:"type"`
+	Change       string           `json:"change"`           // added, removed or modified
+	Address      common.Address   `json:"address"`          // Guardian, contributor or contract
+	Guardianship hexutil.Uint64   `json:"guardianship"`     // Guardianship of the entry, before removals
+	Fields       []vm.FieldChange `json:"fields,omitempty"` // Changed fields of modified entries
+	BlockNumber  hexutil.Uint64   `json:"blockNumber"`
+	BlockHash    common.Hash      `json:"blockHash"`
+	TxHash       common.Hash      `json:"transactionHash"`
+	TxIndex      hexutil.Uint     `json:"transactionIndex"`
+}
+
+// PermissionChanges creates a subscription that is notified of every guardian
+// added or removed, contributor changed and contract entry changed by the
+// transactions of new canonical blocks, in transaction order. Changes of blocks
+// that are later reorganised out of the chain are not retracted. Subscribers
+// falling too far behind miss the changes of the blocks they can't keep up
+// with.
+func (api *PublicVeriteemAPI) PermissionChanges(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	changes := api.permissions.subscribe()
+	go func() {
+		defer api.permissions.unsubscribe(changes)
+
+		for {
+			select {
+			case batch := <-changes:
+				for i := range batch {
+					notifier.Notify(rpcSub.ID, &batch[i])
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// permissionFeed derives the permission changes of every new canonical block
+// once for all the subscriptions, and hands them to the subscribers without
+// waiting for them, so that slow subscribers hold up neither the chain nor
+// each other.
+type permissionFeed struct {
+	api *PublicVeriteemAPI
+
+	lock sync.Mutex
+	subs map[chan []PermissionChange]struct{}
+	quit chan struct{} // Closed to stop following the chain, nil while not following
+}
+
+// newPermissionFeed creates the permission change feed of api. The chain is
+// only followed while there are subscribers.
+func newPermissionFeed(api *PublicVeriteemAPI) *permissionFeed {
+	return &permissionFeed{
+		api:  api,
+		subs: make(map[chan []PermissionChange]struct{}),
+	}
+}
+
+// subscribe returns a channel receiving the permission changes of every new
+// block that has any.
+func (f *permissionFeed) subscribe() chan []PermissionChange {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.quit == nil {
+		f.quit = make(chan struct{})
+		blocks := make(chan *types.Block, permissionBlockQueueSize)
+		go f.follow(blocks, f.quit)
+		go f.derive(blocks, f.quit)
+	}
+	changes := make(chan []PermissionChange, permissionSubChanSize)
+	f.subs[changes] = struct{}{}
+	return changes
+}
+
+// unsubscribe stops handing permission changes to changes. The chain isn't
+// followed anymore once the last subscriber left.
+func (f *permissionFeed) unsubscribe(changes chan []PermissionChange) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.subs, changes)
+	if len(f.subs) == 0 && f.quit != nil {
+		close(f.quit)
+		f.quit = nil
+	}
+}
+
+// follow queues the new canonical blocks for their permission changes to be
+// derived until quit is closed, dropping blocks if derive falls behind rather
+// than stalling the chain event feed.
+func (f *permissionFeed) follow(blocks chan<- *types.Block, quit <-chan struct{}) {
+	events := make(chan core.ChainEvent, permissionChainEventChanSize)
+	sub := f.api.eth.BlockChain().SubscribeChainEvent(events)
+	defer sub.Unsubscribe()
+	defer close(blocks)
+
+	for {
+		select {
+		case ev := <-events:
+			select {
+			case blocks <- ev.Block:
+			default:
+				log.Warn("Dropping permission changes of block", "number", ev.Block.Number(), "hash", ev.Hash)
+			}
+		case <-quit:
+			return
+		case <-sub.Err():
+			return
+		}
+	}
+}
+
+// derive derives the permission changes of the queued blocks and hands them to
+// every subscriber with room for them, until quit is closed. The subscribers of
+// a later run of the feed don't get the changes of the blocks queued before.
+func (f *permissionFeed) derive(blocks <-chan *types.Block, quit <-chan struct{}) {
+	for block := range blocks {
+		changes, err := f.api.permissionChanges(block)
+		if err != nil {
+			log.Warn("Failed to derive permission changes", "number", block.Number(), "hash", block.Hash(), "err", err)
+			continue
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		f.lock.Lock()
+		select {
+		case <-quit:
+			f.lock.Unlock()
+			return
+		default:
+		}
+		for sub := range f.subs {
+			select {
+			case sub <- changes:
+			default:
+				log.Warn("Permission change subscriber fell behind", "number", block.Number(), "hash", block.Hash())
+			}
+		}
+		f.lock.Unlock()
+	}
+}
+
+// permissionChanges returns the permission changes made by the transactions of
+// block. Blocks leaving the permissions unchanged are recognised by comparing
+// the access contract before and after the block; the others are replayed to
+// attribute every change to its transaction. The storage of the access contract
+// is read assuming the layout of the AccessRights contract.
+func (api *PublicVeriteemAPI) permissionChanges(block *types.Block) ([]PermissionChange, error) {
+	blockchain := api.eth.BlockChain()
+	config := blockchain.Config()
+
+	access, ok := config.VeriteemAccess(block.Number())
+	if !ok || block.NumberU64() == 0 {
+		return nil, nil
+	}
+	parent := blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %x not found", block.ParentHash())
+	}
+	statedb, err := blockchain.StateAt(parent.Root())
+	if err != nil {
+		return nil, err
+	}
+	final, err := blockchain.StateAt(block.Root())
+	if err != nil {
+		return nil, err
+	}
+	before, err := vm.ReadAccessSnapshot(statedb, access.Contract)
+	if err != nil {
+		return nil, err
+	}
+	after, err := vm.ReadAccessSnapshot(final, access.Contract)
+	if err != nil {
+		return nil, err
+	}
+	if vm.DiffAccessSnapshots(before, after).Empty() {
+		return nil, nil
+	}
+	var (
+		changes []PermissionChange
+		signer  = types.MakeSigner(config, block.Number())
+	)
+	for i, tx := range block.Transactions() {
+		msg, _ := tx.AsMessage(signer)
+		vmctx := core.NewEVMContext(msg, block.Header(), blockchain, nil)
+
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		vmenv := vm.NewEVM(vmctx, statedb, config, vm.Config{})
+		if _, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas())); err != nil {
+			return nil, fmt.Errorf("tx %x failed: %v", tx.Hash(), err)
+		}
+		statedb.Finalise(true)
+
+		snapshot, err := vm.ReadAccessSnapshot(statedb, access.Contract)
+		if err != nil {
+			return nil, err
+		}
+		diff := vm.DiffAccessSnapshots(before, snapshot)
+		before = snapshot
+
+		for _, change := range newPermissionChanges(diff) {
+			change.BlockNumber = hexutil.Uint64(block.NumberU64())
+			change.BlockHash = block.Hash()
+			change.TxHash = tx.Hash()
+			change.TxIndex = hexutil.Uint(i)
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// newPermissionChanges turns the permission changes of a transaction into
+// notifications, without their block and transaction.
+func newPermissionChanges(diff *vm.AccessDiff) []PermissionChange {
+	var changes []PermissionChange
+	for _, change := range diff.Guardians {
+		typ := GuardianAdded
+		if change.Change == vm.ChangeRemoved {
+			typ = GuardianRemoved
+		}
+		changes = append(changes, PermissionChange{
+			Type:         typ,
+			Change:       change.Change,
+			Address:      change.Guardian,
+			Guardianship: change.Guardianship,
+		})
+	}
+	for _, change := range diff.Contributors {
+		changes = append(changes, PermissionChange{
+			Type:         ContributorChanged,
+			Change:       change.Change,
+			Address:      change.Address,
+			Guardianship: change.Guardianship,
+			Fields:       change.Fields,
+		})
+	}
+	for _, change := range diff.Contracts {
+		changes = append(changes, PermissionChange{
+			Type:         ContractInfoChanged,
+			Change:       change.Change,
+			Address:      change.Address,
+			Guardianship: change.Guardianship,
+			Fields:       change.Fields,
+		})
+	}
+	return changes
+}
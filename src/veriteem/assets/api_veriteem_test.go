@@ -0,0 +1,323 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/veriteem/veriteemtest"
+)
+
+// permissionTxGas is the gas limit of the administration transactions of the
+// tests, low enough for a few of them to fit in a block.
+const permissionTxGas = 1000000
+
+// permissionTester is a Veriteem chain served by the Veriteem API.
+type permissionTester struct {
+	t      *testing.T
+	db     ethdb.Database
+	chain  *core.BlockChain
+	api    *PublicVeriteemAPI
+	access common.Address // Address of the access contract
+	abi    abi.ABI        // ABI of the AccessRights contract
+}
+
+// newPermissionTester boots a chain from the Veriteem genesis in testdata, with
+// every Ethereum fork active from block zero, and serves it through the API.
+func newPermissionTester(t *testing.T) *permissionTester {
+	genesis, err := veriteemtest.LoadGenesis(filepath.Join("testdata", "genesis.json"))
+	if err != nil {
+		t.Fatalf("failed to load genesis: %v", err)
+	}
+	config := *genesis.Config
+	config.HomesteadBlock = new(big.Int)
+	config.EIP150Block = new(big.Int)
+	config.EIP155Block = new(big.Int)
+	config.EIP158Block = new(big.Int)
+	config.ByzantiumBlock = new(big.Int)
+	genesis.Config = &config
+
+	access, ok := config.VeriteemAccess(common.Big0)
+	if !ok {
+		t.Fatalf("genesis doesn't activate access control")
+	}
+	parsed, err := abi.JSON(strings.NewReader(veriteemtest.AccessRightsABI))
+	if err != nil {
+		t.Fatalf("failed to parse access contract ABI: %v", err)
+	}
+	db := ethdb.NewMemDatabase()
+	genesis.MustCommit(db)
+
+	blockchain, err := core.NewBlockChain(db, nil, &config, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	eth := &Ethereum{chainDb: db, blockchain: blockchain}
+	eth.APIBackend = &EthAPIBackend{eth: eth}
+
+	return &permissionTester{
+		t:      t,
+		db:     db,
+		chain:  blockchain,
+		api:    NewPublicVeriteemAPI(eth),
+		access: access.Contract,
+		abi:    parsed,
+	}
+}
+
+// adminTx is a call of an administration method of the access contract.
+type adminTx struct {
+	from   *veriteemtest.Account
+	method string
+	args   []interface{}
+}
+
+// admin returns a call of method of the access contract on behalf of from.
+func admin(from *veriteemtest.Account, method string, args ...interface{}) adminTx {
+	return adminTx{from: from, method: method, args: args}
+}
+
+// mine appends a block running txs in order to the chain and returns it.
+func (pt *permissionTester) mine(txs ...adminTx) *types.Block {
+	pt.t.Helper()
+
+	blocks, _ := core.GenerateChain(pt.chain.Config(), pt.chain.CurrentBlock(), ethash.NewFaker(), pt.db, 1, func(i int, b *core.BlockGen) {
+		for _, call := range txs {
+			input, err := pt.abi.Pack(call.method, call.args...)
+			if err != nil {
+				pt.t.Fatalf("failed to pack %s: %v", call.method, err)
+			}
+			tx := types.NewTransaction(b.TxNonce(call.from.Address), pt.access, new(big.Int), permissionTxGas, new(big.Int), input)
+			tx, err = types.SignTx(tx, types.HomesteadSigner{}, call.from.Key)
+			if err != nil {
+				pt.t.Fatalf("failed to sign %s: %v", call.method, err)
+			}
+			b.AddTx(tx)
+		}
+	})
+	if _, err := pt.chain.InsertChain(blocks); err != nil {
+		pt.t.Fatalf("failed to insert block: %v", err)
+	}
+	return blocks[0]
+}
+
+// Tests that the permission snapshot is served with the tables of the access
+// contract at the requested block next to the block number and hash.
+func TestPermissionSnapshotJSON(t *testing.T) {
+	pt := newPermissionTester(t)
+
+	var (
+		guardian = veriteemtest.NewAccount()
+		member   = veriteemtest.NewAccount()
+	)
+	block := pt.mine(
+		admin(guardian, "InitGuardianship"),
+		admin(guardian, "CreateContributor", member.Address, "member", big.NewInt(5), big.NewInt(1)),
+	)
+	pt.mine() // The snapshot of an older block is requested
+
+	number := rpc.BlockNumber(block.NumberU64())
+	snapshot, err := pt.api.GetPermissionSnapshot(context.Background(), &number)
+	if err != nil {
+		t.Fatalf("failed to take snapshot: %v", err)
+	}
+	blob, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("failed to encode snapshot: %v", err)
+	}
+	var decoded struct {
+		Block         hexutil.Uint64 `json:"block"`
+		Hash          common.Hash    `json:"hash"`
+		Contract      common.Address `json:"contract"`
+		Guardianships []struct {
+			Index        hexutil.Uint64    `json:"index"`
+			Guardians    [2]common.Address `json:"guardians"`
+			Contracts    []common.Address  `json:"contracts"`
+			Contributors []common.Address  `json:"contributors"`
+		} `json:"guardianships"`
+		Contracts    []json.RawMessage `json:"contracts"`
+		Contributors []struct {
+			Address      common.Address `json:"address"`
+			Name         string         `json:"name"`
+			Guardianship hexutil.Uint64 `json:"guardianship"`
+			Limit        *hexutil.Big   `json:"limit"`
+			AccessGroup  *hexutil.Big   `json:"accessGroup"`
+		} `json:"contributors"`
+	}
+	if err := json.Unmarshal(blob, &decoded); err != nil {
+		t.Fatalf("failed to decode snapshot %s: %v", blob, err)
+	}
+	if uint64(decoded.Block) != block.NumberU64() || decoded.Hash != block.Hash() {
+		t.Errorf("block mismatch: have #%d %x, want #%d %x", decoded.Block, decoded.Hash, block.NumberU64(), block.Hash())
+	}
+	if decoded.Contract != pt.access {
+		t.Errorf("contract mismatch: have %x, want %x", decoded.Contract, pt.access)
+	}
+	if len(decoded.Guardianships) != 1 {
+		t.Fatalf("guardianship count mismatch: have %d, want 1 in %s", len(decoded.Guardianships), blob)
+	}
+	if gs := decoded.Guardianships[0]; gs.Index != 1 || gs.Guardians[0] != guardian.Address || len(gs.Contributors) != 1 || gs.Contributors[0] != member.Address {
+		t.Errorf("guardianship mismatch: have %+v", gs)
+	}
+	if decoded.Contracts == nil || len(decoded.Contracts) != 0 {
+		t.Errorf("contracts mismatch: have %v, want an empty list", decoded.Contracts)
+	}
+	if len(decoded.Contributors) != 1 {
+		t.Fatalf("contributor count mismatch: have %d, want 1 in %s", len(decoded.Contributors), blob)
+	}
+	if c := decoded.Contributors[0]; c.Address != member.Address || c.Name != "member" || c.Guardianship != 1 || c.Limit.ToInt().Uint64() != 5 || c.AccessGroup.ToInt().Uint64() != 1 {
+		t.Errorf("contributor mismatch: have %+v", c)
+	}
+}
+
+// Tests that the permission diff between two blocks reports the guardian and the
+// contributor added in between, and nothing of the blocks before.
+func TestPermissionDiff(t *testing.T) {
+	pt := newPermissionTester(t)
+
+	var (
+		guardian  = veriteemtest.NewAccount()
+		candidate = veriteemtest.NewAccount()
+		member    = veriteemtest.NewAccount()
+	)
+	from := pt.mine(admin(guardian, "InitGuardianship"))
+	pt.mine(admin(guardian, "GuardianshipVote", candidate.Address, true))
+	to := pt.mine(admin(guardian, "CreateContributor", member.Address, "member", big.NewInt(5), big.NewInt(1)))
+	pt.mine() // The diff up to an older block is requested
+
+	number := rpc.BlockNumber(to.NumberU64())
+	diff, err := pt.api.GetPermissionDiff(context.Background(), rpc.BlockNumber(from.NumberU64()), &number)
+	if err != nil {
+		t.Fatalf("failed to diff blocks: %v", err)
+	}
+	if uint64(diff.FromBlock) != from.NumberU64() || uint64(diff.ToBlock) != to.NumberU64() {
+		t.Errorf("range mismatch: have %d-%d, want %d-%d", diff.FromBlock, diff.ToBlock, from.NumberU64(), to.NumberU64())
+	}
+	if len(diff.Guardians) != 1 {
+		t.Fatalf("guardian change count mismatch: have %d, want 1: %+v", len(diff.Guardians), diff.Guardians)
+	}
+	if change := diff.Guardians[0]; change.Guardian != candidate.Address || change.Guardianship != 2 || change.Change != vm.ChangeAdded {
+		t.Errorf("guardian change mismatch: have %+v", change)
+	}
+	if len(diff.Contributors) != 1 {
+		t.Fatalf("contributor change count mismatch: have %d, want 1: %+v", len(diff.Contributors), diff.Contributors)
+	}
+	if change := diff.Contributors[0]; change.Address != member.Address || change.Guardianship != 1 || change.Change != vm.ChangeAdded {
+		t.Errorf("contributor change mismatch: have %+v", change)
+	}
+	if len(diff.Contracts) != 0 {
+		t.Errorf("unexpected contract changes: %+v", diff.Contracts)
+	}
+	// Without an end block, the diff runs up to the latest block
+	latest, err := pt.api.GetPermissionDiff(context.Background(), rpc.BlockNumber(to.NumberU64()), nil)
+	if err != nil {
+		t.Fatalf("failed to diff up to latest block: %v", err)
+	}
+	if uint64(latest.ToBlock) != pt.chain.CurrentBlock().NumberU64() || !latest.Empty() {
+		t.Errorf("latest diff mismatch: have %+v", latest)
+	}
+}
+
+// nextPermissionChanges waits for the next permission changes of the feed.
+func nextPermissionChanges(t *testing.T, changes chan []PermissionChange) []PermissionChange {
+	t.Helper()
+
+	select {
+	case batch := <-changes:
+		return batch
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no permission changes notified")
+		return nil
+	}
+}
+
+// Tests that the permission changes of a block are notified one by one, in
+// order, each with the transaction that made it, and that the feed stops
+// following the chain once its last subscriber left and resumes on the next
+// subscription without handing over blocks from before.
+func TestPermissionChangeNotifications(t *testing.T) {
+	pt := newPermissionTester(t)
+	feed := pt.api.permissions
+
+	var (
+		guardian  = veriteemtest.NewAccount()
+		candidate = veriteemtest.NewAccount()
+		member    = veriteemtest.NewAccount()
+		other     = veriteemtest.NewAccount()
+		later     = veriteemtest.NewAccount()
+	)
+	changes := feed.subscribe()
+	pt.mine() // Blocks without permission changes aren't notified
+	block := pt.mine(
+		admin(guardian, "InitGuardianship"),
+		admin(guardian, "CreateContributor", member.Address, "member", big.NewInt(5), big.NewInt(1)),
+		admin(guardian, "GuardianshipVote", candidate.Address, true),
+	)
+	want := []PermissionChange{
+		{Type: GuardianAdded, Change: vm.ChangeAdded, Address: guardian.Address, Guardianship: 1},
+		{Type: ContributorChanged, Change: vm.ChangeAdded, Address: member.Address, Guardianship: 1},
+		{Type: GuardianAdded, Change: vm.ChangeAdded, Address: candidate.Address, Guardianship: 2},
+	}
+	batch := nextPermissionChanges(t, changes)
+	if len(batch) != len(want) {
+		t.Fatalf("change count mismatch: have %d, want %d: %+v", len(batch), len(want), batch)
+	}
+	for i, change := range batch {
+		tx := block.Transactions()[i]
+		if change.Type != want[i].Type || change.Change != want[i].Change || change.Address != want[i].Address || change.Guardianship != want[i].Guardianship {
+			t.Errorf("change %d mismatch: have %+v, want %+v", i, change, want[i])
+		}
+		if uint64(change.BlockNumber) != block.NumberU64() || change.BlockHash != block.Hash() || change.TxHash != tx.Hash() || int(change.TxIndex) != i {
+			t.Errorf("change %d origin mismatch: have #%d %x tx %d %x, want #%d %x tx %d %x",
+				i, change.BlockNumber, change.BlockHash, change.TxIndex, change.TxHash, block.NumberU64(), block.Hash(), i, tx.Hash())
+		}
+	}
+	// The last subscriber leaving stops the feed
+	feed.unsubscribe(changes)
+	feed.lock.Lock()
+	following := feed.quit != nil
+	feed.lock.Unlock()
+	if following {
+		t.Fatalf("feed still following the chain without subscribers")
+	}
+	pt.mine(admin(guardian, "CreateContributor", other.Address, "other", big.NewInt(5), big.NewInt(1)))
+
+	// A new subscription restarts it, with the changes of new blocks only
+	changes = feed.subscribe()
+	defer feed.unsubscribe(changes)
+
+	block = pt.mine(admin(guardian, "CreateContributor", later.Address, "later", big.NewInt(5), big.NewInt(1)))
+	batch = nextPermissionChanges(t, changes)
+	if len(batch) != 1 || batch[0].Address != later.Address || batch[0].BlockHash != block.Hash() {
+		t.Fatalf("restarted feed mismatch: have %+v, want the creation of %x in block %x", batch, later.Address, block.Hash())
+	}
+}
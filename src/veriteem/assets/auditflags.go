@@ -0,0 +1,82 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	AccessAuditLevelFlag = cli.IntFlag{
+		Name:  "access.audit.level",
+		Usage: "Log level contract permission checks are written at: 1=error, 2=warn, 3=info, 4=debug, 5=detail",
+		Value: int(vm.DefaultAccessAuditConfig.Level),
+	}
+	AccessAuditContractsFlag = cli.StringFlag{
+		Name:  "access.audit.contracts",
+		Usage: "Comma separated contract addresses to log permission checks on (default = all)",
+	}
+	AccessAuditSampleFlag = cli.Uint64Flag{
+		Name:  "access.audit.sample",
+		Usage: "Log only one in this many permission checks granting reads, denied reads are always logged",
+		Value: vm.DefaultAccessAuditConfig.Sample,
+	}
+	AccessAuditSinkFlag = cli.StringFlag{
+		Name:  "access.audit.sink",
+		Usage: "File to append a JSON line to for every access denial changing the outcome of a processed transaction",
+	}
+
+	accessAuditFlags = []cli.Flag{
+		AccessAuditLevelFlag,
+		AccessAuditContractsFlag,
+		AccessAuditSampleFlag,
+		AccessAuditSinkFlag,
+	}
+)
+
+// setupAccessAudit configures the logging of contract permission checks from
+// the command line flags.
+func setupAccessAudit(ctx *cli.Context) {
+	config := vm.DefaultAccessAuditConfig
+	config.Level = log.Lvl(ctx.GlobalInt(AccessAuditLevelFlag.Name))
+	config.Sample = ctx.GlobalUint64(AccessAuditSampleFlag.Name)
+
+	if contracts := ctx.GlobalString(AccessAuditContractsFlag.Name); contracts != "" {
+		for _, contract := range strings.Split(contracts, ",") {
+			contract = strings.TrimSpace(contract)
+			if !common.IsHexAddress(contract) {
+				utils.Fatalf("Invalid contract address in --%s: %s", AccessAuditContractsFlag.Name, contract)
+			}
+			config.Contracts = append(config.Contracts, common.HexToAddress(contract))
+		}
+	}
+	if path := ctx.GlobalString(AccessAuditSinkFlag.Name); path != "" {
+		sink, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			utils.Fatalf("Failed to open access audit sink: %v", err)
+		}
+		config.Sink = sink
+	}
+	vm.SetAccessAudit(config)
+}
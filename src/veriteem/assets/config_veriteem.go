@@ -0,0 +1,298 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Default function selectors of the AccessRights contract methods used by the EVM.
+var (
+	VeriteemVerifyAccessSelector           = []byte{0x45, 0xe4, 0xe5, 0xe4} // VerifyContractAccess(address,address)
+	VeriteemGuardianshipIndexSelector      = []byte{0x10, 0xbd, 0x7f, 0xc5} // GuardianshipIndex(address)
+	VeriteemCreateContractSelector         = []byte{0xf5, 0xb7, 0xf3, 0xf6} // CreateContract(address)
+	VeriteemUpdateContributorBlockSelector = []byte{0xcf, 0x0d, 0x1e, 0xa0} // UpdateContributorBlock()
+	VeriteemReadContractInfoSelector       = []byte{0x76, 0x59, 0x99, 0x17} // ReadContractInfo(address)
+	VeriteemReadContributorSelector        = []byte{0xe7, 0xbf, 0x14, 0xc1} // ReadContributor(address)
+)
+
+const (
+	VeriteemAccessCheckGas uint64 = 5000 // Charged for the permission check of a call, from the access gas fork on
+	VeriteemCreateCheckGas uint64 = 5000 // Charged for the permission check of a contract creation, from the access gas fork on
+)
+
+// DefaultVeriteemConfig is the access control configuration of chains whose
+// genesis predates the veriteem config section: the AccessRights contract
+// installed at 0x...0100 in the genesis block, active from block zero.
+var DefaultVeriteemConfig = &VeriteemConfig{
+	AccessContract: common.HexToAddress("0x0000000000000000000000000000000000000100"),
+}
+
+// VeriteemConfig is the access control related config of a Veriteem chain. It
+// locates the AccessRights contract consulted by the EVM and schedules its
+// replacement by upgraded contracts.
+//
+// Every fork block changes the outcome of the transactions after it, so on a
+// running chain they have to be scheduled above the current head, and agreed
+// on by every node, just like the Ethereum forks.
+type VeriteemConfig struct {
+	AccessContract common.Address     `json:"accessContract"`        // Address of the AccessRights contract
+	AccessBlock    *big.Int           `json:"accessBlock,omitempty"` // Block access control activates at (nil = genesis)
+	Selectors      *VeriteemSelectors `json:"selectors,omitempty"`   // Method selectors if they differ from the defaults
+	Native         bool               `json:"native,omitempty"`      // Whether to evaluate the contract's storage natively
+	Upgrades       []VeriteemUpgrade  `json:"upgrades,omitempty"`    // Access contract replacements, ordered by block
+
+	CallKindsBlock    *big.Int `json:"callKindsBlock,omitempty"`    // CALLCODE, DELEGATECALL and STATICCALL are checked like calls (nil = no fork)
+	DenialErrorsBlock *big.Int `json:"denialErrorsBlock,omitempty"` // Denied calls report why they failed (nil = no fork)
+	CreateDenialBlock *big.Int `json:"createDenialBlock,omitempty"` // Denied creations fail without deploying code (nil = no fork)
+	StaticReadBlock   *big.Int `json:"staticReadBlock,omitempty"`   // Static calls are only checked for read access (nil = no fork)
+	RateLimitBlock    *big.Int `json:"rateLimitBlock,omitempty"`    // Allowed writes update the contributor's LastBlockNumber (nil = no fork)
+	AccessGasBlock    *big.Int `json:"accessGasBlock,omitempty"`    // Permission checks are charged for (nil = no fork)
+	MigrationBlock    *big.Int `json:"migrationBlock,omitempty"`    // Calls to migrated contracts are redirected (nil = no fork)
+	LifecycleBlock    *big.Int `json:"lifecycleBlock,omitempty"`    // Contract lifecycle states restrict access (nil = no fork)
+	SelectorBlock     *big.Int `json:"selectorBlock,omitempty"`     // Fallback calls are checked by their own rules (nil = no fork)
+
+	ForwardMigrated     bool     `json:"forwardMigrated,omitempty"`     // Forward calls to migrated contracts instead of rejecting them
+	FallbackAccessGroup *big.Int `json:"fallbackAccessGroup,omitempty"` // Access groups allowed to make fallback calls, from the selector fork on (nil or 0 = all)
+}
+
+// UnmarshalJSON decodes the config, rejecting upgrades that aren't scheduled at
+// strictly increasing blocks, as AccessAt relies on their order.
+func (c *VeriteemConfig) UnmarshalJSON(input []byte) error {
+	type config VeriteemConfig
+	var dec config
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	for i, upgrade := range dec.Upgrades {
+		if upgrade.Block == nil {
+			return fmt.Errorf("veriteem upgrade %d has no block", i)
+		}
+		if i > 0 && upgrade.Block.Cmp(dec.Upgrades[i-1].Block) <= 0 {
+			return fmt.Errorf("veriteem upgrade %d at block %v not after upgrade %d at block %v", i, upgrade.Block, i-1, dec.Upgrades[i-1].Block)
+		}
+	}
+	*c = VeriteemConfig(dec)
+	return nil
+}
+
+// VeriteemUpgrade replaces the access contract from a fork block onwards.
+type VeriteemUpgrade struct {
+	Block          *big.Int           `json:"block"`               // Block the replacement takes effect at
+	AccessContract common.Address     `json:"accessContract"`      // Address of the replacement contract
+	Selectors      *VeriteemSelectors `json:"selectors,omitempty"` // Method selectors of the replacement
+	Native         bool               `json:"native,omitempty"`    // Whether to evaluate the replacement natively
+}
+
+// VeriteemSelectors overrides the 4 byte selectors of the access contract
+// methods invoked by the EVM. Empty fields keep their default value.
+type VeriteemSelectors struct {
+	VerifyAccess           hexutil.Bytes `json:"verifyAccess,omitempty"`
+	GuardianshipIndex      hexutil.Bytes `json:"guardianshipIndex,omitempty"`
+	CreateContract         hexutil.Bytes `json:"createContract,omitempty"`
+	UpdateContributorBlock hexutil.Bytes `json:"updateContributorBlock,omitempty"`
+	ReadContractInfo       hexutil.Bytes `json:"readContractInfo,omitempty"`
+	ReadContributor        hexutil.Bytes `json:"readContributor,omitempty"`
+}
+
+// VeriteemAccess is the access contract in force at a given block. Native is
+// only set for contracts using the storage layout of scripts/AccessRights.sol,
+// whose permission checks the EVM may then answer without running their code.
+type VeriteemAccess struct {
+	Contract                       common.Address
+	VerifyAccessSelector           []byte
+	GuardianshipIndexSelector      []byte
+	CreateContractSelector         []byte
+	UpdateContributorBlockSelector []byte
+	ReadContractInfoSelector       []byte
+	ReadContributorSelector        []byte
+	Native                         bool
+
+	CallKinds     bool // Whether CALLCODE, DELEGATECALL and STATICCALL are checked like calls
+	DenialErrors  bool // Whether denied calls fail with the reason of the denial
+	CreateDenial  bool // Whether denied creations fail before creating an account
+	StaticReads   bool // Whether static calls are only checked for read access
+	RecordWrites  bool // Whether allowed writes are recorded for the contributor rate limit
+	ChargeGas     bool // Whether permission checks are charged for
+	Migrations    bool // Whether calls to migrated contracts are redirected
+	Forward       bool // Whether redirected calls are forwarded to the new address rather than rejected
+	Lifecycle     bool // Whether paused and retired contracts are restricted
+	SelectorRules bool // Whether fallback calls are checked by their own rules
+
+	FallbackAccessGroup *big.Int // Access groups allowed to make fallback calls, nil if unrestricted
+}
+
+// AccessAt returns the access contract in force at block num. The second
+// return value is false if access control isn't active yet.
+func (c *VeriteemConfig) AccessAt(num *big.Int) (VeriteemAccess, bool) {
+	if c.AccessBlock != nil && !isForked(c.AccessBlock, num) {
+		return VeriteemAccess{}, false
+	}
+	access := newVeriteemAccess(c.AccessContract, c.Selectors, c.Native)
+	for _, upgrade := range c.Upgrades {
+		if !isForked(upgrade.Block, num) {
+			break
+		}
+		access = newVeriteemAccess(upgrade.AccessContract, upgrade.Selectors, upgrade.Native)
+	}
+	access.CallKinds = isForked(c.CallKindsBlock, num)
+	access.DenialErrors = isForked(c.DenialErrorsBlock, num)
+	access.CreateDenial = isForked(c.CreateDenialBlock, num)
+	access.StaticReads = isForked(c.StaticReadBlock, num)
+	access.RecordWrites = isForked(c.RateLimitBlock, num)
+	access.ChargeGas = isForked(c.AccessGasBlock, num)
+	access.Migrations = isForked(c.MigrationBlock, num)
+	access.Forward = c.ForwardMigrated
+	access.Lifecycle = isForked(c.LifecycleBlock, num)
+	access.SelectorRules = isForked(c.SelectorBlock, num)
+	if access.SelectorRules {
+		access.FallbackAccessGroup = c.fallbackAccessGroup()
+	}
+	return access, true
+}
+
+// newVeriteemAccess resolves the selectors of an access contract, falling
+// back to the defaults for any that aren't overridden.
+func newVeriteemAccess(contract common.Address, selectors *VeriteemSelectors, native bool) VeriteemAccess {
+	access := VeriteemAccess{
+		Contract:                       contract,
+		VerifyAccessSelector:           VeriteemVerifyAccessSelector,
+		GuardianshipIndexSelector:      VeriteemGuardianshipIndexSelector,
+		CreateContractSelector:         VeriteemCreateContractSelector,
+		UpdateContributorBlockSelector: VeriteemUpdateContributorBlockSelector,
+		ReadContractInfoSelector:       VeriteemReadContractInfoSelector,
+		ReadContributorSelector:        VeriteemReadContributorSelector,
+		Native:                         native,
+	}
+	if selectors != nil {
+		if len(selectors.VerifyAccess) > 0 {
+			access.VerifyAccessSelector = selectors.VerifyAccess
+		}
+		if len(selectors.GuardianshipIndex) > 0 {
+			access.GuardianshipIndexSelector = selectors.GuardianshipIndex
+		}
+		if len(selectors.CreateContract) > 0 {
+			access.CreateContractSelector = selectors.CreateContract
+		}
+		if len(selectors.UpdateContributorBlock) > 0 {
+			access.UpdateContributorBlockSelector = selectors.UpdateContributorBlock
+		}
+		if len(selectors.ReadContractInfo) > 0 {
+			access.ReadContractInfoSelector = selectors.ReadContractInfo
+		}
+		if len(selectors.ReadContributor) > 0 {
+			access.ReadContributorSelector = selectors.ReadContributor
+		}
+	}
+	return access
+}
+
+// VeriteemAccess returns the access contract the EVM consults at block num,
+// using DefaultVeriteemConfig if the chain config has no veriteem section.
+func (c *ChainConfig) VeriteemAccess(num *big.Int) (VeriteemAccess, bool) {
+	if c.Veriteem == nil {
+		return DefaultVeriteemConfig.AccessAt(num)
+	}
+	return c.Veriteem.AccessAt(num)
+}
+
+// checkVeriteemCompatible returns the first conflict between the access control
+// of c, which the chain follows up to head, and that of newcfg. It is called
+// by checkCompatible, so that a node refuses to change the access contracts or
+// forks of blocks it already processed unless the chain is rewound.
+func (c *ChainConfig) checkVeriteemCompatible(newcfg *ChainConfig, head *big.Int) *ConfigCompatError {
+	stored, updated := c.Veriteem, newcfg.Veriteem
+	if stored == nil {
+		stored = DefaultVeriteemConfig
+	}
+	if updated == nil {
+		updated = DefaultVeriteemConfig
+	}
+	// The access contracts in force, from the access block on
+	storedContracts, updatedContracts := stored.accessSchedule(), updated.accessSchedule()
+	for i := 0; i < len(storedContracts) || i < len(updatedContracts); i++ {
+		var s, u VeriteemUpgrade
+		if i < len(storedContracts) {
+			s = storedContracts[i]
+		}
+		if i < len(updatedContracts) {
+			u = updatedContracts[i]
+		}
+		if !isForked(s.Block, head) && !isForked(u.Block, head) {
+			break
+		}
+		if !configNumEqual(s.Block, u.Block) || !reflect.DeepEqual(newVeriteemAccess(s.AccessContract, s.Selectors, s.Native), newVeriteemAccess(u.AccessContract, u.Selectors, u.Native)) {
+			what := "Veriteem access contract"
+			if i > 0 {
+				what = fmt.Sprintf("Veriteem access contract upgrade %d", i-1)
+			}
+			return newCompatError(what, s.Block, u.Block)
+		}
+	}
+	forks := []struct {
+		what            string
+		stored, updated *big.Int
+	}{
+		{"Veriteem call kinds fork block", stored.CallKindsBlock, updated.CallKindsBlock},
+		{"Veriteem denial errors fork block", stored.DenialErrorsBlock, updated.DenialErrorsBlock},
+		{"Veriteem create denial fork block", stored.CreateDenialBlock, updated.CreateDenialBlock},
+		{"Veriteem static read fork block", stored.StaticReadBlock, updated.StaticReadBlock},
+		{"Veriteem rate limit fork block", stored.RateLimitBlock, updated.RateLimitBlock},
+		{"Veriteem access gas fork block", stored.AccessGasBlock, updated.AccessGasBlock},
+		{"Veriteem migration fork block", stored.MigrationBlock, updated.MigrationBlock},
+		{"Veriteem lifecycle fork block", stored.LifecycleBlock, updated.LifecycleBlock},
+		{"Veriteem selector fork block", stored.SelectorBlock, updated.SelectorBlock},
+	}
+	for _, fork := range forks {
+		if isForkIncompatible(fork.stored, fork.updated, head) {
+			return newCompatError(fork.what, fork.stored, fork.updated)
+		}
+	}
+	if isForked(stored.MigrationBlock, head) && stored.ForwardMigrated != updated.ForwardMigrated {
+		return newCompatError("Veriteem forward migrated flag", stored.MigrationBlock, updated.MigrationBlock)
+	}
+	if isForked(stored.SelectorBlock, head) && !configNumEqual(stored.fallbackAccessGroup(), updated.fallbackAccessGroup()) {
+		return newCompatError("Veriteem fallback access group", stored.SelectorBlock, updated.SelectorBlock)
+	}
+	return nil
+}
+
+// accessSchedule returns the access contracts of the config in the order they
+// take effect, starting with the original one at the access block.
+func (c *VeriteemConfig) accessSchedule() []VeriteemUpgrade {
+	block := c.AccessBlock
+	if block == nil {
+		block = new(big.Int)
+	}
+	schedule := []VeriteemUpgrade{{Block: block, AccessContract: c.AccessContract, Selectors: c.Selectors, Native: c.Native}}
+	return append(schedule, c.Upgrades...)
+}
+
+// fallbackAccessGroup returns the access groups allowed to make fallback calls,
+// nil if they are unrestricted.
+func (c *VeriteemConfig) fallbackAccessGroup() *big.Int {
+	if c.FallbackAccessGroup == nil || c.FallbackAccessGroup.Sign() <= 0 {
+		return nil
+	}
+	return c.FallbackAccessGroup
+}
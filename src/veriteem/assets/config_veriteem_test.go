@@ -0,0 +1,186 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVeriteemAccessAt(t *testing.T) {
+	var (
+		original = common.HexToAddress("0x0000000000000000000000000000000000000100")
+		upgraded = common.HexToAddress("0x0000000000000000000000000000000000000200")
+		final    = common.HexToAddress("0x0000000000000000000000000000000000000300")
+		selector = []byte{0x01, 0x02, 0x03, 0x04}
+	)
+	config := &VeriteemConfig{
+		AccessContract: original,
+		AccessBlock:    big.NewInt(5),
+		Upgrades: []VeriteemUpgrade{
+			{Block: big.NewInt(10), AccessContract: upgraded, Selectors: &VeriteemSelectors{VerifyAccess: selector}},
+			{Block: big.NewInt(20), AccessContract: final},
+		},
+	}
+	tests := []struct {
+		number   int64
+		active   bool
+		contract common.Address
+		verify   []byte
+	}{
+		{0, false, common.Address{}, nil},
+		{4, false, common.Address{}, nil},
+		{5, true, original, VeriteemVerifyAccessSelector},
+		{9, true, original, VeriteemVerifyAccessSelector},
+		{10, true, upgraded, selector},
+		{19, true, upgraded, selector},
+		{20, true, final, VeriteemVerifyAccessSelector},
+	}
+	for _, tt := range tests {
+		access, active := config.AccessAt(big.NewInt(tt.number))
+		if active != tt.active || access.Contract != tt.contract || !bytes.Equal(access.VerifyAccessSelector, tt.verify) {
+			t.Errorf("block %d: have %x (selector %x, active %v), want %x (selector %x, active %v)",
+				tt.number, access.Contract, access.VerifyAccessSelector, active, tt.contract, tt.verify, tt.active)
+		}
+		if active && (!bytes.Equal(access.GuardianshipIndexSelector, VeriteemGuardianshipIndexSelector) || !bytes.Equal(access.CreateContractSelector, VeriteemCreateContractSelector)) {
+			t.Errorf("block %d: selectors not overridden changed", tt.number)
+		}
+	}
+}
+
+// Tests that chains without a veriteem section consult the AccessRights
+// contract of the original genesis from block zero.
+func TestVeriteemAccessDefault(t *testing.T) {
+	access, active := new(ChainConfig).VeriteemAccess(new(big.Int))
+	if !active || access.Contract != DefaultVeriteemConfig.AccessContract {
+		t.Errorf("have %x (active %v), want %x", access.Contract, active, DefaultVeriteemConfig.AccessContract)
+	}
+}
+
+func TestVeriteemConfigJSON(t *testing.T) {
+	var config ChainConfig
+	err := json.Unmarshal([]byte(`{"veriteem": {
+		"accessContract": "0x0000000000000000000000000000000000000100",
+		"accessBlock": 3,
+		"upgrades": [{"block": 7, "accessContract": "0x0000000000000000000000000000000000000200", "selectors": {"createContract": "0x01020304"}}]
+	}}`), &config)
+	if err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	access, active := config.VeriteemAccess(big.NewInt(7))
+	if !active || access.Contract != common.HexToAddress("0x0000000000000000000000000000000000000200") {
+		t.Fatalf("upgrade not in force: have %x (active %v)", access.Contract, active)
+	}
+	if !bytes.Equal(access.CreateContractSelector, []byte{0x01, 0x02, 0x03, 0x04}) {
+		t.Errorf("create selector %x, want 01020304", access.CreateContractSelector)
+	}
+	if _, active := config.VeriteemAccess(big.NewInt(2)); active {
+		t.Errorf("access control active before its block")
+	}
+}
+
+// Tests that configs scheduling upgrades out of order, twice at the same block
+// or at no block at all are rejected when loaded.
+func TestVeriteemConfigUpgradeOrder(t *testing.T) {
+	tests := []struct {
+		upgrades string
+		valid    bool
+	}{
+		{`[]`, true},
+		{`[{"block": 7}, {"block": 9}]`, true},
+		{`[{"block": 9}, {"block": 7}]`, false},
+		{`[{"block": 7}, {"block": 7}]`, false},
+		{`[{"accessContract": "0x0000000000000000000000000000000000000200"}]`, false},
+	}
+	for _, tt := range tests {
+		var config ChainConfig
+		err := json.Unmarshal([]byte(`{"veriteem": {"upgrades": `+tt.upgrades+`}}`), &config)
+		if (err == nil) != tt.valid {
+			t.Errorf("upgrades %s: have error %v, want valid %v", tt.upgrades, err, tt.valid)
+		}
+	}
+}
+
+// Tests that the access control of a chain can't be changed for the blocks it
+// already processed, reporting the block to rewind to for the change.
+func TestVeriteemCheckCompatible(t *testing.T) {
+	var (
+		original = common.HexToAddress("0x0000000000000000000000000000000000000100")
+		upgraded = common.HexToAddress("0x0000000000000000000000000000000000000200")
+	)
+	stored := &VeriteemConfig{
+		AccessContract: original,
+		AccessBlock:    big.NewInt(5),
+		Upgrades:       []VeriteemUpgrade{{Block: big.NewInt(20), AccessContract: upgraded}},
+		CallKindsBlock: big.NewInt(10),
+		MigrationBlock: big.NewInt(10),
+		SelectorBlock:  big.NewInt(30),
+	}
+	tests := []struct {
+		name     string
+		head     uint64
+		change   func(*VeriteemConfig)
+		what     string
+		rewindTo uint64
+	}{
+		{"unchanged", 100, func(*VeriteemConfig) {}, "", 0},
+		{"access block ahead", 4, func(c *VeriteemConfig) { c.AccessBlock = big.NewInt(8) }, "", 0},
+		{"access block passed", 6, func(c *VeriteemConfig) { c.AccessBlock = big.NewInt(8) }, "Veriteem access contract", 4},
+		{"access contract", 6, func(c *VeriteemConfig) { c.AccessContract = upgraded }, "Veriteem access contract", 4},
+		{"native", 6, func(c *VeriteemConfig) { c.Native = true }, "Veriteem access contract", 4},
+		{"selectors", 6, func(c *VeriteemConfig) {
+			c.Selectors = &VeriteemSelectors{VerifyAccess: []byte{0x01, 0x02, 0x03, 0x04}}
+		}, "Veriteem access contract", 4},
+		{"default selectors", 6, func(c *VeriteemConfig) {
+			c.Selectors = &VeriteemSelectors{VerifyAccess: VeriteemVerifyAccessSelector}
+		}, "", 0},
+		{"upgrade ahead", 19, func(c *VeriteemConfig) { c.Upgrades = nil }, "", 0},
+		{"upgrade dropped", 20, func(c *VeriteemConfig) { c.Upgrades = nil }, "Veriteem access contract upgrade 0", 19},
+		{"upgrade added", 20, func(c *VeriteemConfig) {
+			c.Upgrades = append(c.Upgrades, VeriteemUpgrade{Block: big.NewInt(25), AccessContract: original})
+		}, "", 0},
+		{"upgrade moved", 20, func(c *VeriteemConfig) {
+			c.Upgrades = []VeriteemUpgrade{{Block: big.NewInt(18), AccessContract: upgraded}}
+		}, "Veriteem access contract upgrade 0", 17},
+		{"fork ahead", 9, func(c *VeriteemConfig) { c.CallKindsBlock = nil }, "", 0},
+		{"fork passed", 10, func(c *VeriteemConfig) { c.CallKindsBlock = nil }, "Veriteem call kinds fork block", 9},
+		{"fork scheduled", 10, func(c *VeriteemConfig) { c.LifecycleBlock = big.NewInt(7) }, "Veriteem lifecycle fork block", 6},
+		{"forward migrated", 10, func(c *VeriteemConfig) { c.ForwardMigrated = true }, "Veriteem forward migrated flag", 9},
+		{"fallback group ahead", 29, func(c *VeriteemConfig) { c.FallbackAccessGroup = big.NewInt(2) }, "", 0},
+		{"fallback group", 30, func(c *VeriteemConfig) { c.FallbackAccessGroup = big.NewInt(2) }, "Veriteem fallback access group", 29},
+		{"fallback group zero", 30, func(c *VeriteemConfig) { c.FallbackAccessGroup = new(big.Int) }, "", 0},
+	}
+	for _, tt := range tests {
+		updated := *stored
+		updated.Upgrades = append([]VeriteemUpgrade{}, stored.Upgrades...)
+		tt.change(&updated)
+
+		err := (&ChainConfig{Veriteem: stored}).CheckCompatible(&ChainConfig{Veriteem: &updated}, tt.head)
+		switch {
+		case tt.what == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.what != "" && err == nil:
+			t.Errorf("%s: change accepted", tt.name)
+		case tt.what != "" && (err.What != tt.what || err.RewindTo != tt.rewindTo):
+			t.Errorf("%s: have %q rewinding to %d, want %q rewinding to %d", tt.name, err.What, err.RewindTo, tt.what, tt.rewindTo)
+		}
+	}
+}
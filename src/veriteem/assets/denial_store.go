@@ -0,0 +1,157 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// DenialRetention is the number of most recent blocks whose access denials
+// the DenialStore keeps.
+const DenialRetention = 100000
+
+// denialPrefix + num (uint64 big endian) -> JSON encoded []denialRecord
+var denialPrefix = []byte("veriteem-denials-")
+
+// denialRecord holds the denials of one of the blocks processed at a height.
+type denialRecord struct {
+	Hash    common.Hash       `json:"hash"`
+	Denials []vm.AccessDenial `json:"denials"`
+}
+
+// DenialStore is the on-node audit log of the calls and contract creations the
+// access policy denied, kept in the chain database and indexed by block number.
+// Like the audit sink, it only holds the denials that changed an outcome, see
+// vm.AccessDenial.
+//
+// Denials are recorded once per processed block, under the hash of the block,
+// and only those of the canonical block are served. The denials of blocks
+// older than DenialRetention are pruned as new blocks are recorded.
+type DenialStore struct {
+	db   ethdb.Database
+	lock sync.Mutex
+}
+
+// NewDenialStore creates an audit log of access denials backed by db.
+func NewDenialStore(db ethdb.Database) *DenialStore {
+	return &DenialStore{db: db}
+}
+
+// denialKey = denialPrefix + num (uint64 big endian)
+func denialKey(number uint64) []byte {
+	key := make([]byte, len(denialPrefix)+8)
+	copy(key, denialPrefix)
+	binary.BigEndian.PutUint64(key[len(denialPrefix):], number)
+	return key
+}
+
+// RecordDenials implements vm.AccessDenialRecorder.
+func (s *DenialStore) RecordDenials(number uint64, hash common.Hash, denials []vm.AccessDenial) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if number > DenialRetention {
+		if err := s.db.Delete(denialKey(number - DenialRetention)); err != nil {
+			log.Crit("Failed to prune access denials", "err", err)
+		}
+	}
+	// Replace any denials recorded when the block was last processed,
+	// keeping those of the other blocks at the same height
+	var (
+		recorded = s.records(number)
+		records  []denialRecord
+	)
+	for _, record := range recorded {
+		if record.Hash != hash {
+			records = append(records, record)
+		}
+	}
+	if len(denials) > 0 {
+		now := time.Now()
+		for i := range denials {
+			if denials[i].Time.IsZero() {
+				denials[i].Time = now
+			}
+		}
+		records = append(records, denialRecord{Hash: hash, Denials: denials})
+	}
+	if len(records) == 0 {
+		if len(recorded) > 0 {
+			if err := s.db.Delete(denialKey(number)); err != nil {
+				log.Crit("Failed to delete access denials", "err", err)
+			}
+		}
+		return
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		log.Crit("Failed to encode access denials", "err", err)
+	}
+	if err := s.db.Put(denialKey(number), data); err != nil {
+		log.Crit("Failed to store access denials", "err", err)
+	}
+}
+
+// Denials returns the denials recorded for the canonical block number, in the
+// order they were taken.
+func (s *DenialStore) Denials(number uint64) []vm.AccessDenial {
+	hash := rawdb.ReadCanonicalHash(s.db, number)
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	for _, record := range s.records(number) {
+		if record.Hash == hash {
+			return record.Denials
+		}
+	}
+	return nil
+}
+
+// TransactionDenials returns the denials recorded for transaction txHash of the
+// canonical block number.
+func (s *DenialStore) TransactionDenials(number uint64, txHash common.Hash) []vm.AccessDenial {
+	var denials []vm.AccessDenial
+	for _, denial := range s.Denials(number) {
+		if denial.TxHash == txHash {
+			denials = append(denials, denial)
+		}
+	}
+	return denials
+}
+
+// records returns the denials recorded for the blocks at height number.
+func (s *DenialStore) records(number uint64) []denialRecord {
+	data, _ := s.db.Get(denialKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	var records []denialRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		log.Error("Invalid access denials JSON", "number", number, "err", err)
+		return nil
+	}
+	return records
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Tests that the denials of a block replace the ones recorded when it was last
+// processed, keeping those of the other blocks at its height, and that only
+// those of the canonical block are served.
+func TestDenialStore(t *testing.T) {
+	var (
+		db     = ethdb.NewMemDatabase()
+		store  = NewDenialStore(db)
+		first  = common.HexToHash("0x01")
+		second = common.HexToHash("0x02")
+		caller = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	)
+	denial := func(reason string) vm.AccessDenial {
+		return vm.AccessDenial{Block: 5, Caller: caller, Reason: reason}
+	}
+	store.RecordDenials(5, first, []vm.AccessDenial{denial("a"), denial("b")})
+	store.RecordDenials(5, second, []vm.AccessDenial{denial("c")})
+	store.RecordDenials(5, first, []vm.AccessDenial{denial("d")})
+
+	if denials := store.Denials(5); len(denials) != 0 {
+		t.Fatalf("denials served without canonical block: have %d", len(denials))
+	}
+	tests := []struct {
+		canonical common.Hash
+		want      []string
+	}{
+		{first, []string{"d"}},
+		{second, []string{"c"}},
+	}
+	for _, tt := range tests {
+		rawdb.WriteCanonicalHash(db, tt.canonical, 5)
+
+		denials := store.Denials(5)
+		if len(denials) != len(tt.want) {
+			t.Fatalf("canonical %x: have %d denials, want %d", tt.canonical, len(denials), len(tt.want))
+		}
+		for i, denial := range denials {
+			if denial.Reason != tt.want[i] || denial.Caller != caller {
+				t.Errorf("canonical %x: denial %d: have %+v, want reason %s", tt.canonical, i, denial, tt.want[i])
+			}
+			if denial.Time.IsZero() {
+				t.Errorf("canonical %x: denial %d: not timestamped", tt.canonical, i)
+			}
+		}
+	}
+	if denials := store.Denials(6); len(denials) != 0 {
+		t.Errorf("block without denials: have %d", len(denials))
+	}
+}
+
+// Tests that recording the denials of a block prunes those of the block
+// DenialRetention blocks before it.
+func TestDenialStorePrune(t *testing.T) {
+	var (
+		db    = ethdb.NewMemDatabase()
+		store = NewDenialStore(db)
+		hash  = common.HexToHash("0x01")
+	)
+	rawdb.WriteCanonicalHash(db, hash, 1)
+	store.RecordDenials(1, hash, []vm.AccessDenial{{Block: 1, Reason: "a"}})
+
+	store.RecordDenials(DenialRetention, common.HexToHash("0x02"), nil)
+	if denials := store.Denials(1); len(denials) != 1 {
+		t.Fatalf("denials pruned within retention: have %d", len(denials))
+	}
+	store.RecordDenials(DenialRetention+1, common.HexToHash("0x03"), nil)
+	if denials := store.Denials(1); len(denials) != 0 {
+		t.Errorf("denials kept beyond retention: have %d", len(denials))
+	}
+}
+
+// Tests that the denials of a transaction are picked from those of its block.
+func TestDenialStoreTransaction(t *testing.T) {
+	var (
+		db     = ethdb.NewMemDatabase()
+		store  = NewDenialStore(db)
+		hash   = common.HexToHash("0x01")
+		first  = common.HexToHash("0xf1")
+		second = common.HexToHash("0xf2")
+	)
+	rawdb.WriteCanonicalHash(db, hash, 5)
+	store.RecordDenials(5, hash, []vm.AccessDenial{
+		{Block: 5, TxHash: first, Reason: "a"},
+		{Block: 5, TxHash: second, Reason: "b"},
+		{Block: 5, TxHash: first, Reason: "c"},
+	})
+	tests := []struct {
+		tx   common.Hash
+		want []string
+	}{
+		{first, []string{"a", "c"}},
+		{second, []string{"b"}},
+		{common.HexToHash("0xf3"), nil},
+	}
+	for _, tt := range tests {
+		denials := store.TransactionDenials(5, tt.tx)
+		if len(denials) != len(tt.want) {
+			t.Errorf("tx %x: have %d denials, want %d", tt.tx, len(denials), len(tt.want))
+			continue
+		}
+		for i, denial := range denials {
+			if denial.TxHash != tt.tx || denial.Reason != tt.want[i] {
+				t.Errorf("tx %x: denial %d: have %+v, want reason %s", tt.tx, i, denial, tt.want[i])
+			}
+		}
+	}
+}
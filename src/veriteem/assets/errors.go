@@ -16,7 +16,12 @@
 
 package vm
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
 
 // List execution errors
 var (
@@ -27,5 +32,33 @@ var (
 	ErrInsufficientBalance      = errors.New("insufficient balance for transfer")
 	ErrContractAddressCollision = errors.New("contract address collision")
 	ErrNoCompatibleInterpreter  = errors.New("no compatible interpreter")
-        ErrContractDisabled         = errors.New("contract disabled")
+	ErrContractDisabled         = errors.New("contract disabled")
 )
+
+// List access control errors, reported for calls and creations denied by the
+// access policy. Receipts only record that a transaction failed: the error is
+// returned by eth_call and, from the denial errors fork on, as the revert
+// reason of the transaction, while nodes keeping the denial store add the
+// reasons to the "accessDenials" field of eth_getTransactionReceipt.
+var (
+	ErrReadDenied        = errors.New("contract read access denied")
+	ErrWriteDenied       = errors.New("contract write access denied")
+	ErrRateLimited       = errors.New("contributor write rate limit exceeded")
+	ErrUnownedContract   = errors.New("contract has no guardianship")
+	ErrAccessGroupDenied = errors.New("function restricted to other access groups")
+	ErrNoGuardianship    = errors.New("caller is not a contributor of the contract's guardianship")
+	ErrCreateDenied      = errors.New("contract creation denied")
+	ErrContractPaused    = errors.New("contract paused")
+	ErrContractRetired   = errors.New("contract retired")
+)
+
+// ContractMigratedError is reported for calls to a contract that was migrated,
+// unless the chain forwards them to the contract superseding it.
+type ContractMigratedError struct {
+	Contract   common.Address // Address of the migrated contract
+	NewAddress common.Address // Address of the contract superseding it
+}
+
+func (e *ContractMigratedError) Error() string {
+	return fmt.Sprintf("contract %s migrated to %s", e.Contract.Hex(), e.NewAddress.Hex())
+}
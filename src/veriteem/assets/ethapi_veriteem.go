@@ -0,0 +1,34 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// withAccessDenials adds the access denials the node recorded for transaction
+// hash of block number to the fields of its receipt, as "accessDenials". The
+// consensus receipt only has a status, so the reasons of the denials are only
+// known to nodes that processed the block within the denial retention.
+func withAccessDenials(fields map[string]interface{}, db ethdb.Database, number uint64, hash common.Hash) map[string]interface{} {
+	if denials := core.NewDenialStore(db).TransactionDenials(number, hash); len(denials) > 0 {
+		fields["accessDenials"] = denials
+	}
+	return fields
+}
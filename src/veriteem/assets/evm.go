@@ -20,13 +20,11 @@ import (
 	"math/big"
 	"sync/atomic"
 	"time"
-        "fmt"
-        "encoding/hex"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
-        "github.com/ethereum/go-ethereum/log"
 )
 
 // emptyCodeHash is used by create to ensure deployment is disallowed to already
@@ -128,6 +126,25 @@ type EVM struct {
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
 	callGasTemp uint64
+	// accessPolicy decides which contracts and functions callers may
+	// read from and write to, and who may deploy new contracts. It is
+	// nil while access control isn't active on the chain.
+	accessPolicy AccessPolicy
+	// access is the access contract in force. The contract itself is
+	// exempt from the permission checks.
+	access params.VeriteemAccess
+	// accessCache memoizes permission decisions across the transactions
+	// of the block being processed, if any.
+	accessCache *AccessCache
+	// accessDenials are the denials of the running transaction, kept
+	// for the AccessDenialBatch of the block being processed.
+	accessDenials []AccessDenial
+	// accessWrites counts the state changing operations the interpreter
+	// ran, to tell whether denying the writes of a frame reverted any.
+	accessWrites uint64
+	// dispatchTables are the selectors dispatched by the contract codes
+	// called so far, by code hash, to tell fallback calls apart.
+	dispatchTables map[common.Hash]map[[4]byte]struct{}
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
@@ -144,10 +161,25 @@ func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmCon
 
 	evm.interpreters[0] = NewEVMInterpreter(evm, vmConfig)
 	evm.interpreter = evm.interpreters[0]
+	if access, ok := chainConfig.VeriteemAccess(ctx.BlockNumber); ok {
+		evm.accessCache = vmConfig.AccessCache
+		evm.access = access
+		evm.SetAccessPolicy(NewAccessPolicy(evm, access))
+	}
 
 	return evm
 }
 
+// SetAccessPolicy replaces the policy consulted by the permission checks of
+// every call and contract creation. Its decisions are cached if the EVM was
+// configured with an AccessCache.
+func (evm *EVM) SetAccessPolicy(policy AccessPolicy) {
+	if evm.accessCache != nil {
+		policy = &cachedAccessPolicy{policy: policy, cache: evm.accessCache}
+	}
+	evm.accessPolicy = policy
+}
+
 // Cancel cancels any running EVM operation. This may be called concurrently and
 // it's safe to be called multiple times.
 func (evm *EVM) Cancel() {
@@ -168,52 +200,22 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		return nil, gas, nil
 	}
 
-        //////////////////////////////////////////////////////////////////////////////////
-        // Start Veriteem addition
-        //////////////////////////////////////////////////////////////////////////////////
-	log.Info(fmt.Sprintf("*** Call <<< %x %x %x %x",caller.Address(),addr,input, gas))
-	FuncAdd := make([]byte,4)
-	Loop := 0
-	for (Loop < 4) {
-		if (Loop >= len(input)) {
-			FuncAdd = append(FuncAdd,0)
-		} else {
-			FuncAdd = append(FuncAdd,input[Loop])
-		}
-		Loop++ 
-	}
-	//log.Info(fmt.Sprintf("Func: %x",FuncAdd))
-
-	temp2, _ := hex.DecodeString(fmt.Sprintf("45e4e5e4000000000000000000000000%x000000000000000000000000000000000000000000000000%x",addr,FuncAdd))
-	//log.Info(fmt.Sprintf("%x",temp2))
-
-        WriteAllowed := 1
-        ReadAllowed := 1
-	if (addr != common.HexToAddress("0000000000000000000000000000000000000100")) {
-           rsp, _, _ := evm.Call(caller, common.HexToAddress("0000000000000000000000000000000000000100"), temp2, gas, value)
-           log.Info(fmt.Sprintf("rsp: %x",rsp))
-           if (rsp[63] == 1) {
-              log.Info("*** Write Allowed ***")
-           } else {
-              log.Info("*** Write Blocked ***")
-              WriteAllowed = 0
-              //gas = 0
-           }
-           if (rsp[31] == 1) {
-              log.Info("*** Read Allowed ***")
-           } else {
-              log.Info("*** Read Blocked ***")
-              ReadAllowed = 0
-              //input,_ = hex.DecodeString("00")
-           }
-	}
-	
-	if ((WriteAllowed == 0) && (ReadAllowed == 0)) {
-           return nil, gas, ErrContractDisabled	  
-        }
-        //////////////////////////////////////////////////////////////////////////////////
-        // End Veriteem addition
-        //////////////////////////////////////////////////////////////////////////////////
+	//////////////////////////////////////////////////////////////////////////////////
+	// Start Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
+	if gas, err = evm.chargeAccessCheck(&addr, gas); err != nil {
+		return nil, gas, err
+	}
+	if addr, err = evm.redirectMigrated(caller, addr, input); err != nil {
+		return evm.denialOutput(err), gas, err
+	}
+	readAllowed, writeAllowed, denial, writeDenial := evm.verifyContractAccess(caller, addr, input, evm.vmConfig.ReadOnlyAccess && evm.depth == 0)
+	if !readAllowed && !writeAllowed {
+		return evm.denialOutput(denial), gas, denial
+	}
+	//////////////////////////////////////////////////////////////////////////////////
+	// End Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
 
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
@@ -227,6 +229,8 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	var (
 		to       = AccountRef(addr)
 		snapshot = evm.StateDB.Snapshot()
+		epoch    = evm.accessCache.Epoch()
+		writes   = evm.accessWrites
 	)
 	if !evm.StateDB.Exist(addr) {
 		precompiles := PrecompiledContractsHomestead
@@ -266,18 +270,27 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in homestead this also counts for code storage gas errors.
 
-        ////////////////////////////////////////////////////////////////////////////////////////////
-        //  Veriteem modifications
-        ////////////////////////////////////////////////////////////////////////////////////////////
-	if (err != nil) || (WriteAllowed == 0) {
+	//////////////////////////////////////////////////////////////////////////////////
+	// Veriteem modifications
+	//////////////////////////////////////////////////////////////////////////////////
+	evm.accessStorageWritten(addr)
+	evm.auditWriteDenial(writeDenial, err, writes, value)
+	if err != nil || !writeAllowed {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.accessCache.Reverted(epoch)
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
-        if (ReadAllowed == 0) {
-	   return nil, contract.Gas, err
-        }
+	if err == nil && writeAllowed {
+		evm.accessWritten(caller, addr)
+	}
+	if err == nil {
+		err = denial
+	}
+	if !readAllowed {
+		return nil, contract.Gas, err
+	}
 	return ret, contract.Gas, err
 }
 
@@ -293,6 +306,30 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 		return nil, gas, nil
 	}
 
+	//////////////////////////////////////////////////////////////////////////////////
+	// Start Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
+	var (
+		readAllowed, writeAllowed = true, true
+		denial                    error
+		writeDenial               *AccessDenial
+	)
+	if evm.access.CallKinds {
+		if gas, err = evm.chargeAccessCheck(&addr, gas); err != nil {
+			return nil, gas, err
+		}
+		if addr, err = evm.redirectMigrated(caller, addr, input); err != nil {
+			return evm.denialOutput(err), gas, err
+		}
+		readAllowed, writeAllowed, denial, writeDenial = evm.verifyContractAccess(caller, addr, input, false)
+		if !readAllowed && !writeAllowed {
+			return evm.denialOutput(denial), gas, denial
+		}
+	}
+	//////////////////////////////////////////////////////////////////////////////////
+	// End Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
+
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
@@ -304,6 +341,8 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 
 	var (
 		snapshot = evm.StateDB.Snapshot()
+		epoch    = evm.accessCache.Epoch()
+		writes   = evm.accessWrites
 		to       = AccountRef(caller.Address())
 	)
 	// initialise a new contract and set the code that is to be used by the
@@ -313,12 +352,28 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
 	ret, err = run(evm, contract, input)
-	if err != nil {
+
+	//////////////////////////////////////////////////////////////////////////////////
+	// Veriteem modifications
+	//////////////////////////////////////////////////////////////////////////////////
+	evm.accessStorageWritten(caller.Address())
+	evm.auditWriteDenial(writeDenial, err, writes, bigZero)
+	if err != nil || !writeAllowed {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.accessCache.Reverted(epoch)
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
+	if err == nil && writeAllowed && evm.access.CallKinds {
+		evm.accessWritten(caller, addr)
+	}
+	if err == nil {
+		err = denial
+	}
+	if !readAllowed {
+		return nil, contract.Gas, err
+	}
 	return ret, contract.Gas, err
 }
 
@@ -331,6 +386,32 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+
+	//////////////////////////////////////////////////////////////////////////////////
+	// Start Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
+	principal := evm.delegateCaller(caller)
+	var (
+		readAllowed, writeAllowed = true, true
+		denial                    error
+		writeDenial               *AccessDenial
+	)
+	if evm.access.CallKinds {
+		if gas, err = evm.chargeAccessCheck(&addr, gas); err != nil {
+			return nil, gas, err
+		}
+		if addr, err = evm.redirectMigrated(principal, addr, input); err != nil {
+			return evm.denialOutput(err), gas, err
+		}
+		readAllowed, writeAllowed, denial, writeDenial = evm.verifyContractAccess(principal, addr, input, false)
+		if !readAllowed && !writeAllowed {
+			return evm.denialOutput(denial), gas, denial
+		}
+	}
+	//////////////////////////////////////////////////////////////////////////////////
+	// End Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
+
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
@@ -338,6 +419,8 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 
 	var (
 		snapshot = evm.StateDB.Snapshot()
+		epoch    = evm.accessCache.Epoch()
+		writes   = evm.accessWrites
 		to       = AccountRef(caller.Address())
 	)
 
@@ -346,12 +429,28 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	contract.SetCallCode(&addr, evm.StateDB.GetCodeHash(addr), evm.StateDB.GetCode(addr))
 
 	ret, err = run(evm, contract, input)
-	if err != nil {
+
+	//////////////////////////////////////////////////////////////////////////////////
+	// Veriteem modifications
+	//////////////////////////////////////////////////////////////////////////////////
+	evm.accessStorageWritten(caller.Address())
+	evm.auditWriteDenial(writeDenial, err, writes, bigZero)
+	if err != nil || !writeAllowed {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.accessCache.Reverted(epoch)
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
+	if err == nil && writeAllowed && evm.access.CallKinds {
+		evm.accessWritten(principal, addr)
+	}
+	if err == nil {
+		err = denial
+	}
+	if !readAllowed {
+		return nil, contract.Gas, err
+	}
 	return ret, contract.Gas, err
 }
 
@@ -363,6 +462,31 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+
+	//////////////////////////////////////////////////////////////////////////////////
+	// Start Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
+	var (
+		readAllowed, writeAllowed = true, true
+		denial                    error
+	)
+	if evm.access.CallKinds {
+		if gas, err = evm.chargeAccessCheck(&addr, gas); err != nil {
+			return nil, gas, err
+		}
+		if addr, err = evm.redirectMigrated(caller, addr, input); err != nil {
+			return evm.denialOutput(err), gas, err
+		}
+		// Static calls can't write, so denying their writes changes nothing
+		readAllowed, writeAllowed, denial, _ = evm.verifyContractAccess(caller, addr, input, evm.access.StaticReads)
+		if !readAllowed && !writeAllowed {
+			return evm.denialOutput(denial), gas, denial
+		}
+	}
+	//////////////////////////////////////////////////////////////////////////////////
+	// End Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
+
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
@@ -378,6 +502,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	var (
 		to       = AccountRef(addr)
 		snapshot = evm.StateDB.Snapshot()
+		epoch    = evm.accessCache.Epoch()
 	)
 	// Initialise a new contract and set the code that is to be used by the
 	// EVM. The contract is a scoped environment for this execution context
@@ -389,39 +514,43 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	// above we revert to the snapshot and consume any gas remaining. Additionally
 	// when we're in Homestead this also counts for code storage gas errors.
 	ret, err = run(evm, contract, input)
-	if err != nil {
+
+	//////////////////////////////////////////////////////////////////////////////////
+	// Veriteem modifications
+	//////////////////////////////////////////////////////////////////////////////////
+	if err != nil || !writeAllowed {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.accessCache.Reverted(epoch)
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
 	}
+	if !readAllowed {
+		return nil, contract.Gas, err
+	}
 	return ret, contract.Gas, err
 }
 
 // create creates a new contract using code as deployment code.
 func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.Int, address common.Address) ([]byte, common.Address, uint64, error) {
 
-        ///////////////////////////////////////////////////////////////////////////////////////////////////
-        /// Start Veriteem Addition
-        ///////////////////////////////////////////////////////////////////////////////////////////////////
-        log.Info(fmt.Sprintf("*** CREATE *** %x",caller.Address()))
-        log.Info(fmt.Sprintf("Code: %x",code))
-        temp2, _ := hex.DecodeString(fmt.Sprintf("10bd7fc5000000000000000000000000%x",caller.Address()))
-        log.Info(fmt.Sprintf("%x",temp2))
-        rsp, _, _ := evm.Call(caller, common.HexToAddress("0000000000000000000000000000000000000100"), temp2, gas, value)
-        var blockedContract = 1
-        if (rsp[31] == 1) {
-           blockedContract = 0
-           log.Info("*** Create Allowed ***")
-        } else {
-           log.Info("*** Create Blocked ***")
-           code,_ = hex.DecodeString("00")
-        }
-        log.Info(fmt.Sprintf("Code: %x",code))
-
-        ///////////////////////////////////////////////////////////////////////////////////////////////////
-        /// End Veriteem addition
-        ///////////////////////////////////////////////////////////////////////////////////////////////////
+	//////////////////////////////////////////////////////////////////////////////////
+	// Start Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
+	gas, err := evm.chargeAccessCheck(nil, gas)
+	if err != nil {
+		return nil, common.Address{}, gas, err
+	}
+	createAllowed, err := evm.canCreate(caller, address)
+	if err != nil {
+		log.Warn("Contract creation check failed", "caller", caller.Address(), "err", err)
+	}
+	if !createAllowed && !evm.access.CreateDenial {
+		code = []byte{0x00}
+	}
+	//////////////////////////////////////////////////////////////////////////////////
+	// End Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
 
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
@@ -434,6 +563,16 @@ func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.I
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	evm.StateDB.SetNonce(caller.Address(), nonce+1)
 
+	//////////////////////////////////////////////////////////////////////////////////
+	// Start Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
+	if !createAllowed && evm.access.CreateDenial {
+		return evm.denyCreate(caller, address, code, gas, value)
+	}
+	//////////////////////////////////////////////////////////////////////////////////
+	// End Veriteem addition
+	//////////////////////////////////////////////////////////////////////////////////
+
 	// Ensure there's no existing contract already at the designated address
 	contractHash := evm.StateDB.GetCodeHash(address)
 	if evm.StateDB.GetNonce(address) != 0 || (contractHash != (common.Hash{}) && contractHash != emptyCodeHash) {
@@ -441,6 +580,7 @@ func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.I
 	}
 	// Create a new account on the state
 	snapshot := evm.StateDB.Snapshot()
+	epoch := evm.accessCache.Epoch()
 	evm.StateDB.CreateAccount(address)
 	if evm.ChainConfig().IsEIP158(evm.BlockNumber) {
 		evm.StateDB.SetNonce(address, 1)
@@ -484,6 +624,7 @@ func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.I
 	// when we're in homestead this also counts for code storage gas errors.
 	if maxCodeSizeExceeded || (err != nil && (evm.ChainConfig().IsHomestead(evm.BlockNumber) || err != ErrCodeStoreOutOfGas)) {
 		evm.StateDB.RevertToSnapshot(snapshot)
+		evm.accessCache.Reverted(epoch)
 		if err != errExecutionReverted {
 			contract.UseGas(contract.Gas)
 		}
@@ -495,19 +636,17 @@ func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.I
 	if evm.vmConfig.Debug && evm.depth == 0 {
 		evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
 	}
-        ////////////////////////////////////////////////////////////////////////////////////
-        //  Start Veriteem modification
-        ////////////////////////////////////////////////////////////////////////////////////
-        if blockedContract == 0 {
-           log.Info(fmt.Sprintf("*** Contract Address %x", address))
-           temp2, _ = hex.DecodeString(fmt.Sprintf("f5b7f3f6000000000000000000000000%x",address))
-           log.Info(fmt.Sprintf("%x",temp2))
-           rsp, _, _ = evm.Call(caller, common.HexToAddress("0000000000000000000000000000000000000100"), temp2, gas, value)
-        }
-
-        ////////////////////////////////////////////////////////////////////////////////////
-        //  End Veriteem modification
-        ////////////////////////////////////////////////////////////////////////////////////
+	//////////////////////////////////////////////////////////////////////////////////
+	// Start Veriteem modification
+	//////////////////////////////////////////////////////////////////////////////////
+	if createAllowed && evm.accessPolicy != nil {
+		if regErr := evm.accessPolicy.RegisterContract(caller.Address(), address); regErr != nil {
+			log.Warn("Contract registration failed", "contract", address, "err", regErr)
+		}
+	}
+	//////////////////////////////////////////////////////////////////////////////////
+	// End Veriteem modification
+	//////////////////////////////////////////////////////////////////////////////////
 	return ret, address, contract.Gas, err
 
 }
@@ -529,3 +668,272 @@ func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *
 
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
+
+//////////////////////////////////////////////////////////////////////////////////
+// Start Veriteem addition
+//////////////////////////////////////////////////////////////////////////////////
+
+// verifyContractAccess asks the access policy whether caller may read from and
+// write to the contract at addr through the function selected by the first four
+// bytes of input, see callSelector for shorter input and fallback calls. From
+// the call kinds fork on every call kind consults it, so a blocked contract
+// can't be reached through a proxy using CALLCODE, DELEGATECALL or STATICCALL.
+// A policy that fails to answer denies all access.
+//
+// The returned denial is the error the call fails with: always if all access
+// is denied, and if only writes are, for calls made by a transaction so that
+// its receipt records the failure. Before the denial errors fork a blocked
+// contract fails with ErrContractDisabled and discarded writes go unreported.
+// A denial of the writes alone is also returned as writeDenial, for
+// auditWriteDenial to audit once the frame ran.
+//
+// If readOnly is set, the write verdict can't influence the outcome of the call
+// and the check is answered by a cheaper read check whenever that allows the
+// read. This is the case for static calls, which can't write, and for the
+// outermost frame of eth_call, whose state changes are thrown away.
+func (evm *EVM) verifyContractAccess(caller ContractRef, addr common.Address, input []byte, readOnly bool) (readAllowed, writeAllowed bool, denial error, writeDenial *AccessDenial) {
+	if evm.accessPolicy == nil || addr == evm.access.Contract {
+		return true, true, nil, nil
+	}
+	selector, fallback := evm.callSelector(addr, input)
+	decision, err := evm.checkAccess(caller.Address(), addr, selector, fallback, readOnly)
+	if err != nil {
+		log.Warn("Contract access check failed", "caller", caller.Address(), "contract", addr, "err", err)
+		decision = AccessDecision{Reason: AccessDenied}
+	}
+	if state, ok := evm.contractState(addr); ok {
+		if state == ContractStateDeprecated {
+			log.Warn("Deprecated contract called", "caller", caller.Address(), "contract", addr, "number", evm.BlockNumber)
+		}
+		decision = state.restrict(decision)
+	}
+	if tracer := evm.accessTracer(); tracer != nil {
+		tracer.CaptureAccessCheck(evm, caller.Address(), addr, selector, decision.Read, decision.Write, decision.Reason)
+	}
+	writeDenial = auditor().check(evm, caller.Address(), addr, selector, decision)
+
+	switch {
+	case !evm.access.DenialErrors:
+		if !decision.Read && !decision.Write {
+			denial = ErrContractDisabled
+		}
+	case !decision.Read && !decision.Write:
+		denial = decision.Reason.Err()
+	case !decision.Write && evm.depth == 0:
+		denial = decision.Reason.Err()
+	}
+	return decision.Read, decision.Write, denial, writeDenial
+}
+
+// auditWriteDenial audits the denial of the writes of a frame once it ran. The
+// denial only changed the outcome if the frame succeeded and changed the state,
+// by receiving value or by running state changing operations itself or in the
+// frames it called, which writes is the count of from before the frame ran.
+func (evm *EVM) auditWriteDenial(writeDenial *AccessDenial, err error, writes uint64, value *big.Int) {
+	if writeDenial == nil || err != nil {
+		return
+	}
+	if value.Sign() != 0 || evm.accessWrites != writes {
+		auditor().deny(evm, *writeDenial)
+	}
+}
+
+// checkAccess asks the access policy for the decision on a call, trying a read
+// check first if readOnly is set. A granted read then allows the whole call,
+// as its writes can't matter; a denied one falls back to the full check so
+// the call fails exactly as it would have otherwise. Fallback calls are held
+// against the fallback access group of the chain on top.
+func (evm *EVM) checkAccess(caller, addr common.Address, selector [4]byte, fallback, readOnly bool) (AccessDecision, error) {
+	decision, err := evm.policyDecision(caller, addr, selector, readOnly)
+	if err != nil || !fallback || evm.access.FallbackAccessGroup == nil || (!decision.Read && !decision.Write) {
+		return decision, err
+	}
+	group, err := evm.accessPolicy.ContributorAccessGroup(caller)
+	if err != nil {
+		return AccessDecision{}, err
+	}
+	if new(big.Int).And(group, evm.access.FallbackAccessGroup).Sign() == 0 {
+		return AccessDecision{Reason: AccessGroupMismatch}, nil
+	}
+	return decision, nil
+}
+
+// policyDecision returns the decision of the access policy on a call, using
+// the read fast path if readOnly is set.
+func (evm *EVM) policyDecision(caller, addr common.Address, selector [4]byte, readOnly bool) (AccessDecision, error) {
+	if readOnly {
+		if decision, err := evm.accessPolicy.CheckRead(caller, addr, selector); err == nil && decision.Read {
+			decision.Write = true
+			return decision, nil
+		}
+	}
+	return evm.accessPolicy.CheckAccess(caller, addr, selector)
+}
+
+// delegateCaller returns the account the permission check of a DELEGATECALL
+// made by caller is taken for: the account that called caller. The delegated
+// code runs with its msg.sender and, behind a proxy, on its calldata, so the
+// access groups of the functions of the implementation apply to it rather than
+// to the proxy.
+func (evm *EVM) delegateCaller(caller ContractRef) ContractRef {
+	if contract, ok := caller.(*Contract); ok {
+		return AccountRef(contract.CallerAddress)
+	}
+	return caller
+}
+
+// chargeAccessCheck deducts the cost of the permission check on a call to the
+// contract at addr, or on a contract creation if addr is nil, from gas. The
+// check costs a fixed amount from the access gas fork on, whether the policy
+// evaluates the access contract natively or runs its code, so both agree on the
+// gas used. The cost covers recording the write for the rate limit too.
+//
+// The fixed cost deliberately replaces metering the check: the gas the queries
+// of the access contract use out of their own accessCheckGas allowance isn't
+// deducted, and decisions served by the AccessCache cost as much as fresh ones,
+// so the gas used doesn't depend on the policy or on the transactions before.
+// Calls of precompiled contracts and of the access contract are free, see
+// TransactionAccessGas.
+func (evm *EVM) chargeAccessCheck(addr *common.Address, gas uint64) (uint64, error) {
+	if evm.accessPolicy == nil || !evm.access.ChargeGas {
+		return gas, nil
+	}
+	cost := params.VeriteemCreateCheckGas
+	if addr != nil {
+		if *addr == evm.access.Contract || isPrecompiled(evm.ChainConfig(), evm.BlockNumber, *addr) {
+			return gas, nil
+		}
+		cost = params.VeriteemAccessCheckGas
+	}
+	if gas < cost {
+		return 0, ErrOutOfGas
+	}
+	return gas - cost, nil
+}
+
+// denialOutput returns the output of a call failing with denial: the reason
+// encoded as a Solidity revert reason for calls made by a transaction or
+// eth_call, so that clients can tell why access was denied.
+func (evm *EVM) denialOutput(denial error) []byte {
+	if denial == nil || !evm.access.DenialErrors || evm.depth > 0 {
+		return nil
+	}
+	return packRevertReason(denial)
+}
+
+// canCreate asks the access policy whether caller may deploy a contract at
+// address.
+func (evm *EVM) canCreate(caller ContractRef, address common.Address) (bool, error) {
+	if evm.accessPolicy == nil {
+		return true, nil
+	}
+	allowed, err := evm.accessPolicy.CanCreate(caller.Address())
+	auditor().create(evm, caller.Address(), address, allowed && err == nil)
+
+	if tracer := evm.accessTracer(); tracer != nil {
+		tracer.CaptureCreateCheck(evm, caller.Address(), allowed && err == nil)
+	}
+	return allowed, err
+}
+
+// denyCreate fails a contract creation refused by the access policy with
+// ErrCreateDenied. The creator's nonce is still bumped, so a denied creation
+// transaction can't be replayed, but no account is created and none of the gas
+// handed to the creation is spent: the caller only pays the intrinsic gas of
+// its transaction, or the cost of the CREATE or CREATE2 opcode.
+func (evm *EVM) denyCreate(caller ContractRef, address common.Address, code []byte, gas uint64, value *big.Int) ([]byte, common.Address, uint64, error) {
+	// Report the denied creation to the tracer like any other failed creation
+	if evm.vmConfig.Debug && evm.depth == 0 {
+		evm.vmConfig.Tracer.CaptureStart(caller.Address(), address, true, code, gas, value)
+		evm.vmConfig.Tracer.CaptureEnd(nil, 0, 0, ErrCreateDenied)
+	}
+	return evm.denialOutput(ErrCreateDenied), common.Address{}, gas, ErrCreateDenied
+}
+
+// contractState returns the lifecycle state of the contract at addr if the
+// access it allows is enforced, which is the case from the lifecycle fork on.
+func (evm *EVM) contractState(addr common.Address) (ContractState, bool) {
+	if evm.accessPolicy == nil || !evm.access.Lifecycle || addr == evm.access.Contract {
+		return 0, false
+	}
+	status, err := evm.accessPolicy.ContractStatus(addr)
+	if err != nil {
+		log.Warn("Contract status lookup failed", "contract", addr, "err", err)
+		return 0, false
+	}
+	return status.State, true
+}
+
+// migration reports whether the access contract marks the contract at addr as
+// migrated, and to which address, from the migration fork on. Migrations to
+// the zero address or to the access contract itself are ignored.
+func (evm *EVM) migration(addr common.Address) (common.Address, bool) {
+	if evm.accessPolicy == nil || !evm.access.Migrations || addr == evm.access.Contract {
+		return common.Address{}, false
+	}
+	status, err := evm.accessPolicy.ContractStatus(addr)
+	if err != nil {
+		log.Warn("Contract status lookup failed", "contract", addr, "err", err)
+		return common.Address{}, false
+	}
+	if status.State != ContractStateMigrated || status.NewAddress == (common.Address{}) || status.NewAddress == evm.access.Contract {
+		return common.Address{}, false
+	}
+	return status.NewAddress, true
+}
+
+// redirectMigrated returns the address a call by caller to the contract at
+// addr runs against. Calls to a migrated contract are forwarded to the contract
+// superseding it if the chain is configured to, and otherwise fail with a
+// ContractMigratedError pointing at it. A forwarded call is checked against
+// the access policy, and runs the code, of the new contract. Migrations aren't
+// followed transitively: NewAddress should name the latest version.
+func (evm *EVM) redirectMigrated(caller ContractRef, addr common.Address, input []byte) (common.Address, error) {
+	newAddress, migrated := evm.migration(addr)
+	if !migrated {
+		return addr, nil
+	}
+	auditor().migrated(evm, caller.Address(), addr, newAddress, accessSelector(input), evm.access.Forward)
+	if evm.access.Forward {
+		return newAddress, nil
+	}
+	return addr, &ContractMigratedError{Contract: addr, NewAddress: newAddress}
+}
+
+// accessWritten is called after a call by caller to the contract at addr, which
+// passed the permission check with writes allowed, succeeded. From the rate
+// limit fork on, the block is recorded as the last one the contributor wrote in.
+//
+// Only contributors have a rate limit, so nothing is recorded for other
+// callers, such as contracts calling each other. Nothing is recorded within a
+// static call either, where UpdateContributorBlock couldn't store the block:
+// native and contract policies then leave the same state.
+func (evm *EVM) accessWritten(caller ContractRef, addr common.Address) {
+	if evm.accessPolicy == nil || !evm.access.RecordWrites || addr == evm.access.Contract || evm.interpreter.IsReadOnly() {
+		return
+	}
+	contributor, err := evm.accessPolicy.IsContributor(caller.Address())
+	if err != nil {
+		log.Warn("Contributor lookup failed", "caller", caller.Address(), "err", err)
+		return
+	}
+	if !contributor {
+		return
+	}
+	if err := evm.accessPolicy.RecordWrite(caller.Address()); err != nil {
+		log.Warn("Contributor write recording failed", "caller", caller.Address(), "err", err)
+	}
+}
+
+// accessStorageWritten is called after running code in the storage context of
+// addr. If that is the access contract, its tables may have changed and any
+// cached permission decisions are dropped.
+func (evm *EVM) accessStorageWritten(addr common.Address) {
+	if evm.accessPolicy != nil && addr == evm.access.Contract {
+		evm.accessCache.Invalidate()
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////
+// End Veriteem addition
+//////////////////////////////////////////////////////////////////////////////////
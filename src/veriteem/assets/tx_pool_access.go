@@ -0,0 +1,117 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// accessDeniedTxCounter counts the transactions turned away because the
+	// access policy would drop their writes.
+	accessDeniedTxCounter = metrics.NewRegisteredCounter("txpool/accessdenied", nil)
+
+	// rateLimitedTxCounter counts the transactions accepted from senders held
+	// back by their write rate limit.
+	rateLimitedTxCounter = metrics.NewRegisteredCounter("txpool/ratelimited", nil)
+)
+
+// validateAccess checks a transaction against the access policy in force for
+// the next block, using the pending state of the pool. Transactions whose writes
+// are bound to be dropped, or whose contract creation is bound to be denied,
+// would only waste block space and are rejected with the reason of the denial.
+// From the access gas fork on, the gas of a transaction has to cover the
+// permission check on top of its intrinsic gas intrGas.
+//
+// Transactions of rate limited senders are accepted, as they may pass in a later
+// block, but the sender is held back from the pending transactions handed out
+// for block building until its rate limit allows another write.
+func (pool *TxPool) validateAccess(tx *types.Transaction, from common.Address, intrGas uint64) error {
+	number := pool.nextBlockNumber()
+	if tx.Gas() < intrGas+vm.TransactionAccessGas(pool.chainconfig, number, tx.To()) {
+		return ErrIntrinsicGas
+	}
+	switch err := vm.CheckTransactionAccess(pool.accessStateDB(), pool.chainconfig, number, from, tx.To(), tx.Data()); err {
+	case nil:
+		return nil
+	case vm.ErrRateLimited:
+		log.Trace("Deferring rate limited transaction", "hash", tx.Hash(), "from", from)
+		rateLimitedTxCounter.Inc(1)
+		if pool.rateLimited == nil {
+			pool.rateLimited = make(map[common.Address]struct{})
+		}
+		pool.rateLimited[from] = struct{}{}
+		return nil
+	default:
+		log.Trace("Rejecting access denied transaction", "hash", tx.Hash(), "from", from, "err", err)
+		accessDeniedTxCounter.Inc(1)
+		return err
+	}
+}
+
+// holdRateLimited cuts the transactions of rate limited senders out of pending
+// from their first one that would still be rate limited in the next block, and
+// forgets the senders whose rate limit has passed.
+func (pool *TxPool) holdRateLimited(pending map[common.Address]types.Transactions) map[common.Address]types.Transactions {
+	if len(pool.rateLimited) == 0 {
+		return pending
+	}
+	var (
+		number  = pool.nextBlockNumber()
+		statedb = pool.accessStateDB()
+	)
+	for addr := range pool.rateLimited {
+		txs, limited := pending[addr], false
+		for i, tx := range txs {
+			if vm.CheckTransactionAccess(statedb, pool.chainconfig, number, addr, tx.To(), tx.Data()) == vm.ErrRateLimited {
+				if i == 0 {
+					delete(pending, addr)
+				} else {
+					pending[addr] = txs[:i]
+				}
+				limited = true
+				break
+			}
+		}
+		if !limited && pool.queue[addr] == nil {
+			delete(pool.rateLimited, addr)
+		}
+	}
+	return pending
+}
+
+// nextBlockNumber returns the number of the block the pool validates for.
+func (pool *TxPool) nextBlockNumber() *big.Int {
+	return new(big.Int).Add(pool.chain.CurrentBlock().Number(), common.Big1)
+}
+
+// accessStateDB returns the state the access checks of the pool run on: a copy
+// of the pending state, taken once after every reset of the pool, since the
+// checks touch the state they run on.
+func (pool *TxPool) accessStateDB() *state.StateDB {
+	if pool.accessState == nil {
+		pool.accessState = pool.pendingState.StateCopy()
+	}
+	return pool.accessState
+}
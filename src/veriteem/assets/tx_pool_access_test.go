@@ -0,0 +1,253 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// accessRightsABI holds the AccessRights methods the pool tests administer the
+// access contract with.
+const accessRightsABI = `[
+	{"type": "function", "name": "InitGuardianship", "inputs": []},
+	{"type": "function", "name": "CreateContributor", "inputs": [
+		{"name": "ContributorAddress", "type": "address"},
+		{"name": "Name", "type": "string"},
+		{"name": "Limit", "type": "uint256"},
+		{"name": "AccessGroup", "type": "uint256"}
+	]}
+]`
+
+// testCounterCode is the creation code of a contract counting the calls made to
+// it in storage slot zero.
+var testCounterCode = common.FromHex("600a80600b6000396000f360005460010160005500")
+
+// accessTestPool is a transaction pool over a state holding the AccessRights
+// contract of the genesis at the default access contract address.
+type accessTestPool struct {
+	pool     *TxPool
+	guardian *ecdsa.PrivateKey // Guardian of the first guardianship
+	member   *ecdsa.PrivateKey // Contributor of the first guardianship
+	outsider *ecdsa.PrivateKey // Neither guardian nor contributor
+	counter  common.Address    // Contract of the first guardianship
+}
+
+// newAccessTestPool creates a pool whose access policy is read from config,
+// funding the test accounts and setting up their roles through the access
+// contract. The member may write once every limit blocks.
+func newAccessTestPool(t *testing.T, config *params.ChainConfig, limit uint64) *accessTestPool {
+	data, err := ioutil.ReadFile(filepath.Join("vm", "testdata", "genesis.json"))
+	if err != nil {
+		t.Fatalf("failed to read genesis: %v", err)
+	}
+	var genesis struct {
+		Alloc map[string]struct {
+			Code hexutil.Bytes `json:"code"`
+		} `json:"alloc"`
+	}
+	if err := json.Unmarshal(data, &genesis); err != nil {
+		t.Fatalf("failed to parse genesis: %v", err)
+	}
+	access := params.DefaultVeriteemConfig.AccessContract
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	for addr, account := range genesis.Alloc {
+		if common.HexToAddress(addr) == access {
+			statedb.SetCode(access, account.Code)
+		}
+	}
+	p := new(accessTestPool)
+	p.guardian, _ = crypto.GenerateKey()
+	p.member, _ = crypto.GenerateKey()
+	p.outsider, _ = crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{p.guardian, p.member, p.outsider} {
+		statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(params.Ether))
+	}
+	ctx := vm.Context{
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		GasPrice:    new(big.Int),
+		BlockNumber: new(big.Int),
+		Time:        new(big.Int),
+		Difficulty:  new(big.Int),
+	}
+	evm := vm.NewEVM(ctx, statedb, config, vm.Config{})
+
+	parsed, _ := abi.JSON(strings.NewReader(accessRightsABI))
+	guardian := vm.AccountRef(crypto.PubkeyToAddress(p.guardian.PublicKey))
+	for _, method := range []struct {
+		name string
+		args []interface{}
+	}{
+		{"InitGuardianship", nil},
+		{"CreateContributor", []interface{}{crypto.PubkeyToAddress(p.member.PublicKey), "member", new(big.Int).SetUint64(limit), new(big.Int)}},
+	} {
+		input, err := parsed.Pack(method.name, method.args...)
+		if err != nil {
+			t.Fatalf("failed to pack %s: %v", method.name, err)
+		}
+		if _, _, err := evm.Call(guardian, access, input, 1000000, new(big.Int)); err != nil {
+			t.Fatalf("%s failed: %v", method.name, err)
+		}
+	}
+	if _, p.counter, _, err = evm.Create(guardian, testCounterCode, 1000000, new(big.Int)); err != nil {
+		t.Fatalf("failed to deploy counter: %v", err)
+	}
+	p.pool = NewTxPool(testTxPoolConfig, config, &testBlockChain{statedb, 1000000, new(event.Feed)})
+	return p
+}
+
+// accessTransaction returns a transaction of key calling to, or creating a
+// contract with data if to is nil.
+func accessTransaction(nonce uint64, to *common.Address, data []byte, key *ecdsa.PrivateKey) *types.Transaction {
+	var tx *types.Transaction
+	if to == nil {
+		tx = types.NewContractCreation(nonce, new(big.Int), 200000, big.NewInt(1), data)
+	} else {
+		tx = types.NewTransaction(nonce, *to, new(big.Int), 200000, big.NewInt(1), data)
+	}
+	tx, _ = types.SignTx(tx, types.HomesteadSigner{}, key)
+	return tx
+}
+
+// Tests that the pool rejects the transactions the access policy would block,
+// under the default contract policy and the native one.
+func TestTxPoolAccess(t *testing.T) {
+	native := *params.TestChainConfig
+	native.Veriteem = &params.VeriteemConfig{AccessContract: params.DefaultVeriteemConfig.AccessContract, Native: true}
+
+	for _, config := range []*params.ChainConfig{params.TestChainConfig, &native} {
+		p := newAccessTestPool(t, config, 0)
+		access := params.DefaultVeriteemConfig.AccessContract
+
+		tests := []struct {
+			key  *ecdsa.PrivateKey
+			to   *common.Address
+			data []byte
+			fail bool
+			err  error // Checked if set
+		}{
+			{key: p.member, to: &p.counter},
+			{key: p.outsider, to: &p.counter, fail: true},
+			{key: p.outsider, to: &access},
+			{key: p.guardian, data: testCounterCode},
+			{key: p.outsider, data: testCounterCode, fail: true, err: vm.ErrCreateDenied},
+		}
+		for i, tt := range tests {
+			nonce := p.pool.currentState.GetNonce(crypto.PubkeyToAddress(tt.key.PublicKey))
+			err := p.pool.AddRemote(accessTransaction(nonce, tt.to, tt.data, tt.key))
+			if tt.fail != (err != nil) || (tt.err != nil && err != tt.err) {
+				t.Errorf("native %v test %d: have %v, want failure %v (%v)", config.Veriteem != nil, i, err, tt.fail, tt.err)
+			}
+		}
+		p.pool.Stop()
+	}
+}
+
+// Tests that from the access gas fork on, the pool requires the gas of a
+// transaction to cover the permission check on top of its intrinsic gas,
+// except for calls of the access contract, which aren't checked.
+func TestTxPoolAccessGas(t *testing.T) {
+	for _, native := range []bool{false, true} {
+		config := *params.TestChainConfig
+		config.Veriteem = &params.VeriteemConfig{
+			AccessContract: params.DefaultVeriteemConfig.AccessContract,
+			Native:         native,
+			AccessGasBlock: new(big.Int),
+		}
+		p := newAccessTestPool(t, &config, 0)
+		access := params.DefaultVeriteemConfig.AccessContract
+
+		intrinsic, _ := IntrinsicGas(nil, false, true)
+		tests := []struct {
+			to   common.Address
+			gas  uint64
+			fail bool
+		}{
+			{p.counter, intrinsic, true},
+			{p.counter, intrinsic + params.VeriteemAccessCheckGas - 1, true},
+			{p.counter, intrinsic + params.VeriteemAccessCheckGas, false},
+			{access, intrinsic, false},
+		}
+		member := crypto.PubkeyToAddress(p.member.PublicKey)
+		for i, tt := range tests {
+			tx := types.NewTransaction(p.pool.State().GetNonce(member), tt.to, new(big.Int), tt.gas, big.NewInt(1), nil)
+			tx, _ = types.SignTx(tx, types.HomesteadSigner{}, p.member)
+
+			err := p.pool.AddRemote(tx)
+			if tt.fail && err != ErrIntrinsicGas || !tt.fail && err != nil {
+				t.Errorf("native %v test %d: have %v, want failure %v", native, i, err, tt.fail)
+			}
+		}
+		p.pool.Stop()
+	}
+}
+
+// Tests that the transactions of rate limited senders are accepted, as they may
+// pass in a later block, but aren't handed out for block building, under the
+// default contract policy and the native one.
+func TestTxPoolRateLimited(t *testing.T) {
+	for _, native := range []bool{false, true} {
+		config := *params.TestChainConfig
+		config.Veriteem = &params.VeriteemConfig{
+			AccessContract: params.DefaultVeriteemConfig.AccessContract,
+			Native:         native,
+			RateLimitBlock: new(big.Int),
+		}
+		// The member was created in block zero, so it can't write before block 5
+		p := newAccessTestPool(t, &config, 5)
+
+		for _, key := range []*ecdsa.PrivateKey{p.member, p.guardian} {
+			addr := crypto.PubkeyToAddress(key.PublicKey)
+			if err := p.pool.AddRemote(accessTransaction(p.pool.State().GetNonce(addr), &p.counter, nil, key)); err != nil {
+				t.Fatalf("native %v: transaction of %x rejected: %v", native, addr, err)
+			}
+		}
+		pending, err := p.pool.Pending()
+		if err != nil {
+			t.Fatalf("native %v: failed to retrieve pending transactions: %v", native, err)
+		}
+		if txs := pending[crypto.PubkeyToAddress(p.member.PublicKey)]; len(txs) != 0 {
+			t.Errorf("native %v: %d transactions of rate limited sender handed out", native, len(txs))
+		}
+		if txs := pending[crypto.PubkeyToAddress(p.guardian.PublicKey)]; len(txs) != 1 {
+			t.Errorf("native %v: %d transactions of guardian handed out, want 1", native, len(txs))
+		}
+		if _, queued := p.pool.Stats(); queued != 0 {
+			t.Errorf("native %v: %d transactions queued, want none", native, queued)
+		}
+		p.pool.Stop()
+	}
+}
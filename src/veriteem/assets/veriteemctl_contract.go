@@ -0,0 +1,396 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/veriteem/accessrights"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	newAddressFlag = cli.StringFlag{
+		Name:  "newaddress",
+		Usage: "Address of the contract superseding a migrated contract",
+	}
+	indexFlag = cli.Uint64Flag{
+		Name:  "index",
+		Usage: "Slot of the contract's FunctionList to write (default = the function's slot, or the first free one)",
+	}
+)
+
+var commandContract = cli.Command{
+	Name:  "contract",
+	Usage: "Manage the contracts of a guardianship",
+	Subcommands: []cli.Command{
+		{
+			Name:      "register",
+			Usage:     "Place a contract under the sender's guardianship",
+			ArgsUsage: "<address>",
+			Flags:     []cli.Flag{nameFlag},
+			Action:    contractRegister,
+		},
+		{
+			Name:      "info",
+			Usage:     "Show the entry of a contract",
+			ArgsUsage: "<address>",
+			Action:    contractInfo,
+		},
+		{
+			Name:      "set-write",
+			Usage:     "Allow or deny writes to a contract",
+			ArgsUsage: "<address> <true|false>",
+			Action: func(ctx *cli.Context) error {
+				return contractUpdate(ctx, func(info *accessrights.ContractInfo, arg string) (err error) {
+					info.WriteValid, err = parseBool(arg)
+					return err
+				})
+			},
+		},
+		{
+			Name:      "set-read",
+			Usage:     "Allow or deny reads of a contract by contributors of other guardianships",
+			ArgsUsage: "<address> <true|false>",
+			Action: func(ctx *cli.Context) error {
+				return contractUpdate(ctx, func(info *accessrights.ContractInfo, arg string) (err error) {
+					info.GlobalReadValid, err = parseBool(arg)
+					return err
+				})
+			},
+		},
+		{
+			Name:      "set-state",
+			Usage:     "Set the lifecycle state of a contract",
+			ArgsUsage: "<address> <disabled|active|migrated|paused|deprecated|retired>",
+			Flags:     []cli.Flag{newAddressFlag},
+			Action: func(ctx *cli.Context) error {
+				return contractUpdate(ctx, func(info *accessrights.ContractInfo, arg string) (err error) {
+					if info.State, err = parseState(arg); err != nil {
+						return err
+					}
+					if ctx.IsSet(newAddressFlag.Name) {
+						info.NewAddress, err = parseAddress(ctx.String(newAddressFlag.Name))
+					}
+					return err
+				})
+			},
+		},
+		{
+			Name:      "function-group",
+			Usage:     "Restrict a function of a contract to a set of access groups",
+			ArgsUsage: "<address> <signature|selector> <group>",
+			Flags:     []cli.Flag{indexFlag},
+			Description: `
+The function is given by its signature, such as "set(uint256)", or by its 4
+byte selector in hex. The group is the bit mask of the access groups whose
+contributors may call the function. The contract stops looking up functions at
+the first empty slot of the FunctionList, so functions are kept contiguous.`,
+			Action: contractFunctionGroup,
+		},
+	},
+}
+
+// contractRegister places a contract under the guardianship of the sender and
+// names it. Newly registered contracts are active and open to reads and
+// writes.
+func contractRegister(ctx *cli.Context) error {
+	address, err := addressArg(ctx, 0, "contract")
+	if err != nil {
+		return err
+	}
+	client, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	opts, err := newTransactor(ctx)
+	if err != nil {
+		return err
+	}
+	guardianship, err := requireGuardian(client, opts)
+	if err != nil {
+		return err
+	}
+	info, err := client.ContractInfo(latest(), address)
+	if err != nil {
+		return err
+	}
+	if info.Guardianship != 0 && info.Guardianship != guardianship.Index {
+		return fmt.Errorf("%s is owned by guardianship %d", address.Hex(), info.Guardianship)
+	}
+	tx, err := client.CreateContract(opts, address)
+	if err != nil {
+		return err
+	}
+	result, err := wait(client, tx)
+	if err != nil {
+		return err
+	}
+	if info, err = client.ContractInfo(latest(), address); err != nil {
+		return err
+	}
+	if info.Guardianship != guardianship.Index {
+		return fmt.Errorf("transaction %s was mined but %s wasn't registered", result.Tx.Hex(), address.Hex())
+	}
+	if ctx.IsSet(nameFlag.Name) {
+		info.Name = ctx.String(nameFlag.Name)
+		tx, err := client.SetContractInfo(opts, info)
+		if err != nil {
+			return err
+		}
+		if result, err = wait(client, tx); err != nil {
+			return err
+		}
+	}
+	return output(ctx, struct {
+		*transactionResult
+		Contract *accessrights.ContractInfo `json:"contract"`
+	}{result, info}, txTable(result), contractTable(info))
+}
+
+// contractInfo prints the entry of a contract, with the guardianships sharing
+// it and the access groups of its functions.
+func contractInfo(ctx *cli.Context) error {
+	address, err := addressArg(ctx, 0, "contract")
+	if err != nil {
+		return err
+	}
+	client, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	opts := callOpts(ctx)
+	info, err := client.ContractInfo(opts, address)
+	if err != nil {
+		return err
+	}
+	list, err := client.SubGuardianships(opts, address)
+	if err != nil {
+		return err
+	}
+	functions, err := client.FunctionGroups(opts, address)
+	if err != nil {
+		return err
+	}
+	subGuardianships := []uint64{}
+	for _, index := range list {
+		if index != 0 {
+			subGuardianships = append(subGuardianships, index)
+		}
+	}
+	if functions == nil {
+		functions = []accessrights.FunctionGroup{}
+	}
+	t := contractTable(info)
+	t.addRow("Sub guardianships", strings.Trim(fmt.Sprint(subGuardianships), "[]"))
+
+	f := &table{header: []string{"INDEX", "SELECTOR", "ACCESS GROUP"}}
+	for _, function := range functions {
+		f.addRow(function.Index, function.Selector, fmt.Sprintf("%#x", function.AccessGroup))
+	}
+	return output(ctx, struct {
+		*accessrights.ContractInfo
+		StateName        string                       `json:"stateName"`
+		SubGuardianships []uint64                     `json:"subGuardianships"`
+		Functions        []accessrights.FunctionGroup `json:"functions"`
+	}{info, info.State.String(), subGuardianships, functions}, t, f)
+}
+
+// contractUpdate rewrites the entry of the contract given as first argument,
+// after modify changed it according to the second argument.
+func contractUpdate(ctx *cli.Context, modify func(info *accessrights.ContractInfo, arg string) error) error {
+	address, err := addressArg(ctx, 0, "contract")
+	if err != nil {
+		return err
+	}
+	if ctx.NArg() < 2 {
+		return fmt.Errorf("missing value argument")
+	}
+	client, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	opts, err := newTransactor(ctx)
+	if err != nil {
+		return err
+	}
+	guardianship, err := requireGuardian(client, opts)
+	if err != nil {
+		return err
+	}
+	info, err := client.ContractInfo(latest(), address)
+	if err != nil {
+		return err
+	}
+	if info.Guardianship != guardianship.Index {
+		return fmt.Errorf("%s is not a contract of guardianship %d", address.Hex(), guardianship.Index)
+	}
+	if err := modify(info, ctx.Args().Get(1)); err != nil {
+		return err
+	}
+	if info.State == accessrights.ContractStateMigrated && info.NewAddress == (common.Address{}) {
+		return fmt.Errorf("migrated contracts need a --%s", newAddressFlag.Name)
+	}
+	tx, err := client.SetContractInfo(opts, info)
+	if err != nil {
+		return err
+	}
+	result, err := wait(client, tx)
+	if err != nil {
+		return err
+	}
+	updated, err := client.ContractInfo(latest(), address)
+	if err != nil {
+		return err
+	}
+	if *updated != *info {
+		return fmt.Errorf("transaction %s was mined but %s wasn't updated", result.Tx.Hex(), address.Hex())
+	}
+	return output(ctx, struct {
+		*transactionResult
+		Contract *accessrights.ContractInfo `json:"contract"`
+	}{result, updated}, txTable(result), contractTable(updated))
+}
+
+// contractFunctionGroup restricts a function of a contract to a set of access
+// groups.
+func contractFunctionGroup(ctx *cli.Context) error {
+	address, err := addressArg(ctx, 0, "contract")
+	if err != nil {
+		return err
+	}
+	if ctx.NArg() < 3 {
+		return fmt.Errorf("missing function or group argument")
+	}
+	selector, err := parseSelector(ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	group, err := parseGroup(ctx.Args().Get(2))
+	if err != nil {
+		return err
+	}
+	client, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	opts, err := newTransactor(ctx)
+	if err != nil {
+		return err
+	}
+	guardianship, err := requireGuardian(client, opts)
+	if err != nil {
+		return err
+	}
+	info, err := client.ContractInfo(latest(), address)
+	if err != nil {
+		return err
+	}
+	if info.Guardianship != guardianship.Index {
+		return fmt.Errorf("%s is not a contract of guardianship %d", address.Hex(), guardianship.Index)
+	}
+	functions, err := client.FunctionGroups(latest(), address)
+	if err != nil {
+		return err
+	}
+	index := uint64(len(functions))
+	for _, function := range functions {
+		if bytes.Equal(function.Selector, selector[:]) {
+			index = function.Index
+			break
+		}
+	}
+	if ctx.IsSet(indexFlag.Name) {
+		index = ctx.Uint64(indexFlag.Name)
+	}
+	if index > uint64(len(functions)) {
+		return fmt.Errorf("slot %d follows an empty slot and would be ignored, the next free slot is %d", index, len(functions))
+	}
+	tx, err := client.SetFunctionGroup(opts, address, index, selector, group)
+	if err != nil {
+		return err
+	}
+	result, err := wait(client, tx)
+	if err != nil {
+		return err
+	}
+	entry, err := client.ReadContractFunctionIndex(latest(), address, new(big.Int).SetUint64(index))
+	if err != nil {
+		return err
+	}
+	if entry.FunctionAddress != accessrights.FunctionAddress(selector) || entry.AccessGroup.Cmp(group) != 0 {
+		return fmt.Errorf("transaction %s was mined but the function group of %s wasn't written", result.Tx.Hex(), address.Hex())
+	}
+	function := accessrights.FunctionGroup{Index: index, Selector: selector[:], AccessGroup: group}
+
+	t := txTable(result)
+	t.addRow("Index", function.Index)
+	t.addRow("Selector", function.Selector)
+	t.addRow("Access group", fmt.Sprintf("%#x", function.AccessGroup))
+
+	return output(ctx, struct {
+		*transactionResult
+		Function accessrights.FunctionGroup `json:"function"`
+	}{result, function}, t)
+}
+
+// contractTable is the table of the entry of a contract.
+func contractTable(info *accessrights.ContractInfo) *table {
+	t := new(table)
+	t.addRow("Contract", info.Address.Hex())
+	t.addRow("Name", info.Name)
+	t.addRow("Guardianship", info.Guardianship)
+	t.addRow("State", info.State)
+	t.addRow("New address", formatAddress(info.NewAddress))
+	t.addRow("Write valid", info.WriteValid)
+	t.addRow("Global read valid", info.GlobalReadValid)
+	return t
+}
+
+// parseState parses a contract lifecycle state, by name or number.
+func parseState(s string) (accessrights.ContractState, error) {
+	if n, err := strconv.ParseUint(s, 0, 64); err == nil {
+		return accessrights.ContractState(n), nil
+	}
+	for state := accessrights.ContractStateDisabled; state <= accessrights.ContractStateRetired; state++ {
+		if state.String() == strings.ToLower(s) {
+			return state, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid contract state %q", s)
+}
+
+// parseSelector parses a function given by its signature or its selector.
+func parseSelector(s string) (selector [4]byte, err error) {
+	if strings.Contains(s, "(") {
+		copy(selector[:], crypto.Keccak256([]byte(s)))
+		return selector, nil
+	}
+	raw, err := hexutil.Decode(s)
+	if err != nil || len(raw) != len(selector) {
+		return selector, fmt.Errorf("invalid function %q, expected a signature or a 4 byte selector", s)
+	}
+	copy(selector[:], raw)
+	return selector, nil
+}
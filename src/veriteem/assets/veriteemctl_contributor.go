@@ -0,0 +1,201 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/veriteem/accessrights"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	nameFlag = cli.StringFlag{
+		Name:  "name",
+		Usage: "Name of the entry",
+	}
+	limitFlag = cli.Uint64Flag{
+		Name:  "limit",
+		Usage: "Number of blocks the contributor has to wait between two writes",
+	}
+	groupFlag = cli.StringFlag{
+		Name:  "group",
+		Usage: "Bit mask of the contributor's access groups, decimal or 0x prefixed hex",
+		Value: "0",
+	}
+)
+
+var commandContributor = cli.Command{
+	Name:  "contributor",
+	Usage: "Manage the contributors of a guardianship",
+	Subcommands: []cli.Command{
+		{
+			Name:      "create",
+			Usage:     "Add a contributor to the sender's guardianship, or update it",
+			ArgsUsage: "<address>",
+			Flags:     []cli.Flag{nameFlag, limitFlag, groupFlag},
+			Action:    contributorCreate,
+		},
+		{
+			Name:      "delete",
+			Usage:     "Remove a contributor from the sender's guardianship",
+			ArgsUsage: "<address>",
+			Action:    contributorDelete,
+		},
+		{
+			Name:      "show",
+			Usage:     "Show the entry of a contributor",
+			ArgsUsage: "<address>",
+			Action:    contributorShow,
+		},
+	},
+}
+
+// contributorCreate adds a contributor to the guardianship of the sender. The
+// contract doesn't add contributors twice, so contributors already belonging
+// to the guardianship are updated instead.
+func contributorCreate(ctx *cli.Context) error {
+	address, err := addressArg(ctx, 0, "contributor")
+	if err != nil {
+		return err
+	}
+	group, err := parseGroup(ctx.String(groupFlag.Name))
+	if err != nil {
+		return err
+	}
+	client, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	opts, err := newTransactor(ctx)
+	if err != nil {
+		return err
+	}
+	guardianship, err := requireGuardian(client, opts)
+	if err != nil {
+		return err
+	}
+	current, err := client.Contributor(latest(), address)
+	if err != nil {
+		return err
+	}
+	var (
+		name  = ctx.String(nameFlag.Name)
+		limit = new(big.Int).SetUint64(ctx.Uint64(limitFlag.Name))
+		tx    *types.Transaction
+	)
+	switch current.Guardianship {
+	case 0:
+		tx, err = client.CreateContributor(opts, address, name, limit, group)
+	case guardianship.Index:
+		tx, err = client.WriteContributor(opts, address, name, limit, group)
+	default:
+		return fmt.Errorf("%s is a contributor of guardianship %d", address.Hex(), current.Guardianship)
+	}
+	if err != nil {
+		return err
+	}
+	result, err := wait(client, tx)
+	if err != nil {
+		return err
+	}
+	contributor, err := client.Contributor(latest(), address)
+	if err != nil {
+		return err
+	}
+	if contributor.Guardianship != guardianship.Index {
+		return fmt.Errorf("transaction %s was mined but %s wasn't added", result.Tx.Hex(), address.Hex())
+	}
+	return output(ctx, struct {
+		*transactionResult
+		Contributor *accessrights.Contributor `json:"contributor"`
+	}{result, contributor}, txTable(result), contributorTable(contributor))
+}
+
+// contributorDelete removes a contributor from the guardianship of the sender.
+func contributorDelete(ctx *cli.Context) error {
+	address, err := addressArg(ctx, 0, "contributor")
+	if err != nil {
+		return err
+	}
+	client, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	opts, err := newTransactor(ctx)
+	if err != nil {
+		return err
+	}
+	guardianship, err := requireGuardian(client, opts)
+	if err != nil {
+		return err
+	}
+	current, err := client.Contributor(latest(), address)
+	if err != nil {
+		return err
+	}
+	if current.Guardianship != guardianship.Index {
+		return fmt.Errorf("%s is not a contributor of guardianship %d", address.Hex(), guardianship.Index)
+	}
+	tx, err := client.DeleteContributor(opts, address)
+	if err != nil {
+		return err
+	}
+	result, err := wait(client, tx)
+	if err != nil {
+		return err
+	}
+	contributor, err := client.Contributor(latest(), address)
+	if err != nil {
+		return err
+	}
+	if contributor.Guardianship != 0 {
+		return fmt.Errorf("transaction %s was mined but %s wasn't deleted", result.Tx.Hex(), address.Hex())
+	}
+	return output(ctx, result, txTable(result))
+}
+
+// contributorShow prints the entry of a contributor.
+func contributorShow(ctx *cli.Context) error {
+	address, err := addressArg(ctx, 0, "contributor")
+	if err != nil {
+		return err
+	}
+	client, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	contributor, err := client.Contributor(callOpts(ctx), address)
+	if err != nil {
+		return err
+	}
+	return output(ctx, contributor, contributorTable(contributor))
+}
+
+// contributorTable is the table of the entry of a contributor.
+func contributorTable(contributor *accessrights.Contributor) *table {
+	t := new(table)
+	t.addRow("Contributor", contributor.Address.Hex())
+	t.addRow("Name", contributor.Name)
+	t.addRow("Guardianship", contributor.Guardianship)
+	t.addRow("Limit", contributor.Limit)
+	t.addRow("Last block", contributor.LastBlockNumber)
+	t.addRow("Access group", fmt.Sprintf("%#x", contributor.AccessGroup))
+	return t
+}
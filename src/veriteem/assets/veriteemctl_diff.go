@@ -0,0 +1,125 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandDiff = cli.Command{
+	Name:      "diff",
+	Usage:     "Report the permission changes between two blocks",
+	ArgsUsage: "<from block> [<to block>]",
+	Action:    permissionDiff,
+	Description: `
+Lists the guardians added or removed, the contributors created, deleted or
+whose limit or access groups changed, and the contracts registered, unregistered
+or whose flags, state, sub guardianships or function groups changed between
+the two blocks. The end of the period defaults to the latest block. The node
+has to serve the veriteem RPC API.`,
+}
+
+// permissionDiffResult is the result of veriteem_getPermissionDiff.
+type permissionDiffResult struct {
+	FromBlock hexutil.Uint64 `json:"fromBlock"`
+	ToBlock   hexutil.Uint64 `json:"toBlock"`
+	vm.AccessDiff
+}
+
+// permissionDiff prints the permission changes between two blocks.
+func permissionDiff(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return fmt.Errorf("missing from block argument")
+	}
+	from, err := blockArg(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	to := "latest"
+	if ctx.NArg() > 1 {
+		if to, err = blockArg(ctx.Args().Get(1)); err != nil {
+			return err
+		}
+	}
+	client, err := rpc.Dial(ctx.GlobalString(rpcFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	diff := new(permissionDiffResult)
+	if err := client.CallContext(context.Background(), diff, "veriteem_getPermissionDiff", from, to); err != nil {
+		return err
+	}
+	t := &table{header: []string{"KIND", "ADDRESS", "GUARDIANSHIP", "CHANGE", "DETAILS"}}
+	for _, change := range diff.Guardians {
+		t.addRow("guardian", change.Guardian.Hex(), uint64(change.Guardianship), change.Change, "")
+	}
+	for _, change := range diff.Contributors {
+		t.addRow("contributor", change.Address.Hex(), uint64(change.Guardianship), change.Change, formatFields(change.Fields))
+	}
+	for _, change := range diff.Contracts {
+		t.addRow("contract", change.Address.Hex(), uint64(change.Guardianship), change.Change, formatFields(change.Fields))
+	}
+	summary := new(table)
+	summary.addRow("From block", uint64(diff.FromBlock))
+	summary.addRow("To block", uint64(diff.ToBlock))
+	if diff.Empty() {
+		summary.addRow("Changes", "none")
+		return output(ctx, diff, summary)
+	}
+	return output(ctx, diff, summary, t)
+}
+
+// blockArg converts a block number argument, decimal or "latest", into its RPC
+// form.
+func blockArg(s string) (string, error) {
+	if s == "latest" {
+		return s, nil
+	}
+	number, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid block %q", s)
+	}
+	return hexutil.EncodeUint64(number), nil
+}
+
+// formatFields formats the changed fields of an entry for a table cell.
+func formatFields(fields []vm.FieldChange) string {
+	changes := make([]string, len(fields))
+	for i, field := range fields {
+		changes[i] = fmt.Sprintf("%s: %s -> %s", field.Field, formatValue(field.From), formatValue(field.To))
+	}
+	return strings.Join(changes, ", ")
+}
+
+// formatValue formats the decoded JSON value of a field, showing unset ones
+// as "-".
+func formatValue(value interface{}) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprint(value)
+}
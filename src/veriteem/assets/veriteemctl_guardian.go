@@ -0,0 +1,150 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/veriteem/accessrights"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandGuardian = cli.Command{
+	Name:  "guardian",
+	Usage: "Vote on guardians and list the guardianships",
+	Subcommands: []cli.Command{
+		{
+			Name:  "vote",
+			Usage: "Vote to add or remove a guardian",
+			Description: `
+Every guardianship has one vote to add and one vote to remove a guardian. A
+vote passes once more guardianships agree than disagree. Voting for the zero
+address withdraws the guardianship's vote.`,
+			Subcommands: []cli.Command{
+				{
+					Name:      "add",
+					Usage:     "Vote to make an account the guardian of a new guardianship",
+					ArgsUsage: "<candidate>",
+					Action: func(ctx *cli.Context) error {
+						return guardianVote(ctx, true)
+					},
+				},
+				{
+					Name:      "remove",
+					Usage:     "Vote to remove a guardianship, disabling its contracts and contributors",
+					ArgsUsage: "<guardian>",
+					Action: func(ctx *cli.Context) error {
+						return guardianVote(ctx, false)
+					},
+				},
+			},
+		},
+		{
+			Name:      "stats",
+			Usage:     "List the guardianships, or tally the votes for an account",
+			ArgsUsage: "[<candidate>]",
+			Action:    guardianStats,
+		},
+	},
+}
+
+// guardianVote casts the vote of the sender's guardianship to add or remove a
+// guardian.
+func guardianVote(ctx *cli.Context, add bool) error {
+	candidate, err := addressArg(ctx, 0, "candidate")
+	if err != nil {
+		return err
+	}
+	client, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	opts, err := newTransactor(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := requireGuardian(client, opts); err != nil {
+		return err
+	}
+	tx, err := client.GuardianshipVote(opts, candidate, add)
+	if err != nil {
+		return err
+	}
+	result, err := wait(client, tx)
+	if err != nil {
+		return err
+	}
+	stats, err := client.VoteStats(latest(), candidate)
+	if err != nil {
+		return err
+	}
+	_, guardian, err := client.GuardianshipOf(latest(), candidate)
+	if err != nil {
+		return err
+	}
+	agree, disagree := stats.AddAgree, stats.AddDisagree
+	if !add {
+		agree, disagree = stats.RemoveAgree, stats.RemoveDisagree
+	}
+	t := txTable(result)
+	t.addRow("Candidate", candidate.Hex())
+	t.addRow("Votes", fmt.Sprintf("%d of %d", agree, agree+disagree))
+	t.addRow("Guardian", guardian)
+
+	return output(ctx, struct {
+		*transactionResult
+		Candidate common.Address          `json:"candidate"`
+		Votes     *accessrights.VoteStats `json:"votes"`
+		Guardian  bool                    `json:"guardian"`
+	}{result, candidate, stats, guardian}, t)
+}
+
+// guardianStats lists the guardianships or, given a candidate, the votes to
+// add or remove it.
+func guardianStats(ctx *cli.Context) error {
+	client, err := dial(ctx)
+	if err != nil {
+		return err
+	}
+	if ctx.NArg() > 0 {
+		candidate, err := addressArg(ctx, 0, "candidate")
+		if err != nil {
+			return err
+		}
+		stats, err := client.VoteStats(callOpts(ctx), candidate)
+		if err != nil {
+			return err
+		}
+		t := &table{header: []string{"VOTE", "AGREE", "DISAGREE"}}
+		t.addRow("add", stats.AddAgree, stats.AddDisagree)
+		t.addRow("remove", stats.RemoveAgree, stats.RemoveDisagree)
+		return output(ctx, stats, t)
+	}
+	guardianships, err := client.Guardianships(callOpts(ctx))
+	if err != nil {
+		return err
+	}
+	t := &table{header: []string{"INDEX", "NAME", "GUARDIAN A", "GUARDIAN B", "CONTRACTS", "CONTRIBUTORS", "ADD VOTE", "REMOVE VOTE"}}
+	for _, g := range guardianships {
+		t.addRow(g.Index, g.Name, formatAddress(g.Guardians[0]), formatAddress(g.Guardians[1]), g.Contracts, g.Contributors, formatAddress(g.AddVote), formatAddress(g.RemoveVote))
+	}
+	if guardianships == nil {
+		guardianships = []*accessrights.Guardianship{}
+	}
+	return output(ctx, guardianships, t)
+}
@@ -0,0 +1,257 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// veriteemctl administers the guardianships, contributors and contracts held
+// by the AccessRights contract of a Veriteem chain, through the RPC endpoint of
+// a node.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/veriteem/accessrights"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+
+var app *cli.App
+
+func init() {
+	app = utils.NewApp(gitCommit, "Veriteem guardianship administration tool")
+	app.Flags = []cli.Flag{
+		rpcFlag,
+		contractFlag,
+		blockFlag,
+		jsonFlag,
+		fromFlag,
+		signerFlag,
+		utils.KeyStoreDirFlag,
+		utils.PasswordFileFlag,
+		chainIDFlag,
+	}
+	app.Commands = []cli.Command{
+		commandGuardian,
+		commandContributor,
+		commandContract,
+		commandSnapshot,
+		commandDiff,
+		commandWatch,
+	}
+}
+
+// Commonly used command line flags.
+var (
+	rpcFlag = cli.StringFlag{
+		Name:  "rpc",
+		Usage: "RPC endpoint of the Veriteem node",
+		Value: "http://localhost:8545",
+	}
+	contractFlag = cli.StringFlag{
+		Name:  "contract",
+		Usage: "Address of the AccessRights contract",
+		Value: accessrights.DefaultAddress.Hex(),
+	}
+	blockFlag = cli.Int64Flag{
+		Name:  "block",
+		Usage: "Block to read the permission tables at (default = latest)",
+		Value: -1,
+	}
+	jsonFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Output JSON instead of tables",
+	}
+	fromFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "Guardian account to send transactions from",
+	}
+	signerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: `Backend signing transactions, "keystore" or "remotewallet"`,
+		Value: "keystore",
+	}
+	chainIDFlag = cli.Uint64Flag{
+		Name:  "chainid",
+		Usage: "Chain id transactions are signed for, 0 for unprotected transactions",
+		Value: 18535500,
+	}
+)
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// dial connects to the node and binds to its AccessRights contract.
+func dial(ctx *cli.Context) (*accessrights.Client, error) {
+	address, err := parseAddress(ctx.GlobalString(contractFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %v", contractFlag.Name, err)
+	}
+	return accessrights.Dial(ctx.GlobalString(rpcFlag.Name), address)
+}
+
+// callOpts returns the options of the calls reading the permission tables at
+// the block selected by --block.
+func callOpts(ctx *cli.Context) *bind.CallOpts {
+	opts := &bind.CallOpts{Context: context.Background()}
+	if block := ctx.GlobalInt64(blockFlag.Name); block >= 0 {
+		opts.BlockNumber = big.NewInt(block)
+	}
+	return opts
+}
+
+// latest returns the options of the calls checking the outcome of a mined
+// transaction.
+func latest() *bind.CallOpts {
+	return &bind.CallOpts{Context: context.Background()}
+}
+
+// parseAddress parses a hex encoded address.
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %q", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+// addressArg parses the address given as the n-th argument of a command.
+func addressArg(ctx *cli.Context, n int, name string) (common.Address, error) {
+	if ctx.NArg() <= n {
+		return common.Address{}, fmt.Errorf("missing %s argument", name)
+	}
+	return parseAddress(ctx.Args().Get(n))
+}
+
+// parseGroup parses an access group bit mask, in decimal or 0x prefixed hex.
+func parseGroup(s string) (*big.Int, error) {
+	group, ok := math.ParseBig256(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid access group %q", s)
+	}
+	return group, nil
+}
+
+// parseBool parses the true or false argument of the commands setting a flag.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true", "on", "yes", "1":
+		return true, nil
+	case "false", "off", "no", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q, expected true or false", s)
+}
+
+// requireGuardian returns the guardianship of the account transactions are
+// sent from. The AccessRights contract silently ignores administration by
+// anyone else, so this is checked before sending anything.
+func requireGuardian(client *accessrights.Client, opts *bind.TransactOpts) (*accessrights.Guardianship, error) {
+	guardianship, ok, err := client.GuardianshipOf(latest(), opts.From)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("account %s is not a guardian", opts.From.Hex())
+	}
+	return guardianship, nil
+}
+
+// transactionResult is the outcome of an administration transaction.
+type transactionResult struct {
+	Tx    common.Hash `json:"tx"`
+	Block uint64      `json:"block"`
+}
+
+// wait waits for an administration transaction to be mined.
+func wait(client *accessrights.Client, tx *types.Transaction) (*transactionResult, error) {
+	receipt, err := client.Wait(context.Background(), tx)
+	if err != nil {
+		return nil, err
+	}
+	return &transactionResult{Tx: tx.Hash(), Block: receipt.BlockNumber.Uint64()}, nil
+}
+
+// table is the human readable form of a command's output.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// addRow appends a row of values to the table.
+func (t *table) addRow(values ...interface{}) {
+	row := make([]string, len(values))
+	for i, value := range values {
+		row[i] = fmt.Sprint(value)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// output writes out the output of a command: result as indented JSON with
+// --json, or the tables otherwise.
+func output(ctx *cli.Context, result interface{}, tables ...*table) error {
+	if ctx.GlobalBool(jsonFlag.Name) {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	for i, t := range tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if len(t.header) > 0 {
+			fmt.Fprintln(w, strings.Join(t.header, "\t"))
+		}
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+	}
+	return w.Flush()
+}
+
+// txTable is the table of a mined administration transaction.
+func txTable(result *transactionResult) *table {
+	t := new(table)
+	t.addRow("Transaction", result.Tx.Hex())
+	t.addRow("Block", result.Block)
+	return t
+}
+
+// formatAddress formats an address for tables, showing unset ones as "-".
+func formatAddress(address common.Address) string {
+	if address == (common.Address{}) {
+		return "-"
+	}
+	return address.Hex()
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"remotewallet"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/console"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/veriteem/accessrights"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// signFn signs a transaction for the chain with the given id, nil if the
+// transaction isn't replay protected.
+type signFn func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+
+// newTransactor returns the options of the transactions sent from the --from
+// account, signed by the backend selected with --signer.
+func newTransactor(ctx *cli.Context) (*bind.TransactOpts, error) {
+	if !ctx.GlobalIsSet(fromFlag.Name) {
+		return nil, fmt.Errorf("--%s is required to send transactions", fromFlag.Name)
+	}
+	from, err := parseAddress(ctx.GlobalString(fromFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %v", fromFlag.Name, err)
+	}
+	var sign signFn
+	switch backend := ctx.GlobalString(signerFlag.Name); backend {
+	case "keystore":
+		sign, err = keystoreSigner(ctx, from)
+	case "remotewallet":
+		sign, err = remoteSigner(from)
+	default:
+		err = fmt.Errorf("unknown signer %q, expected keystore or remotewallet", backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var chainID *big.Int
+	if id := ctx.GlobalUint64(chainIDFlag.Name); id != 0 {
+		chainID = new(big.Int).SetUint64(id)
+	}
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, errors.New("not authorized to sign this account")
+			}
+			return sign(tx, chainID)
+		},
+		// Veriteem chains don't price gas
+		GasPrice: new(big.Int),
+		GasLimit: accessrights.TxGas,
+	}, nil
+}
+
+// keystoreSigner signs with the key of from in the --keystore directory, which
+// is unlocked with the first line of --password or a passphrase prompted for.
+func keystoreSigner(ctx *cli.Context, from common.Address) (signFn, error) {
+	keydir := ctx.GlobalString(utils.KeyStoreDirFlag.Name)
+	if keydir == "" {
+		return nil, fmt.Errorf("--%s is required by the keystore signer", utils.KeyStoreDirFlag.Name)
+	}
+	ks := keystore.NewKeyStore(keydir, keystore.StandardScryptN, keystore.StandardScryptP)
+	account, err := ks.Find(accounts.Account{Address: from})
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %v", from.Hex(), err)
+	}
+	var passphrase string
+	if passwords := utils.MakePasswordList(ctx); len(passwords) > 0 {
+		passphrase = passwords[0]
+	} else {
+		passphrase, err = console.Stdin.PromptPassword(fmt.Sprintf("Passphrase of %s: ", from.Hex()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %v", err)
+		}
+	}
+	if err := ks.Unlock(account, passphrase); err != nil {
+		return nil, fmt.Errorf("failed to unlock %s: %v", from.Hex(), err)
+	}
+	return func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return ks.SignTx(account, tx, chainID)
+	}, nil
+}
+
+// remoteSigner signs through the Veriteem signing server, on which the owner of
+// from has to have unlocked the account.
+func remoteSigner(from common.Address) (signFn, error) {
+	hub, err := remotewallet.NewVeriteemWallet()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the signing server: %v", err)
+	}
+	for _, wallet := range hub.Wallets() {
+		if err := wallet.Open(""); err != nil && err != accounts.ErrWalletAlreadyOpen {
+			return nil, fmt.Errorf("failed to open %s: %v", wallet.URL(), err)
+		}
+		// Contains only knows of the accounts the wallet listed last
+		for _, account := range wallet.Accounts() {
+			if account.Address != from {
+				continue
+			}
+			wallet, account := wallet, account
+			return func(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+				return wallet.SignTx(account, tx, chainID)
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("account %s not found on the signing server", from.Hex())
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	formatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: `Output format, "json" or "csv"`,
+		Value: "json",
+	}
+	outputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "File to write to (default = standard output)",
+	}
+)
+
+var commandSnapshot = cli.Command{
+	Name:   "snapshot",
+	Usage:  "Export the permission tables at a block",
+	Flags:  []cli.Flag{formatFlag, outputFlag},
+	Action: snapshotExport,
+	Description: `
+Exports every guardianship of the access contract, with the entries of the
+contracts and contributors on their lists, at the block selected by --block.
+The node has to serve the veriteem RPC API.
+
+The CSV export has one row per guardianship, contract, function group and
+contributor, told apart by the kind column. Lists within a cell are separated
+by semicolons.`,
+}
+
+// permissionSnapshot is the result of veriteem_getPermissionSnapshot.
+type permissionSnapshot struct {
+	Block hexutil.Uint64 `json:"block"`
+	Hash  common.Hash    `json:"hash"`
+	vm.AccessSnapshot
+}
+
+// snapshotColumns is the header of the CSV export.
+var snapshotColumns = []string{
+	"kind", "guardianship", "address", "name",
+	"guardians", "add_vote", "remove_vote",
+	"write_valid", "global_read_valid", "state", "new_address", "sub_guardianships",
+	"function_index", "selector",
+	"limit", "access_group", "last_block",
+}
+
+// snapshotExport writes out the permission tables at the selected block.
+func snapshotExport(ctx *cli.Context) error {
+	format := ctx.String(formatFlag.Name)
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unknown format %q, expected json or csv", format)
+	}
+	snapshot, err := fetchSnapshot(ctx, ctx.GlobalInt64(blockFlag.Name))
+	if err != nil {
+		return err
+	}
+	out := io.Writer(os.Stdout)
+	if path := ctx.String(outputFlag.Name); path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	if format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snapshot)
+	}
+	return writeSnapshotCSV(out, snapshot)
+}
+
+// fetchSnapshot retrieves the permission tables at block, or at the latest
+// block if negative.
+func fetchSnapshot(ctx *cli.Context, block int64) (*permissionSnapshot, error) {
+	client, err := rpc.Dial(ctx.GlobalString(rpcFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	number := "latest"
+	if block >= 0 {
+		number = hexutil.EncodeUint64(uint64(block))
+	}
+	snapshot := new(permissionSnapshot)
+	if err := client.CallContext(context.Background(), snapshot, "veriteem_getPermissionSnapshot", number); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// writeSnapshotCSV writes a snapshot as CSV, one row per table entry.
+func writeSnapshotCSV(out io.Writer, snapshot *permissionSnapshot) error {
+	w := csv.NewWriter(out)
+	w.Write(snapshotColumns)
+
+	row := func(values map[string]string) {
+		record := make([]string, len(snapshotColumns))
+		for i, column := range snapshotColumns {
+			record[i] = values[column]
+		}
+		w.Write(record)
+	}
+	for _, g := range snapshot.Guardianships {
+		row(map[string]string{
+			"kind":         "guardianship",
+			"guardianship": fmt.Sprint(uint64(g.Index)),
+			"name":         g.Name,
+			"guardians":    joinAddresses(g.Guardians[:]),
+			"add_vote":     joinAddresses([]common.Address{g.AddVote}),
+			"remove_vote":  joinAddresses([]common.Address{g.RemoveVote}),
+		})
+	}
+	for _, c := range snapshot.Contracts {
+		subGuardianships := make([]string, len(c.SubGuardianships))
+		for i, index := range c.SubGuardianships {
+			subGuardianships[i] = fmt.Sprint(uint64(index))
+		}
+		row(map[string]string{
+			"kind":              "contract",
+			"guardianship":      fmt.Sprint(uint64(c.Guardianship)),
+			"address":           c.Address.Hex(),
+			"name":              c.Name,
+			"write_valid":       fmt.Sprint(c.WriteValid),
+			"global_read_valid": fmt.Sprint(c.GlobalReadValid),
+			"state":             c.State,
+			"new_address":       joinAddresses([]common.Address{c.NewAddress}),
+			"sub_guardianships": strings.Join(subGuardianships, ";"),
+		})
+		for _, f := range c.Functions {
+			row(map[string]string{
+				"kind":           "function",
+				"guardianship":   fmt.Sprint(uint64(c.Guardianship)),
+				"address":        c.Address.Hex(),
+				"function_index": fmt.Sprint(uint64(f.Index)),
+				"selector":       f.Selector.String(),
+				"access_group":   f.AccessGroup.String(),
+			})
+		}
+	}
+	for _, c := range snapshot.Contributors {
+		row(map[string]string{
+			"kind":         "contributor",
+			"guardianship": fmt.Sprint(uint64(c.Guardianship)),
+			"address":      c.Address.Hex(),
+			"name":         c.Name,
+			"limit":        c.Limit.ToInt().String(),
+			"access_group": c.AccessGroup.String(),
+			"last_block":   c.LastBlockNumber.ToInt().String(),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// joinAddresses formats the set addresses of a list for a CSV cell.
+func joinAddresses(addresses []common.Address) string {
+	var set []string
+	for _, address := range addresses {
+		if address != (common.Address{}) {
+			set = append(set, address.Hex())
+		}
+	}
+	return strings.Join(set, ";")
+}
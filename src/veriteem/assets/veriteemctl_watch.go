@@ -0,0 +1,88 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandWatch = cli.Command{
+	Name:   "watch",
+	Usage:  "Follow the permission changes of new blocks",
+	Action: permissionWatch,
+	Description: `
+Prints every guardian added or removed, contributor changed and contract entry
+changed by the transactions of new blocks, until interrupted. The subscription
+needs a websocket or IPC endpoint, passed with --rpc, of a node serving the
+veriteem RPC API. With --json every change is printed as a line of JSON.`,
+}
+
+// permissionChange is a notification of veriteem_subscribe("permissionChanges").
+type permissionChange struct {
+	Type         string           `json:"type"`
+	Change       string           `json:"change"`
+	Address      common.Address   `json:"address"`
+	Guardianship hexutil.Uint64   `json:"guardianship"`
+	Fields       []vm.FieldChange `json:"fields,omitempty"`
+	BlockNumber  hexutil.Uint64   `json:"blockNumber"`
+	BlockHash    common.Hash      `json:"blockHash"`
+	TxHash       common.Hash      `json:"transactionHash"`
+	TxIndex      hexutil.Uint     `json:"transactionIndex"`
+}
+
+// permissionWatch prints the permission changes notified by the node.
+func permissionWatch(ctx *cli.Context) error {
+	client, err := rpc.Dial(ctx.GlobalString(rpcFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	changes := make(chan permissionChange)
+	sub, err := client.Subscribe(context.Background(), "veriteem", changes, "permissionChanges")
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		select {
+		case change := <-changes:
+			if ctx.GlobalBool(jsonFlag.Name) {
+				if err := enc.Encode(&change); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Printf("block %d tx %s: %s %s guardianship %d %s\n",
+				uint64(change.BlockNumber), change.TxHash.Hex(), change.Type,
+				change.Address.Hex(), uint64(change.Guardianship), formatFields(change.Fields))
+		case err := <-sub.Err():
+			return err
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package veriteemtest runs an in-memory Veriteem chain for testing the access
+// control of the EVM. The chain is booted from a Veriteem genesis, including
+// the AccessRights contract it installs, and is driven without any network:
+// blocks are only mined when a test commits them.
+//
+// Guardians, contributors and contracts are scripted through the helpers of
+// Chain, which send the transactions the administration scripts would, and
+// the outcome of calls and contract creations is read back from receipts and
+// from the access contract itself.
+package veriteemtest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// TxGas is the gas limit of the transactions sent by the helpers. Veriteem
+// chains don't price gas, so transactions are sent with a zero gas price and
+// accounts needn't be funded.
+const TxGas uint64 = 4000000
+
+// errNoAccessControl is returned if the genesis doesn't activate access control
+// at block zero.
+var errNoAccessControl = errors.New("genesis doesn't activate access control")
+
+// LoadGenesis reads a Veriteem genesis, such as assets/genesis.json.
+func LoadGenesis(path string) (*core.Genesis, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	genesis := new(core.Genesis)
+	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+		return nil, fmt.Errorf("invalid genesis file %s: %v", path, err)
+	}
+	return genesis, nil
+}
+
+// Account is a key the chain's transactions can be signed with.
+type Account struct {
+	Key     *ecdsa.PrivateKey
+	Address common.Address
+}
+
+// NewAccount generates a fresh account.
+func NewAccount() *Account {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		panic(fmt.Sprintf("failed to generate key: %v", err))
+	}
+	return &Account{Key: key, Address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// Chain is an in-memory Veriteem chain.
+type Chain struct {
+	Backend *backends.SimulatedBackend
+	Genesis *core.Genesis
+	Access  common.Address // Address of the access contract
+
+	abi abi.ABI // ABI of the AccessRights contract
+}
+
+// NewChain boots a chain from genesis. The genesis has to activate access
+// control at block zero.
+func NewChain(genesis *core.Genesis) (*Chain, error) {
+	if genesis.Config == nil {
+		return nil, errNoAccessControl
+	}
+	access, ok := genesis.Config.VeriteemAccess(common.Big0)
+	if !ok {
+		return nil, errNoAccessControl
+	}
+	parsed, err := abi.JSON(strings.NewReader(AccessRightsABI))
+	if err != nil {
+		return nil, err
+	}
+	return &Chain{
+		Backend: backends.NewSimulatedBackendWithGenesis(genesis),
+		Genesis: genesis,
+		Access:  access.Contract,
+		abi:     parsed,
+	}, nil
+}
+
+// NewChainFromFile boots a chain from the genesis file at path.
+func NewChainFromFile(path string) (*Chain, error) {
+	genesis, err := LoadGenesis(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewChain(genesis)
+}
+
+// Config returns the chain configuration.
+func (c *Chain) Config() *params.ChainConfig {
+	return c.Genesis.Config
+}
+
+// BlockNumber returns the number of the last mined block.
+func (c *Chain) BlockNumber() uint64 {
+	header, err := c.Backend.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		panic(fmt.Sprintf("failed to read head: %v", err))
+	}
+	return header.Number.Uint64()
+}
+
+// Mine mines n blocks, which is how tests let a contributor's rate limit
+// expire.
+func (c *Chain) Mine(n int) {
+	for i := 0; i < n; i++ {
+		c.Backend.Commit()
+	}
+}
+
+// Transact sends a transaction from an account to the contract at to, or
+// deploying a contract if to is nil, mines it and returns its receipt. A
+// receipt with a failed status is not an error: it is the outcome of a denied
+// call or creation.
+func (c *Chain) Transact(from *Account, to *common.Address, input []byte) (*types.Receipt, error) {
+	ctx := context.Background()
+
+	nonce, err := c.Backend.PendingNonceAt(ctx, from.Address)
+	if err != nil {
+		return nil, err
+	}
+	var tx *types.Transaction
+	if to == nil {
+		tx = types.NewContractCreation(nonce, new(big.Int), TxGas, new(big.Int), input)
+	} else {
+		tx = types.NewTransaction(nonce, *to, new(big.Int), TxGas, new(big.Int), input)
+	}
+	tx, err = types.SignTx(tx, types.HomesteadSigner{}, from.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Backend.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	c.Backend.Commit()
+
+	return c.Backend.TransactionReceipt(ctx, tx.Hash())
+}
+
+// Deploy deploys a contract with the given creation code and returns its
+// address. The creation is expected to succeed.
+func (c *Chain) Deploy(from *Account, code []byte) (common.Address, error) {
+	receipt, err := c.Transact(from, nil, code)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return common.Address{}, fmt.Errorf("creation by %x failed", from.Address)
+	}
+	return receipt.ContractAddress, nil
+}
+
+// Call runs input against the contract at to on top of the last mined block,
+// as eth_call would, and returns its output. The output of a call failing a
+// permission check holds the reason of the denial, see RevertReason.
+func (c *Chain) Call(from common.Address, to common.Address, input []byte) ([]byte, error) {
+	msg := ethereum.CallMsg{From: from, To: &to, Gas: TxGas, GasPrice: new(big.Int), Value: new(big.Int), Data: input}
+	return c.Backend.CallContract(context.Background(), msg, nil)
+}
+
+// Succeeded reports whether a transaction succeeded.
+func Succeeded(receipt *types.Receipt) bool {
+	return receipt.Status == types.ReceiptStatusSuccessful
+}
+
+// RevertReason decodes the revert reason in the output of a failed call, such
+// as the reason a permission check reports from the denial errors fork on.
+func RevertReason(output []byte) (string, bool) {
+	if len(output) < 4+64 || !strings.HasPrefix(string(output), "\x08\xc3\x79\xa0") {
+		return "", false
+	}
+	data := output[4:]
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(data)) {
+		return "", false
+	}
+	start := offset.Uint64() + 32
+	size := new(big.Int).SetBytes(data[start-32 : start])
+	if !size.IsUint64() || start+size.Uint64() > uint64(len(data)) {
+		return "", false
+	}
+	return string(data[start : start+size.Uint64()]), true
+}
+
+// Admin sends a transaction invoking method of the access contract on behalf
+// of from. The AccessRights contract ignores unauthorized administration
+// instead of failing, so a successful receipt doesn't mean the tables changed.
+func (c *Chain) Admin(from *Account, method string, args ...interface{}) (*types.Receipt, error) {
+	input, err := c.abi.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return c.Transact(from, &c.Access, input)
+}
+
+// Read calls a constant method of the access contract and decodes its result
+// into out.
+func (c *Chain) Read(out interface{}, method string, args ...interface{}) error {
+	input, err := c.abi.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	output, err := c.Call(common.Address{}, c.Access, input)
+	if err != nil {
+		return err
+	}
+	return c.abi.Unpack(out, method, output)
+}
+
+// InitGuardianship makes guardian the first guardian of the chain, which is
+// only possible while the first guardianship has none.
+func (c *Chain) InitGuardianship(guardian *Account) error {
+	return c.admin(guardian, "InitGuardianship")
+}
+
+// AddGuardian votes for candidate to become the guardian of a new guardianship.
+// Guardianships are added once a majority of the existing guardians agree.
+func (c *Chain) AddGuardian(voter *Account, candidate common.Address) error {
+	return c.admin(voter, "GuardianshipVote", candidate, true)
+}
+
+// CreateContributor adds contributor to the guardianship of guardian.
+func (c *Chain) CreateContributor(guardian *Account, contributor common.Address, name string, limit, accessGroup uint64) error {
+	return c.admin(guardian, "CreateContributor", contributor, name, new(big.Int).SetUint64(limit), new(big.Int).SetUint64(accessGroup))
+}
+
+// WriteContractInfo sets the flags and lifecycle state of a contract owned by
+// the guardianship of guardian.
+func (c *Chain) WriteContractInfo(guardian *Account, contract common.Address, name string, newAddress common.Address, writeValid, globalReadValid bool, state vm.ContractState) error {
+	return c.admin(guardian, "WriteContractInfo", contract, name, newAddress, writeValid, globalReadValid, new(big.Int).SetUint64(uint64(state)))
+}
+
+// WriteContractSubGuardianship lets the contributors of the guardianship of
+// subGuardian write to a contract owned by the guardianship of guardian.
+func (c *Chain) WriteContractSubGuardianship(guardian *Account, contract, subGuardian common.Address, index uint64) error {
+	return c.admin(guardian, "WriteContractSubGuardianship", contract, subGuardian, new(big.Int).SetUint64(index))
+}
+
+// RestrictFunction restricts the function of contract identified by selector
+// to the given access groups, in slot index of the contract's FunctionList.
+func (c *Chain) RestrictFunction(guardian *Account, contract common.Address, index uint64, selector [4]byte, accessGroup uint64) error {
+	return c.admin(guardian, "WriteContractFunctionIndex", contract, new(big.Int).SetUint64(index), vm.FunctionAddress(selector), new(big.Int).SetUint64(accessGroup))
+}
+
+// admin runs an administration method, failing if the transaction fails.
+func (c *Chain) admin(from *Account, method string, args ...interface{}) error {
+	receipt, err := c.Admin(from, method, args...)
+	if err != nil {
+		return err
+	}
+	if !Succeeded(receipt) {
+		return fmt.Errorf("%s by %x failed", method, from.Address)
+	}
+	return nil
+}
+
+// CheckAccess returns the read and write verdicts VerifyContractAccess gives caller
+// on the function of contract identified by selector, at the last mined block.
+func (c *Chain) CheckAccess(caller, contract common.Address, selector [4]byte) (read, write bool, err error) {
+	input, err := c.abi.Pack("VerifyContractAccess", contract, vm.FunctionAddress(selector))
+	if err != nil {
+		return false, false, err
+	}
+	output, err := c.Call(caller, c.Access, input)
+	if err != nil {
+		return false, false, err
+	}
+	var result struct {
+		ReadApproved  bool
+		WriteApproved bool
+	}
+	if err := c.abi.Unpack(&result, "VerifyContractAccess", output); err != nil {
+		return false, false, err
+	}
+	return result.ReadApproved, result.WriteApproved, nil
+}
+
+// Selector returns the 4 byte selector of a function signature, such as
+// "set(uint256)".
+func Selector(signature string) (selector [4]byte) {
+	copy(selector[:], crypto.Keccak256([]byte(signature)))
+	return selector
+}
+
+// AccessRightsABI is the ABI of the AccessRights contract, compiled from
+// scripts/AccessRights.sol.
+const AccessRightsABI = `[{"constant":false,"inputs":[{"name":"ContractAddress","type":"address"},{"name":"SubGuardianId","type":"address"},{"name":"Index","type":"uint256"}],"name":"WriteContractSubGuardianship","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"GuardianAddress","type":"address"}],"name":"GuardianshipIndex","outputs":[{"name":"Match","type":"bool"},{"name":"Index","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"Name","type":"string"}],"name":"WriteGuardianshipName","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"GuardianAddress","type":"address"},{"name":"ListIndex","type":"uint256"}],"name":"ReadGuardianshipContributor","outputs":[{"name":"RetContributorAddress","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"GuardianAddress","type":"address"}],"name":"ReadGuardianship","outputs":[{"name":"Match","type":"bool"},{"name":"GIndex","type":"uint256"},{"name":"GuardianA","type":"address"},{"name":"GuardianB","type":"address"},{"name":"Name","type":"string"},{"name":"ContractListLength","type":"uint256"},{"name":"ContributorListLength","type":"uint256"},{"name":"AddVote","type":"address"},{"name":"RemoveVote","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"ContributorAddress","type":"address"},{"name":"Name","type":"string"},{"name":"Limit","type":"uint256"},{"name":"AccessGroup","type":"uint256"}],"name":"WriteContributor","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"NewGuardianAddress","type":"address"}],"name":"WriteGuardian","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"ContractAddress","type":"address"},{"name":"Index","type":"uint256"}],"name":"ReadContractSubGuardianship","outputs":[{"name":"SubGuardianship","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"ContractId","type":"address"},{"name":"FunctionAddress","type":"address"}],"name":"VerifyContractAccess","outputs":[{"name":"ReadApproved","type":"bool"},{"name":"WriteApproved","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"VoteId","type":"address"},{"name":"AddVote","type":"bool"}],"name":"GuardianshipVote","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"ContributorAddress","type":"address"},{"name":"Name","type":"string"},{"name":"Limit","type":"uint256"},{"name":"AccessGroup","type":"uint256"}],"name":"CreateContributor","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"ContractAddress","type":"address"},{"name":"Name","type":"string"},{"name":"NewAddress","type":"address"},{"name":"WriteValid","type":"bool"},{"name":"GlobalReadValid","type":"bool"},{"name":"State","type":"uint256"}],"name":"WriteContractInfo","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"ContributorAddress","type":"address"}],"name":"DeleteContributor","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"ContractId","type":"address"},{"name":"Guardianship","type":"uint256"}],"name":"VerifyContractSubGuardianshipList","outputs":[{"name":"Match","type":"bool"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"ContractAddress","type":"address"}],"name":"ReadContractInfo","outputs":[{"name":"Guardianship","type":"uint256"},{"name":"Name","type":"string"},{"name":"NewAddress","type":"address"},{"name":"WriteValid","type":"bool"},{"name":"GlobalReadValid","type":"bool"},{"name":"State","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"Contract","type":"address"},{"name":"FunctionIndex","type":"uint256"},{"name":"FunctionAddress","type":"address"},{"name":"AccessGroup","type":"uint256"}],"name":"WriteContractFunctionIndex","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"Contract","type":"address"},{"name":"FunctionIndex","type":"uint256"}],"name":"ReadContractFunctionIndex","outputs":[{"name":"FunctionAddress","type":"address"},{"name":"AccessGroup","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"Index","type":"uint256"}],"name":"ReadGuardianshipIndex","outputs":[{"name":"GuardianA","type":"address"},{"name":"GuardianB","type":"address"},{"name":"Name","type":"string"},{"name":"ContractListLength","type":"uint256"},{"name":"ContributorListLength","type":"uint256"},{"name":"AddVote","type":"address"},{"name":"RemoveVote","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[],"name":"UpdateContributorBlock","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[],"name":"InitGuardianship","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":true,"inputs":[{"name":"GuardianAddress","type":"address"},{"name":"ListIndex","type":"uint256"}],"name":"ReadGuardianshipContract","outputs":[{"name":"RetContractAddress","type":"address"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"Contract","type":"address"},{"name":"FunctionAddress","type":"address"}],"name":"ReadContractFunctionAccessGroup","outputs":[{"name":"Match","type":"bool"},{"name":"AccessGroup","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"ContributorAddress","type":"address"}],"name":"ReadContributor","outputs":[{"name":"Guardianship","type":"uint256"},{"name":"Name","type":"string"},{"name":"Limit","type":"uint256"},{"name":"LastBlockNumber","type":"uint256"},{"name":"AccessGroup","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"NewGuardian","type":"address"}],"name":"GuardianshipVoteStats","outputs":[{"name":"AddAgreeCount","type":"uint256"},{"name":"AddDisagreeCount","type":"uint256"},{"name":"RemoveAgreeCount","type":"uint256"},{"name":"RemoveDisagreeCount","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"ContractAddress","type":"address"}],"name":"CreateContract","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"}]`
@@ -0,0 +1,140 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package veriteemtest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Tests that every call kind is subject to the permission check: a contract
+// blocked to everyone can't be reached through a proxy using CALL, CALLCODE,
+// DELEGATECALL or STATICCALL, while an open one can. Under the original rules
+// only CALL is checked.
+func TestCallKinds(t *testing.T) {
+	var (
+		inc  = Selector("inc()")
+		peek = Selector("peek()") // Reaches the fallback, which doesn't write
+	)
+	kinds := []struct {
+		op    vm.OpCode
+		input [4]byte
+	}{
+		{vm.CALL, inc},
+		{vm.CALLCODE, inc},
+		{vm.DELEGATECALL, inc},
+		{vm.STATICCALL, peek},
+	}
+	for _, config := range testConfigs {
+		chain := newTestChain(t, config.configure)
+		guardian := newGuardianship(t, chain)
+		member := newContributor(t, chain, guardian, 0, 0)
+
+		open := deployContract(t, chain, guardian, counterCode(inc), true)
+		blocked, err := chain.Deploy(guardian, counterCode(inc))
+		if err != nil {
+			t.Fatalf("%s: failed to deploy blocked contract: %v", config.name, err)
+		}
+		if err := chain.WriteContractInfo(guardian, blocked, "blocked", common.Address{}, false, false, vm.ContractStateActive); err != nil {
+			t.Fatalf("%s: failed to block contract: %v", config.name, err)
+		}
+		for _, kind := range kinds {
+			// The proxies are contributors, so that the calls they make may
+			// write to the open contract
+			for _, target := range []struct {
+				addr    common.Address
+				allowed bool
+			}{{open, true}, {blocked, false}} {
+				proxy := deployContract(t, chain, guardian, proxyCode(kind.op, target.addr), true)
+				if err := chain.CreateContributor(guardian, proxy, "proxy", 0, 0); err != nil {
+					t.Fatalf("%s: failed to add proxy contributor: %v", config.name, err)
+				}
+				allowed := target.allowed || (!config.forked && kind.op != vm.CALL)
+
+				before := counter(t, chain, target.addr)
+				if ok := call(t, chain, member, proxy, kind.input); ok != allowed {
+					t.Errorf("%s: %v to %x through proxy: success %v, want %v", config.name, kind.op, target.addr, ok, allowed)
+				}
+				// Only CALL runs the code in the storage of the target
+				want := before
+				if kind.op == vm.CALL && allowed {
+					want++
+				}
+				if have := counter(t, chain, target.addr); have != want {
+					t.Errorf("%s: %v to %x through proxy: counter %d, want %d", config.name, kind.op, target.addr, have, want)
+				}
+			}
+		}
+	}
+}
+
+// Tests that anyone may read from a contract marked GlobalReadValid, through
+// eth_call or a static call, while other contracts deny it. From the static
+// read fork on such reads are answered by the read check alone, which has to
+// agree with the full one.
+func TestPublicReads(t *testing.T) {
+	word := common.LeftPadBytes([]byte{0x01}, 32)
+	for _, config := range testConfigs {
+		chain := newTestChain(t, config.configure)
+		guardian := newGuardianship(t, chain)
+		member := newContributor(t, chain, guardian, 0, 0)
+		outsider := NewAccount()
+
+		public := deployContract(t, chain, guardian, readerCode(), true)
+		private := deployContract(t, chain, guardian, readerCode(), false)
+
+		tests := []struct {
+			caller  common.Address
+			target  common.Address
+			allowed bool
+		}{
+			{outsider.Address, public, true},
+			{member.Address, public, true},
+			{outsider.Address, private, false},
+			{member.Address, private, false}, // Members may write, but not see the output
+		}
+		for _, tt := range tests {
+			output, err := chain.Call(tt.caller, tt.target, nil)
+			if err != nil {
+				t.Fatalf("%s: call failed: %v", config.name, err)
+			}
+			if allowed := bytes.Equal(output, word); allowed != tt.allowed {
+				t.Errorf("%s: read of %x by %x: output %x, want allowed %v", config.name, tt.target, tt.caller, output, tt.allowed)
+			}
+		}
+		// Denials have to tell why the read failed from the denial errors fork on
+		output, _ := chain.Call(outsider.Address, private, nil)
+		if _, ok := RevertReason(output); ok != config.forked {
+			t.Errorf("%s: denied read output %x, want reason %v", config.name, output, config.forked)
+		}
+		// Static calls made by contracts, which aren't contributors, have to
+		// face the same verdicts, once they are checked at all. The proxies are
+		// called by a member, who may write to them.
+		for _, target := range []struct {
+			addr    common.Address
+			allowed bool
+		}{{public, true}, {private, false}} {
+			proxy := deployContract(t, chain, guardian, proxyCode(vm.STATICCALL, target.addr), true)
+			if ok := call(t, chain, member, proxy, [4]byte{}); ok != (target.allowed || !config.forked) {
+				t.Errorf("%s: static call of %x: success %v, want %v", config.name, target.addr, ok, target.allowed || !config.forked)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package veriteemtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that only guardians may deploy contracts from accounts. Before the
+// create denial fork others deploy an empty contract; from the fork on their
+// creation fails before any account is created, charging nothing but the
+// intrinsic gas and the creation check.
+func TestCreateDenial(t *testing.T) {
+	for _, config := range testConfigs {
+		chain := newTestChain(t, config.configure)
+		guardian := newGuardianship(t, chain)
+		member := newContributor(t, chain, guardian, 0, 0)
+
+		if contract, err := chain.Deploy(guardian, readerCode()); err != nil || len(code(t, chain, contract)) == 0 {
+			t.Fatalf("%s: deployment by guardian failed: %v", config.name, err)
+		}
+		for _, creator := range []*Account{member, NewAccount()} {
+			nonce, err := chain.Backend.PendingNonceAt(context.Background(), creator.Address)
+			if err != nil {
+				t.Fatalf("%s: failed to read nonce: %v", config.name, err)
+			}
+			receipt, err := chain.Transact(creator, nil, readerCode())
+			if err != nil {
+				t.Fatalf("%s: failed to send creation: %v", config.name, err)
+			}
+			address := crypto.CreateAddress(creator.Address, nonce)
+			if len(code(t, chain, address)) != 0 {
+				t.Errorf("%s: contract deployed by %x", config.name, creator.Address)
+			}
+			if next, _ := chain.Backend.PendingNonceAt(context.Background(), creator.Address); next != nonce+1 {
+				t.Errorf("%s: nonce after denied creation %d, want %d", config.name, next, nonce+1)
+			}
+			if !config.forked {
+				continue
+			}
+			if Succeeded(receipt) {
+				t.Errorf("%s: denied creation by %x succeeded", config.name, creator.Address)
+			}
+			intrinsic, _ := core.IntrinsicGas(readerCode(), true, true)
+			if want := intrinsic + params.VeriteemCreateCheckGas; receipt.GasUsed != want {
+				t.Errorf("%s: denied creation used %d gas, want %d", config.name, receipt.GasUsed, want)
+			}
+		}
+	}
+}
+
+// Tests that contracts can't deploy contracts with CREATE or CREATE2, as only
+// guardians may create contracts. Before the create denial fork they deploy an
+// empty contract instead.
+func TestContractCreateDenial(t *testing.T) {
+	for _, config := range testConfigs {
+		for _, create2 := range []bool{false, true} {
+			chain := newTestChain(t, config.configure)
+			guardian := newGuardianship(t, chain)
+			member := newContributor(t, chain, guardian, 0, 0)
+
+			factory := deployContract(t, chain, guardian, factoryCode(create2), true)
+			// The factory itself doesn't fail, as the failure of CREATE is
+			// returned to its code
+			if !call(t, chain, member, factory, [4]byte{}) {
+				t.Errorf("%s: call of factory (create2 %v) failed", config.name, create2)
+			}
+			created := common.BytesToAddress(storage(t, chain, factory))
+			if config.forked && created != (common.Address{}) {
+				t.Errorf("%s: factory (create2 %v) created %x", config.name, create2, created)
+			}
+			if created != (common.Address{}) && len(code(t, chain, created)) != 0 {
+				t.Errorf("%s: factory (create2 %v) deployed code at %x", config.name, create2, created)
+			}
+		}
+	}
+}
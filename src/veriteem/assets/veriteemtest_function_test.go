@@ -0,0 +1,80 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package veriteemtest
+
+import "testing"
+
+// Tests that the access groups of a contract's functions restrict the calls
+// made to them: the EVM has to pass the access contract the selector of the
+// called function in the form its FunctionList holds for the entry to match.
+func TestFunctionAccessGroups(t *testing.T) {
+	var (
+		restricted = Selector("restricted()")
+		open       = Selector("open()")
+	)
+	for _, config := range testConfigs {
+		chain := newTestChain(t, config.configure)
+		guardian := newGuardianship(t, chain)
+		member := newContributor(t, chain, guardian, 0, 1)
+		outsider := newContributor(t, chain, guardian, 0, 2)
+
+		contract := deployContract(t, chain, guardian, counterCode(restricted, open), true)
+		if err := chain.RestrictFunction(guardian, contract, 0, restricted, 1); err != nil {
+			t.Fatalf("%s: failed to restrict function: %v", config.name, err)
+		}
+		// The access contract itself has to agree on who may call what
+		tests := []struct {
+			caller   *Account
+			selector [4]byte
+			allowed  bool
+		}{
+			{member, restricted, true},
+			{member, open, true},
+			{outsider, restricted, false},
+			{outsider, open, true},
+		}
+		for _, tt := range tests {
+			read, write, err := chain.CheckAccess(tt.caller.Address, contract, tt.selector)
+			if err != nil {
+				t.Fatalf("%s: failed to check access: %v", config.name, err)
+			}
+			if read != tt.allowed || write != tt.allowed {
+				t.Errorf("%s: access of %x to %x: read %v write %v, want %v", config.name, tt.caller.Address, tt.selector, read, write, tt.allowed)
+			}
+		}
+		// Calls of the functions have to face the same verdicts
+		var count uint64
+		for _, tt := range tests {
+			if ok := call(t, chain, tt.caller, contract, tt.selector); ok != tt.allowed {
+				t.Errorf("%s: call of %x to %x: success %v, want %v", config.name, tt.caller.Address, tt.selector, ok, tt.allowed)
+			}
+			if tt.allowed {
+				count++
+			}
+			if have := counter(t, chain, contract); have != count {
+				t.Errorf("%s: call of %x to %x: counter %d, want %d", config.name, tt.caller.Address, tt.selector, have, count)
+			}
+		}
+		// Clearing the FunctionList entry has to open the function to everyone
+		if err := chain.RestrictFunction(guardian, contract, 0, [4]byte{}, 0); err != nil {
+			t.Fatalf("%s: failed to lift restriction: %v", config.name, err)
+		}
+		if !call(t, chain, outsider, contract, restricted) {
+			t.Errorf("%s: call of %x to lifted restriction failed", config.name, outsider.Address)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package veriteemtest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that from the rate limit fork on, contributors can't write again within
+// Limit blocks of their last write, which the EVM records on every allowed
+// write. Before the fork nothing records writes, so the limit never applies.
+func TestRateLimit(t *testing.T) {
+	const limit = 3
+
+	inc := Selector("inc()")
+	for _, config := range testConfigs {
+		chain := newTestChain(t, config.configure)
+		guardian := newGuardianship(t, chain)
+		member := newContributor(t, chain, guardian, limit, 0)
+		contract := deployContract(t, chain, guardian, counterCode(inc), true)
+
+		var last, count uint64
+		for i := 0; i < 10; i++ {
+			if i == 7 {
+				chain.Mine(limit)
+			}
+			number := chain.BlockNumber() + 1
+			want := !config.forked || number >= last+limit
+
+			if ok := call(t, chain, member, contract, inc); ok != want {
+				t.Errorf("%s: write in block %d after write in block %d: success %v, want %v", config.name, number, last, ok, want)
+			}
+			if want {
+				last, count = number, count+1
+			}
+		}
+		if have := counter(t, chain, contract); have != count {
+			t.Errorf("%s: counter %d, want %d", config.name, have, count)
+		}
+		if !config.forked {
+			continue
+		}
+		var contributor struct {
+			Guardianship    *big.Int
+			Name            string
+			Limit           *big.Int
+			LastBlockNumber *big.Int
+			AccessGroup     *big.Int
+		}
+		if err := chain.Read(&contributor, "ReadContributor", member.Address); err != nil {
+			t.Fatalf("%s: failed to read contributor: %v", config.name, err)
+		}
+		if contributor.LastBlockNumber.Uint64() != last {
+			t.Errorf("%s: recorded write block %d, want %d", config.name, contributor.LastBlockNumber, last)
+		}
+	}
+}
+
+// Tests the gas charged for permission checks from the access gas fork on: a
+// fixed amount on top of the execution of allowed calls, and nothing else for
+// denied ones, whether the access contract is run or evaluated natively.
+func TestAccessGas(t *testing.T) {
+	inc := Selector("inc()")
+	intrinsic, _ := core.IntrinsicGas(inc[:], false, true)
+
+	run := func(configure func(*params.VeriteemConfig)) (allowed, denied uint64) {
+		chain := newTestChain(t, configure)
+		guardian := newGuardianship(t, chain)
+		member := newContributor(t, chain, guardian, 0, 1)
+		outsider := newContributor(t, chain, guardian, 0, 2)
+
+		contract := deployContract(t, chain, guardian, counterCode(inc), true)
+		if err := chain.RestrictFunction(guardian, contract, 0, inc, 1); err != nil {
+			t.Fatalf("failed to restrict function: %v", err)
+		}
+		receipt := transact(t, chain, member, contract, inc[:])
+		if !Succeeded(receipt) {
+			t.Fatalf("allowed call failed")
+		}
+		allowed = receipt.GasUsed
+
+		receipt = transact(t, chain, outsider, contract, inc[:])
+		if Succeeded(receipt) {
+			t.Fatalf("denied call succeeded")
+		}
+		return allowed, receipt.GasUsed
+	}
+	unpriced := func(config *params.VeriteemConfig) {
+		allForks(true)(config)
+		config.AccessGasBlock = nil
+	}
+	baseAllowed, baseDenied := run(unpriced)
+	if baseDenied != intrinsic {
+		t.Errorf("unpriced denied call used %d gas, want %d", baseDenied, intrinsic)
+	}
+	for _, native := range []bool{false, true} {
+		allowed, denied := run(allForks(native))
+		if want := baseAllowed + params.VeriteemAccessCheckGas; allowed != want {
+			t.Errorf("native %v: allowed call used %d gas, want %d", native, allowed, want)
+		}
+		if want := intrinsic + params.VeriteemAccessCheckGas; denied != want {
+			t.Errorf("native %v: denied call used %d gas, want %d", native, denied, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package veriteemtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that from the migration fork on, calls to a contract migrated to a new
+// address are rejected with an error naming the new address, or forwarded to
+// it if the chain is configured to. Before the fork the migration is ignored.
+func TestMigration(t *testing.T) {
+	forward := func(native bool) func(*params.VeriteemConfig) {
+		return func(config *params.VeriteemConfig) {
+			allForks(native)(config)
+			config.ForwardMigrated = true
+		}
+	}
+	tests := []struct {
+		name      string
+		configure func(*params.VeriteemConfig)
+		forwarded bool
+		rejected  bool
+	}{
+		{"legacy", noForks, false, false},
+		{"rejected", allForks(false), false, true},
+		{"forwarded", forward(false), true, false},
+		{"native/rejected", allForks(true), false, true},
+		{"native/forwarded", forward(true), true, false},
+	}
+	inc := Selector("inc()")
+	for _, tt := range tests {
+		chain := newTestChain(t, tt.configure)
+		guardian := newGuardianship(t, chain)
+		member := newContributor(t, chain, guardian, 0, 0)
+
+		old := deployContract(t, chain, guardian, counterCode(inc), true)
+		current := deployContract(t, chain, guardian, counterCode(inc), true)
+		if err := chain.WriteContractInfo(guardian, old, "old", current, true, true, vm.ContractStateMigrated); err != nil {
+			t.Fatalf("%s: failed to migrate contract: %v", tt.name, err)
+		}
+		if ok := call(t, chain, member, old, inc); ok == tt.rejected {
+			t.Errorf("%s: call of migrated contract: success %v, want %v", tt.name, ok, !tt.rejected)
+		}
+		var wantOld, wantCurrent uint64
+		switch {
+		case tt.forwarded:
+			wantCurrent = 1
+		case !tt.rejected:
+			wantOld = 1
+		}
+		if have := counter(t, chain, old); have != wantOld {
+			t.Errorf("%s: counter of migrated contract %d, want %d", tt.name, have, wantOld)
+		}
+		if have := counter(t, chain, current); have != wantCurrent {
+			t.Errorf("%s: counter of new contract %d, want %d", tt.name, have, wantCurrent)
+		}
+		// Rejections have to point callers at the new address
+		if tt.rejected {
+			output, err := chain.Call(member.Address, old, inc[:])
+			if err != nil {
+				t.Fatalf("%s: call failed: %v", tt.name, err)
+			}
+			if reason, ok := RevertReason(output); !ok || !strings.Contains(reason, current.Hex()) {
+				t.Errorf("%s: rejection reason %q doesn't name %x", tt.name, reason, current)
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package veriteemtest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// withFallbackGroup configures a chain with every fork, restricting fallback
+// calls to access group 2.
+func withFallbackGroup(config *params.VeriteemConfig) {
+	allForks(true)(config)
+	config.FallbackAccessGroup = big.NewInt(2)
+}
+
+// Tests that from the selector fork on, calls reaching the fallback function of
+// a contract are held against the fallback access group: calls without a full
+// selector, and calls with a selector the contract doesn't dispatch.
+func TestFallbackCalls(t *testing.T) {
+	inc := Selector("inc()")
+	inputs := []struct {
+		name     string
+		input    []byte
+		fallback bool
+	}{
+		{"function", inc[:], false},
+		{"function with arguments", append(inc[:], make([]byte, 32)...), false},
+		{"empty", nil, true},
+		{"short", inc[:2], true},
+		{"unknown selector", []byte{0x12, 0x34, 0x56, 0x78}, true},
+	}
+	for _, forked := range []bool{false, true} {
+		configure := withFallbackGroup
+		if !forked {
+			configure = func(config *params.VeriteemConfig) {
+				withFallbackGroup(config)
+				config.SelectorBlock = nil
+			}
+		}
+		chain := newTestChain(t, configure)
+		guardian := newGuardianship(t, chain)
+		member := newContributor(t, chain, guardian, 0, 1)
+		fallbacker := newContributor(t, chain, guardian, 0, 2)
+		contract := deployContract(t, chain, guardian, counterCode(inc), true)
+
+		for _, in := range inputs {
+			for _, caller := range []*Account{member, fallbacker} {
+				want := !forked || !in.fallback || caller == fallbacker
+				if ok := Succeeded(transact(t, chain, caller, contract, in.input)); ok != want {
+					t.Errorf("forked %v: %s call by group %v: success %v, want %v", forked, in.name, caller == fallbacker, ok, want)
+				}
+			}
+		}
+	}
+}
+
+// Tests that from the call kinds fork on, functions reached through a proxy
+// delegating calls are checked for the caller of the proxy, so that the access
+// groups of the functions of the implementation apply to it. Before the fork
+// delegated calls aren't checked at all.
+func TestDelegatedSelectors(t *testing.T) {
+	var (
+		restricted = Selector("restricted()")
+		open       = Selector("open()")
+	)
+	for _, forked := range []bool{false, true} {
+		configure := allForks(true)
+		if !forked {
+			configure = func(config *params.VeriteemConfig) {
+				allForks(true)(config)
+				config.CallKindsBlock = nil
+			}
+		}
+		chain := newTestChain(t, configure)
+		guardian := newGuardianship(t, chain)
+		member := newContributor(t, chain, guardian, 0, 1)
+		outsider := newContributor(t, chain, guardian, 0, 2)
+
+		implementation := deployContract(t, chain, guardian, counterCode(restricted, open), true)
+		if err := chain.RestrictFunction(guardian, implementation, 0, restricted, 1); err != nil {
+			t.Fatalf("forked %v: failed to restrict function: %v", forked, err)
+		}
+		proxy := deployContract(t, chain, guardian, proxyCode(vm.DELEGATECALL, implementation), true)
+		if err := chain.CreateContributor(guardian, proxy, "proxy", 0, 0); err != nil {
+			t.Fatalf("forked %v: failed to add proxy contributor: %v", forked, err)
+		}
+		tests := []struct {
+			caller   *Account
+			selector [4]byte
+			allowed  bool
+		}{
+			{member, restricted, true},
+			{outsider, restricted, !forked},
+			{member, open, true},
+			{outsider, open, true},
+		}
+		var count uint64
+		for _, tt := range tests {
+			if ok := call(t, chain, tt.caller, proxy, tt.selector); ok != tt.allowed {
+				t.Errorf("forked %v: delegated call of %x by %x: success %v, want %v", forked, tt.selector, tt.caller.Address, ok, tt.allowed)
+			}
+			if tt.allowed {
+				count++
+			}
+		}
+		// The implementation runs in the storage of the proxy
+		if have := counter(t, chain, proxy); have != count {
+			t.Errorf("forked %v: proxy counter %d, want %d", forked, have, count)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package veriteemtest
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newTestChain boots a chain from the Veriteem genesis in testdata, with every
+// Ethereum fork active from block zero so receipts carry a status and CREATE2
+// is available. The access control forks are left to configure.
+//
+// Every block the tests commit is generated without the access cache and then
+// imported by the blockchain with it, so any disagreement between the cached
+// and uncached permission checks fails the test on the spot.
+func newTestChain(t *testing.T, configure func(*params.VeriteemConfig)) *Chain {
+	genesis, err := LoadGenesis(filepath.Join("testdata", "genesis.json"))
+	if err != nil {
+		t.Fatalf("failed to load genesis: %v", err)
+	}
+	config := *genesis.Config
+	config.HomesteadBlock = new(big.Int)
+	config.EIP150Block = new(big.Int)
+	config.EIP155Block = new(big.Int)
+	config.EIP158Block = new(big.Int)
+	config.ByzantiumBlock = new(big.Int)
+	config.ConstantinopleBlock = new(big.Int)
+
+	veriteem := *config.Veriteem
+	if configure != nil {
+		configure(&veriteem)
+	}
+	config.Veriteem = &veriteem
+	genesis.Config = &config
+
+	chain, err := NewChain(genesis)
+	if err != nil {
+		t.Fatalf("failed to boot chain: %v", err)
+	}
+	return chain
+}
+
+// allForks activates every access control fork from block zero, with the access
+// contract evaluated natively if native is set.
+func allForks(native bool) func(*params.VeriteemConfig) {
+	return func(config *params.VeriteemConfig) {
+		config.Native = native
+		config.CallKindsBlock = new(big.Int)
+		config.DenialErrorsBlock = new(big.Int)
+		config.CreateDenialBlock = new(big.Int)
+		config.StaticReadBlock = new(big.Int)
+		config.RateLimitBlock = new(big.Int)
+		config.AccessGasBlock = new(big.Int)
+		config.MigrationBlock = new(big.Int)
+		config.LifecycleBlock = new(big.Int)
+		config.SelectorBlock = new(big.Int)
+	}
+}
+
+// noForks restores the original access control rules, enforced by running the
+// access contract, whatever forks the genesis schedules.
+func noForks(config *params.VeriteemConfig) {
+	*config = params.VeriteemConfig{
+		AccessContract: config.AccessContract,
+		AccessBlock:    config.AccessBlock,
+		Upgrades:       config.Upgrades,
+	}
+}
+
+// testConfigs are the access control configurations every end to end test runs
+// under: the original rules, and every fork with the access contract run as
+// code or evaluated natively.
+var testConfigs = []struct {
+	name      string
+	forked    bool
+	configure func(*params.VeriteemConfig)
+}{
+	{"legacy", false, noForks},
+	{"forked", true, allForks(false)},
+	{"native", true, allForks(true)},
+}
+
+// counterCode returns the creation code of a contract counting, in storage slot
+// zero, the calls made to the functions identified by selectors. Calls of any
+// other function reach a fallback that does nothing.
+func counterCode(selectors ...[4]byte) []byte {
+	// PUSH1 0 CALLDATALOAD PUSH1 0xe0 PUSH1 2 EXP SWAP1 DIV: the selector
+	code := []byte{0x60, 0x00, 0x35, 0x60, 0xe0, 0x60, 0x02, 0x0a, 0x90, 0x04}
+
+	count := byte(len(code) + 10*len(selectors) + 1)
+	for _, selector := range selectors {
+		// DUP1 PUSH4 selector EQ PUSH1 count JUMPI
+		code = append(code, 0x80, 0x63)
+		code = append(code, selector[:]...)
+		code = append(code, 0x14, 0x60, count, 0x57)
+	}
+	// STOP, then JUMPDEST PUSH1 0 SLOAD PUSH1 1 ADD PUSH1 0 SSTORE STOP
+	code = append(code, 0x00)
+	code = append(code, 0x5b, 0x60, 0x00, 0x54, 0x60, 0x01, 0x01, 0x60, 0x00, 0x55, 0x00)
+
+	return deployCode(code)
+}
+
+// readerCode returns the creation code of a contract returning the word 1 to
+// any call, without touching the state.
+func readerCode() []byte {
+	// PUSH1 1 PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+	return deployCode([]byte{0x60, 0x01, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3})
+}
+
+// proxyCode returns the creation code of a contract forwarding its calldata to
+// target with op, one of CALL, CALLCODE, DELEGATECALL and STATICCALL, and
+// failing if the forwarded call does.
+func proxyCode(op vm.OpCode, target common.Address) []byte {
+	// CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY PUSH1 0 PUSH1 0 CALLDATASIZE PUSH1 0
+	code := []byte{0x36, 0x60, 0x00, 0x60, 0x00, 0x37, 0x60, 0x00, 0x60, 0x00, 0x36, 0x60, 0x00}
+	if op == vm.CALL || op == vm.CALLCODE {
+		// PUSH1 0: no value
+		code = append(code, 0x60, 0x00)
+	}
+	// PUSH20 target GAS op
+	code = append(code, 0x73)
+	code = append(code, target.Bytes()...)
+	code = append(code, 0x5a, byte(op))
+
+	// PUSH1 ok JUMPI PUSH1 0 DUP1 REVERT, ok: JUMPDEST STOP
+	code = append(code, 0x60, byte(len(code)+7), 0x57, 0x60, 0x00, 0x80, 0xfd, 0x5b, 0x00)
+	return deployCode(code)
+}
+
+// childCode is the creation code of the contracts created by factories,
+// deploying the runtime code STOP.
+var childCode = []byte{0x60, 0x00, 0x60, 0x00, 0x53, 0x60, 0x01, 0x60, 0x00, 0xf3}
+
+// factoryCode returns the creation code of a contract creating a contract with
+// childCode on every call, with CREATE or, if create2 is set, CREATE2, and
+// storing the address of the latest contract, or zero if creation failed, in
+// storage slot zero.
+func factoryCode(create2 bool) []byte {
+	size := byte(len(childCode))
+	offset := byte(18)
+	if create2 {
+		offset = 20
+	}
+	// PUSH1 size PUSH1 offset PUSH1 0 CODECOPY: the child's code
+	code := []byte{0x60, size, 0x60, offset, 0x60, 0x00, 0x39}
+	if create2 {
+		// PUSH1 0 PUSH1 size PUSH1 0 PUSH1 0 CREATE2: salt 0, no value
+		code = append(code, 0x60, 0x00, 0x60, size, 0x60, 0x00, 0x60, 0x00, byte(vm.CREATE2))
+	} else {
+		// PUSH1 size PUSH1 0 PUSH1 0 CREATE: no value
+		code = append(code, 0x60, size, 0x60, 0x00, 0x60, 0x00, byte(vm.CREATE))
+	}
+	// PUSH1 0 SSTORE STOP
+	code = append(code, 0x60, 0x00, 0x55, 0x00)
+	return deployCode(append(code, childCode...))
+}
+
+// deployCode returns creation code deploying runtime code of up to 255 bytes.
+func deployCode(code []byte) []byte {
+	// PUSH1 len DUP1 PUSH1 11 PUSH1 0 CODECOPY PUSH1 0 RETURN
+	return append([]byte{0x60, byte(len(code)), 0x80, 0x60, 0x0b, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}, code...)
+}
+
+// counter returns the number of function calls a counter contract recorded.
+func counter(t *testing.T, chain *Chain, contract common.Address) uint64 {
+	return new(big.Int).SetBytes(storage(t, chain, contract)).Uint64()
+}
+
+// storage returns storage slot zero of contract.
+func storage(t *testing.T, chain *Chain, contract common.Address) []byte {
+	value, err := chain.Backend.StorageAt(context.Background(), contract, common.Hash{}, nil)
+	if err != nil {
+		t.Fatalf("failed to read storage of %x: %v", contract, err)
+	}
+	return value
+}
+
+// code returns the code of the account at addr.
+func code(t *testing.T, chain *Chain, addr common.Address) []byte {
+	code, err := chain.Backend.CodeAt(context.Background(), addr, nil)
+	if err != nil {
+		t.Fatalf("failed to read code of %x: %v", addr, err)
+	}
+	return code
+}
+
+// newGuardianship makes a new account the first guardian of the chain.
+func newGuardianship(t *testing.T, chain *Chain) *Account {
+	guardian := NewAccount()
+	if err := chain.InitGuardianship(guardian); err != nil {
+		t.Fatalf("failed to init guardianship: %v", err)
+	}
+	return guardian
+}
+
+// newContributor adds a new account to the guardianship of guardian.
+func newContributor(t *testing.T, chain *Chain, guardian *Account, limit, accessGroup uint64) *Account {
+	contributor := NewAccount()
+	if err := chain.CreateContributor(guardian, contributor.Address, "contributor", limit, accessGroup); err != nil {
+		t.Fatalf("failed to create contributor: %v", err)
+	}
+	return contributor
+}
+
+// deployContract deploys code as guardian, making the contract writable by the
+// guardianship and, if public is set, readable by anyone.
+func deployContract(t *testing.T, chain *Chain, guardian *Account, code []byte, public bool) common.Address {
+	contract, err := chain.Deploy(guardian, code)
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	if err := chain.WriteContractInfo(guardian, contract, "contract", common.Address{}, true, public, vm.ContractStateActive); err != nil {
+		t.Fatalf("failed to write contract info: %v", err)
+	}
+	return contract
+}
+
+// call sends a transaction from account to the function of contract identified
+// by selector, and reports whether it succeeded.
+func call(t *testing.T, chain *Chain, from *Account, contract common.Address, selector [4]byte) bool {
+	return Succeeded(transact(t, chain, from, contract, selector[:]))
+}
+
+// transact sends a transaction from account to contract with the given input
+// and returns its receipt.
+func transact(t *testing.T, chain *Chain, from *Account, contract common.Address, input []byte) *types.Receipt {
+	receipt, err := chain.Transact(from, &contract, input)
+	if err != nil {
+		t.Fatalf("failed to call %x: %v", contract, err)
+	}
+	return receipt
+}
+
+func TestNewChain(t *testing.T) {
+	chain := newTestChain(t, nil)
+	if chain.Access != common.HexToAddress("0x0000000000000000000000000000000000000100") {
+		t.Fatalf("access contract at %x", chain.Access)
+	}
+	if len(code(t, chain, chain.Access)) == 0 {
+		t.Fatalf("genesis installs no access contract")
+	}
+	// Access control has to be in force from the genesis on
+	guardian := newGuardianship(t, chain)
+	var index struct {
+		Match bool
+		Index *big.Int
+	}
+	if err := chain.Read(&index, "GuardianshipIndex", guardian.Address); err != nil {
+		t.Fatalf("failed to read guardianship: %v", err)
+	}
+	if !index.Match || index.Index.Uint64() != 1 {
+		t.Errorf("guardianship of first guardian: match %v index %v, want 1", index.Match, index.Index)
+	}
+	if _, err := NewChain(&core.Genesis{}); err != errNoAccessControl {
+		t.Errorf("chain without config: have %v, want %v", err, errNoAccessControl)
+	}
+}
+
+func TestRevertReason(t *testing.T) {
+	output := []byte("\x08\xc3\x79\xa0")
+	output = append(output, common.LeftPadBytes([]byte{0x20}, 32)...)
+	output = append(output, common.LeftPadBytes([]byte{0x0d}, 32)...)
+	output = append(output, common.RightPadBytes([]byte("access denied"), 32)...)
+
+	if reason, ok := RevertReason(output); !ok || reason != "access denied" {
+		t.Errorf("reason: have %q (%v), want %q", reason, ok, "access denied")
+	}
+	for i := 0; i < len(output); i += 17 {
+		if reason, ok := RevertReason(output[:i]); ok {
+			t.Errorf("reason decoded from %d bytes: %q", i, reason)
+		}
+	}
+}
+
+func TestSelector(t *testing.T) {
+	if have, want := Selector("transfer(address,uint256)"), [4]byte{0xa9, 0x05, 0x9c, 0xbb}; have != want {
+		t.Errorf("selector: have %x, want %x", have, want)
+	}
+}
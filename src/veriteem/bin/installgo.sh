@@ -20,14 +20,103 @@ git reset --hard d9575e92fc6e52ba18267410fcd2426d5a148cbc
 cd ..
 cp ../assets/evm.go go-ethereum/core/vm/evm.go
 cp ../assets/errors.go go-ethereum/core/vm/errors.go
+cp ../assets/access.go go-ethereum/core/vm/access.go
+cp ../assets/access_test.go go-ethereum/core/vm/access_test.go
+cp ../assets/access_helper_test.go go-ethereum/core/vm/access_helper_test.go
+mkdir -p go-ethereum/core/vm/testdata
+cp ../assets/genesis.json go-ethereum/core/vm/testdata/genesis.json
+cp ../assets/access_create_test.go go-ethereum/core/vm/access_create_test.go
+cp ../assets/access_limit_test.go go-ethereum/core/vm/access_limit_test.go
+cp ../assets/access_migration_test.go go-ethereum/core/vm/access_migration_test.go
+cp ../assets/access_tracer_test.go go-ethereum/core/vm/access_tracer_test.go
+cp ../assets/access_audit_test.go go-ethereum/core/vm/access_audit_test.go
+cp ../assets/access_native.go go-ethereum/core/vm/access_native.go
+cp ../assets/access_native_test.go go-ethereum/core/vm/access_native_test.go
+cp ../assets/access_cache.go go-ethereum/core/vm/access_cache.go
+cp ../assets/access_cache_test.go go-ethereum/core/vm/access_cache_test.go
+cp ../assets/access_tracer.go go-ethereum/core/vm/access_tracer.go
+cp ../assets/config_veriteem.go go-ethereum/params/config_veriteem.go
+cp ../assets/config_veriteem_test.go go-ethereum/params/config_veriteem_test.go
+cp ../assets/api_tracer_access.go go-ethereum/eth/api_tracer_access.go
+cp ../assets/access_audit.go go-ethereum/core/vm/access_audit.go
+cp ../assets/access_snapshot.go go-ethereum/core/vm/access_snapshot.go
+cp ../assets/access_diff.go go-ethereum/core/vm/access_diff.go
+cp ../assets/auditflags.go go-ethereum/cmd/geth/auditflags.go
+cp ../assets/denial_store.go go-ethereum/core/denial_store.go
+cp ../assets/denial_store_test.go go-ethereum/core/denial_store_test.go
+cp ../assets/api_veriteem.go go-ethereum/eth/api_veriteem.go
+cp ../assets/api_veriteem_events.go go-ethereum/eth/api_veriteem_events.go
+cp ../assets/api_veriteem_test.go go-ethereum/eth/api_veriteem_test.go
+mkdir -p go-ethereum/eth/testdata
+cp ../assets/genesis.json go-ethereum/eth/testdata/genesis.json
+cp ../assets/ethapi_veriteem.go go-ethereum/internal/ethapi/api_veriteem.go
+cp ../assets/tx_pool_access.go go-ethereum/core/tx_pool_access.go
+cp ../assets/tx_pool_access_test.go go-ethereum/core/tx_pool_access_test.go
+cp ../assets/managed_state_access.go go-ethereum/core/state/managed_state_access.go
+mkdir -p go-ethereum/veriteem/veriteemtest
+cp ../assets/veriteemtest.go go-ethereum/veriteem/veriteemtest/veriteemtest.go
+cp ../assets/veriteemtest_test.go go-ethereum/veriteem/veriteemtest/veriteemtest_test.go
+cp ../assets/veriteemtest_call_test.go go-ethereum/veriteem/veriteemtest/call_test.go
+cp ../assets/veriteemtest_create_test.go go-ethereum/veriteem/veriteemtest/create_test.go
+cp ../assets/veriteemtest_function_test.go go-ethereum/veriteem/veriteemtest/function_test.go
+cp ../assets/veriteemtest_limit_test.go go-ethereum/veriteem/veriteemtest/limit_test.go
+cp ../assets/veriteemtest_migration_test.go go-ethereum/veriteem/veriteemtest/migration_test.go
+cp ../assets/veriteemtest_selector_test.go go-ethereum/veriteem/veriteemtest/selector_test.go
+mkdir -p go-ethereum/veriteem/veriteemtest/testdata
+cp ../assets/genesis.json go-ethereum/veriteem/veriteemtest/testdata/genesis.json
+mkdir -p go-ethereum/veriteem/accessrights
+cp ../assets/accessrights.go go-ethereum/veriteem/accessrights/accessrights.go
+cp ../assets/accessrights_client.go go-ethereum/veriteem/accessrights/client.go
+cp ../assets/accessrights_client_test.go go-ethereum/veriteem/accessrights/client_test.go
+mkdir -p go-ethereum/veriteem/accessrights/testdata
+cp ../assets/genesis.json go-ethereum/veriteem/accessrights/testdata/genesis.json
+mkdir -p go-ethereum/vendor/remotewallet
+cp ../remotewallet/remotewallet.go go-ethereum/vendor/remotewallet/remotewallet.go
+cp ../remotewallet/wallet.go go-ethereum/vendor/remotewallet/wallet.go
+cp ../remotewallet/veriteem.go go-ethereum/vendor/remotewallet/veriteem.go
+cp ../remotewallet/SigningServer.go go-ethereum/vendor/remotewallet/SigningServer.go
+mkdir -p go-ethereum/cmd/veriteemctl
+cp ../assets/veriteemctl_main.go go-ethereum/cmd/veriteemctl/main.go
+cp ../assets/veriteemctl_signer.go go-ethereum/cmd/veriteemctl/signer.go
+cp ../assets/veriteemctl_guardian.go go-ethereum/cmd/veriteemctl/guardian.go
+cp ../assets/veriteemctl_contributor.go go-ethereum/cmd/veriteemctl/contributor.go
+cp ../assets/veriteemctl_contract.go go-ethereum/cmd/veriteemctl/contract.go
+cp ../assets/veriteemctl_snapshot.go go-ethereum/cmd/veriteemctl/snapshot.go
+cp ../assets/veriteemctl_diff.go go-ethereum/cmd/veriteemctl/diff.go
+cp ../assets/veriteemctl_watch.go go-ethereum/cmd/veriteemctl/watch.go
 version=`ls ../.. | grep veriteem- | cut -d "-" -f2 | cut -d '.' -f1-3`
 echo $version >../VERSION
 sed -i "/pingPacket = iota + 1/c\        pingPacket = iota + 32 " go-ethereum/p2p/discover/udp.go 
 sed -i "/VersionMeta  =/c\     VersionMeta = \"veriteem-$version\"" go-ethereum/params/version.go 
+sed -i '/Clique \*CliqueConfig/a\	Veriteem *VeriteemConfig `json:"veriteem,omitempty"`' go-ethereum/params/config.go
+sed -i 's/new(EthashConfig), nil}/new(EthashConfig), nil, nil}/; s/nil, &CliqueConfig{Period: 0, Epoch: 30000}}/nil, \&CliqueConfig{Period: 0, Epoch: 30000}, nil}/' go-ethereum/params/config.go
+sed -i '/newCompatError("ewasm fork block"/{n;s/$/\n\tif err := c.checkVeriteemCompatible(newcfg, head); err != nil {\n\t\treturn err\n\t}/}' go-ethereum/params/config.go
+sed -i '/EnablePreimageRecording bool/a\	AccessCache *AccessCache // Permission decisions shared by the transactions of a block' go-ethereum/core/vm/interpreter.go
+sed -i '/AccessCache \*AccessCache/a\	ReadOnlyAccess bool // Whether the state changes of the outermost call are discarded, as for eth_call' go-ethereum/core/vm/interpreter.go
+sed -i '/ReadOnlyAccess bool/a\	DenialRecorder AccessDenialRecorder // Persists the access denials of processed blocks' go-ethereum/core/vm/interpreter.go
+sed -i '/DenialRecorder AccessDenialRecorder/a\	AccessDenials *AccessDenialBatch // Access denials of the transactions of the block being processed' go-ethereum/core/vm/interpreter.go
+sed -i '/res, err := operation.execute(/i\		if operation.writes {\n\t\t\tin.evm.accessWrites++\n\t\t}' go-ethereum/core/vm/interpreter.go
+sed -i '/^func (s \*PublicTransactionPoolAPI) GetTransactionReceipt(/,/^}/s/return fields, nil/return withAccessDenials(fields, s.b.ChainDb(), blockNumber, hash), nil/' go-ethereum/internal/ethapi/api.go
+sed -i 's/s.doCall(ctx, args, blockNr, vm.Config{}, 5\*time.Second)/s.doCall(ctx, args, blockNr, vm.Config{ReadOnlyAccess: true}, 5*time.Second)/' go-ethereum/internal/ethapi/api.go
+sed -i '/func (p \*StateProcessor) Process(/a\	cfg.AccessCache, cfg.AccessDenials = vm.NewAccessCache(), vm.NewAccessDenialBatch(cfg.DenialRecorder)' go-ethereum/core/state_processor.go
+sed -i '/p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles(), receipts)/a\	cfg.AccessDenials.Record(block.NumberU64(), block.Hash())' go-ethereum/core/state_processor.go
+sed -i '/_, gas, failed, err := ApplyMessage(vmenv, msg, gp)/a\	vmenv.FlushAccessDenials(tx.Hash())' go-ethereum/core/state_processor.go
+sed -i 's/vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}/vm.Config{EnablePreimageRecording: config.EnablePreimageRecording, DenialRecorder: core.NewDenialStore(chainDb)}/' go-ethereum/eth/backend.go
+sed -i '/apis = append(apis, s.engine.APIs(s.BlockChain())...)/a\	apis = append(apis, veriteemAPIs(s)...)' go-ethereum/eth/backend.go
+sed -i '/if tx.Gas() < intrGas {/,/return nil/s/return nil/return pool.validateAccess(tx, from, intrGas)/' go-ethereum/core/tx_pool.go
+sed -i '/pendingState *\*state.ManagedState/a\	accessState   *state.StateDB              // Copy of the pending state for the access checks\n\trateLimited   map[common.Address]struct{} // Senders held back by their write rate limit' go-ethereum/core/tx_pool.go
+sed -i '/pool.pendingState = state.ManageState(statedb)/a\	pool.accessState = nil' go-ethereum/core/tx_pool.go
+sed -i '/^func (pool \*TxPool) Pending()/,/^}/s/return pending, nil/return pool.holdRateLimited(pending), nil/' go-ethereum/core/tx_pool.go
+sed -i '/app.Flags = append(app.Flags, debug.Flags...)/a\	app.Flags = append(app.Flags, accessAuditFlags...)' go-ethereum/cmd/geth/main.go
+sed -i '/runtime.GOMAXPROCS(runtime.NumCPU())/a\		setupAccessAudit(ctx)' go-ethereum/cmd/geth/main.go
+sed -i '/^func NewSimulatedBackend(/,/^}/{/database := ethdb.NewMemDatabase()/d}' go-ethereum/accounts/abi/bind/backends/simulated.go
+sed -i 's/^\tgenesis := core.Genesis{Config: params.AllEthashProtocolChanges, GasLimit: gasLimit, Alloc: alloc}$/\treturn NewSimulatedBackendWithGenesis(\&core.Genesis{Config: params.AllEthashProtocolChanges, GasLimit: gasLimit, Alloc: alloc})\n}\n\n\/\/ NewSimulatedBackendWithGenesis creates a new binding backend using a simulated\n\/\/ blockchain started from genesis, such as the genesis of a Veriteem chain.\nfunc NewSimulatedBackendWithGenesis(genesis *core.Genesis) *SimulatedBackend {\n\tdatabase := ethdb.NewMemDatabase()/' go-ethereum/accounts/abi/bind/backends/simulated.go
 cd go-ethereum
 make all
 cd ..
 cp go-ethereum/build/bin/geth veriteem
 chmod +x veriteem
+cp go-ethereum/build/bin/veriteemctl veriteemctl
+chmod +x veriteemctl
 #rm -rf go-ethereum
 